@@ -1,55 +1,309 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/homedepot/arcade/internal/cli"
+	"github.com/homedepot/arcade/internal/coalesce"
+	"github.com/homedepot/arcade/internal/filesink"
+	arcadegrpc "github.com/homedepot/arcade/internal/grpc"
 	arcadehttp "github.com/homedepot/arcade/internal/http"
+	"github.com/homedepot/arcade/internal/logging"
+	"github.com/homedepot/arcade/internal/metrics"
 	"github.com/homedepot/arcade/internal/middleware"
+	"github.com/homedepot/arcade/internal/ratelimit"
+	"github.com/homedepot/arcade/internal/secretsync"
+	"github.com/homedepot/arcade/internal/tracing"
 )
 
-var (
-	r = gin.Default()
-)
+// cleanupTimeout bounds each step after draining during shutdown, such as
+// revoking tokens and flushing the audit log and traces, so that draining
+// for the whole shutdown timeout does not leave them no time.
+const cleanupTimeout = 10 * time.Second
+
+func fatal(msg string, err error) {
+	if err != nil {
+		slog.Error(msg, slog.Any("error", err))
+	} else {
+		slog.Error(msg)
+	}
 
-func init() {
-	gin.ForceConsoleColor()
+	os.Exit(1)
+}
+
+// Run arcade, by default on port 1982, until it receives SIGINT or SIGTERM.
+// If the first argument is a command of the command line client, such as
+// token, or arcade is run as a credential helper, run it instead.
+func main() {
+	switch filepath.Base(os.Args[0]) {
+	case cli.DockerCredentialHelper:
+		os.Exit(runCLI(append([]string{"docker-credential"}, os.Args[1:]...)))
+	case cli.GitCredentialHelper:
+		os.Exit(runCLI(append([]string{"git-credential"}, os.Args[1:]...)))
+	}
+
+	if len(os.Args) > 1 && cli.IsCommand(os.Args[1]) {
+		os.Exit(runCLI(os.Args[1:]))
+	}
+
+	cfg, err := parseConfig(os.Args[1:])
+	if err != nil {
+		fatal("invalid configuration", err)
+	}
+
+	logger, err := logging.New(os.Stdout, cfg.logFormat, cfg.logLevel)
+	if err != nil {
+		fatal("invalid logging configuration", err)
+	}
+
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := tracing.Setup(context.Background())
+	if err != nil {
+		fatal("error configuring tracing", err)
+	}
 
-	var (
-		controller arcadehttp.Controller
-		err        error
-	)
+	var controller arcadehttp.Controller
 
-	if dir := os.Getenv("ARCADE_CONFIG_DIRECTORY"); dir != "" {
-		controller, err = arcadehttp.NewController(dir)
+	if cfg.configDirectory != "" {
+		controller, err = arcadehttp.NewController(cfg.configDirectory)
 	} else {
 		controller, err = arcadehttp.NewDefaultController()
 	}
 
 	if err != nil {
-		log.Fatal(err)
+		fatal("error loading token providers", err)
 	}
 
-	apiKey := mustGetenv("ARCADE_API_KEY")
-	r.Use(middleware.NewAPIKeyAuth(apiKey))
+	store, secret, err := newCacheStore(cfg)
+	if err != nil {
+		fatal("error configuring token cache", err)
+	}
 
-	r.GET("/tokens", controller.GetToken)
-}
+	if store != nil {
+		if err := controller.UseStore(store, secret); err != nil {
+			fatal("error configuring token cache", err)
+		}
+	}
+
+	controller.Wrap(coalesce.Wrap)
 
-func mustGetenv(env string) (s string) {
-	if s = os.Getenv(env); s == "" {
-		log.Fatal(env + " not set; exiting.")
+	if cfg.maxConcurrentRefreshes > 0 {
+		controller.Wrap(ratelimit.LimitRefreshes(cfg.maxConcurrentRefreshes))
 	}
 
-	return
+	m := metrics.New()
+	controller.Wrap(m.Instrument)
+	controller.Wrap(tracing.Instrument)
+	controller.Wrap(arcadehttp.TrackStatus)
+	controller.RequireTokenForReadiness = cfg.readinessRequiresToken
+	controller.CallerLimiter = ratelimit.New(cfg.callerRateLimit, cfg.callerRateBurst)
+	controller.ProviderLimiter = ratelimit.New(cfg.providerRateLimit, cfg.providerRateBurst)
+	controller.OnRateLimited = m.RateLimited
+	controller.DisableDefaultProvider = cfg.disableDefaultProvider
+
+	// Watches of tokens end once the server starts shutting down, so that
+	// their clients reconnect elsewhere.
+	done := make(chan struct{})
+	controller.Done = done
+
+	controller.Auditor, err = newAuditor(cfg)
+	if err != nil {
+		fatal("error configuring audit log", err)
+	}
+
+	// Tokens are written to files and synced to Secrets in the
+	// background, and requested from every provider until they have
+	// produced one if readiness requires it.
+	var background []func(context.Context)
+
+	if cfg.readinessRequiresToken {
+		background = append(background, controller.WarmUp)
+	}
+
+	if cfg.fileSinkConfig != "" {
+		files, err := filesink.LoadConfig(cfg.fileSinkConfig)
+		if err != nil {
+			fatal("error configuring file sink", err)
+		}
+
+		sink := filesink.New(&controller, files)
+		if err := sink.Validate(); err != nil {
+			fatal("error configuring file sink", err)
+		}
+
+		background = append(background, sink.Run)
+	}
+
+	if cfg.secretSyncConfig != "" {
+		secrets, err := secretsync.LoadConfig(cfg.secretSyncConfig)
+		if err != nil {
+			fatal("error configuring secret sync", err)
+		}
+
+		client, err := secretsync.NewInClusterClient()
+		if err != nil {
+			fatal("error configuring secret sync", err)
+		}
+
+		syncer := secretsync.New(&controller, client, secrets)
+		if err := syncer.Validate(); err != nil {
+			fatal("error configuring secret sync", err)
+		}
+
+		background = append(background, syncer.Run)
+	}
+
+	keys, err := apiKeys()
+	if err != nil {
+		fatal("invalid API keys", err)
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+
+	r := gin.New()
+	r.Use(middleware.NewRequestID(), middleware.NewLogger(logger, "/healthz", "/readyz", "/metrics"))
+	r.Use(gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, err any) {
+		slog.ErrorContext(c.Request.Context(), "panic handling request", slog.Any("error", err))
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	r.GET("/metrics", gin.WrapH(m.Handler()))
+	r.GET("/healthz", controller.Healthz)
+	r.GET("/readyz", controller.Readyz)
+
+	api := r.Group("/", middleware.NewAPIKeysAuth(keys))
+	api.GET("/tokens", controller.GetToken)
+	api.GET("/tokens/watch", controller.WatchTokens)
+	api.POST("/tokens/report-invalid", controller.ReportInvalidToken)
+	api.GET("/status", controller.GetStatus)
+	api.GET("/providers", controller.GetProviders)
+
+	r.GET("/v1/openapi.json", controller.GetOpenAPI)
+
+	v1 := r.Group("/v1", middleware.NewAPIKeysAuth(keys))
+	v1.GET("/providers", controller.GetProviders)
+	v1.GET("/providers/:name", controller.GetProvider)
+	v1.GET("/providers/:name/token", controller.GetToken)
+	v1.GET("/providers/:name/token/watch", controller.WatchTokens)
+	v1.POST("/providers/:name/token/report-invalid", controller.ReportInvalidToken)
+
+	// Administrative operations are only available with their own API key.
+	if adminAPIKey := os.Getenv("ARCADE_ADMIN_API_KEY"); adminAPIKey != "" {
+		admin := r.Group("/", middleware.NewAdminAPIKeyAuth(adminAPIKey))
+		admin.POST("/tokens/refresh", controller.RefreshToken)
+		admin.DELETE("/tokens/cache", controller.InvalidateToken)
+		admin.POST("/v1/providers/:name/token/refresh", controller.RefreshToken)
+		admin.DELETE("/v1/providers/:name/token/cache", controller.InvalidateToken)
+	}
+
+	r.NoRoute(controller.NotFound)
+
+	// ctx is canceled once a shutdown signal is received.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Watches are not traced, as they last as long as their clients.
+	srv := &http.Server{
+		Addr:           cfg.listenAddress,
+		Handler:        tracing.NewHandler(r, "/healthz", "/readyz", "/metrics", "/tokens/watch", "/v1/providers/*/token/watch"),
+		ReadTimeout:    cfg.readTimeout,
+		WriteTimeout:   cfg.writeTimeout,
+		IdleTimeout:    cfg.idleTimeout,
+		MaxHeaderBytes: cfg.maxHeaderBytes,
+		ErrorLog:       slog.NewLogLogger(logger.Handler(), slog.LevelWarn),
+	}
+
+	srv.RegisterOnShutdown(func() { close(done) })
+
+	errc := make(chan error, 2)
+
+	go func() {
+		slog.Info("listening", slog.String("address", cfg.listenAddress))
+		errc <- srv.ListenAndServe()
+	}()
+
+	// Background tasks run until a shutdown signal is received.
+	var wg sync.WaitGroup
+
+	for _, run := range background {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			run(ctx)
+		}()
+	}
+
+	var grpcSrv *arcadegrpc.Server
+
+	if cfg.grpcListenAddress != "" {
+		lis, err := net.Listen("tcp", cfg.grpcListenAddress)
+		if err != nil {
+			fatal("error listening for gRPC", err)
+		}
+
+		grpcSrv = arcadegrpc.NewServer(&controller, keys, logger)
+
+		go func() {
+			slog.Info("listening for gRPC", slog.String("address", cfg.grpcListenAddress))
+			errc <- grpcSrv.Serve(lis)
+		}()
+	}
+
+	select {
+	case err := <-errc:
+		fatal("error running server", err)
+	case <-ctx.Done():
+	}
+
+	stop()
+	slog.Info("shutting down, draining in-flight requests")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("error shutting down server", slog.Any("error", err))
+	}
+
+	if grpcSrv != nil {
+		if err := grpcSrv.Shutdown(shutdownCtx); err != nil {
+			slog.Error("error shutting down gRPC server", slog.Any("error", err))
+		}
+	}
+
+	wg.Wait()
+
+	if cfg.revokeTokensOnShutdown {
+		cleanup(controller.RevokeTokens, "error revoking tokens")
+	}
+
+	cleanup(controller.Auditor.Close, "error closing audit log")
+	cleanup(shutdownTracing, "error flushing traces")
+
+	slog.Info("shutdown complete")
 }
 
-// Run arcade on port 1982.
-func main() {
-	log.SetFlags(log.Ldate | log.Ltime | log.Lmicroseconds | log.Lshortfile)
+// cleanup runs a step of shutdown with its own timeout, logging msg if it
+// fails.
+func cleanup(step func(context.Context) error, msg string) {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
 
-	if err := r.Run(":1982"); err != nil {
-		log.Fatal(err)
+	if err := step(ctx); err != nil {
+		slog.Error(msg, slog.Any("error", err))
 	}
 }
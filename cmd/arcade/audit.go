@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/homedepot/arcade/internal/audit"
+)
+
+// newAuditor returns an Auditor writing to the configured sink, or nil if
+// auditing is disabled.
+func newAuditor(cfg config) (*audit.Auditor, error) {
+	var sink audit.Sink
+
+	switch cfg.auditSink {
+	case "", "none":
+		return nil, nil
+	case "stdout":
+		sink = audit.NewWriterSink(os.Stdout)
+	case "file":
+		s, err := audit.NewFileSink(cfg.auditFile, cfg.auditFileMaxBytes, cfg.auditFileMaxBackups)
+		if err != nil {
+			return nil, err
+		}
+
+		sink = s
+	case "webhook":
+		if cfg.auditWebhookURL == "" {
+			return nil, errors.New("audit webhook URL not set")
+		}
+
+		sink = audit.NewWebhookSink(cfg.auditWebhookURL, cfg.auditWebhookTimeout)
+	default:
+		return nil, fmt.Errorf("invalid audit sink %q", cfg.auditSink)
+	}
+
+	return audit.New(sink, cfg.auditBufferSize), nil
+}
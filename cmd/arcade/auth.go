@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/homedepot/arcade/internal/filesink"
+	"github.com/homedepot/arcade/internal/middleware"
+	"github.com/homedepot/arcade/internal/secretsync"
+)
+
+// reservedCallers are the names of the callers arcade authenticates or
+// requests tokens as itself, which API keys may not be named after.
+var reservedCallers = []string{middleware.AdminAPIKeyName, filesink.Caller, secretsync.Caller}
+
+// apiKeys returns the API keys requests are authenticated with, keyed by
+// the name of their callers: the key in ARCADE_API_KEY, named default, and
+// the comma separated name=key pairs in ARCADE_API_KEYS.
+func apiKeys() (map[string]string, error) {
+	keys := map[string]string{}
+
+	if key := os.Getenv("ARCADE_API_KEY"); key != "" {
+		keys[middleware.DefaultAPIKeyName] = key
+	}
+
+	if list := os.Getenv("ARCADE_API_KEYS"); list != "" {
+		for _, pair := range strings.Split(list, ",") {
+			name, key, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || name == "" || key == "" {
+				return nil, errors.New("ARCADE_API_KEYS must be comma separated name=key pairs")
+			}
+
+			if slices.Contains(reservedCallers, name) {
+				return nil, fmt.Errorf("ARCADE_API_KEYS: the name %q is reserved", name)
+			}
+
+			if _, ok := keys[name]; ok {
+				return nil, fmt.Errorf("ARCADE_API_KEYS: duplicate API key name %q", name)
+			}
+
+			keys[name] = key
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("ARCADE_API_KEY or ARCADE_API_KEYS must be set")
+	}
+
+	return keys, nil
+}
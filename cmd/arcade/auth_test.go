@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+
+	"github.com/homedepot/arcade/internal/filesink"
+	"github.com/homedepot/arcade/internal/secretsync"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Auth", func() {
+	var (
+		keys map[string]string
+		err  error
+	)
+
+	BeforeEach(func() {
+		os.Setenv("ARCADE_API_KEY", "default-key")
+		os.Setenv("ARCADE_API_KEYS", "ci=ci-key")
+	})
+
+	AfterEach(func() {
+		os.Unsetenv("ARCADE_API_KEY")
+		os.Unsetenv("ARCADE_API_KEYS")
+	})
+
+	JustBeforeEach(func() {
+		keys, err = apiKeys()
+	})
+
+	Describe("#apiKeys", func() {
+		It("returns every API key by name", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(keys).To(Equal(map[string]string{"default": "default-key", "ci": "ci-key"}))
+		})
+
+		When("an API key is named after the admin", func() {
+			BeforeEach(func() {
+				os.Setenv("ARCADE_API_KEYS", "admin=admin-key")
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError(`ARCADE_API_KEYS: the name "admin" is reserved`))
+			})
+		})
+
+		When("an API key is named after the file sink", func() {
+			BeforeEach(func() {
+				os.Setenv("ARCADE_API_KEYS", filesink.Caller+"=sink-key")
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError(`ARCADE_API_KEYS: the name "file-sink" is reserved`))
+			})
+		})
+
+		When("an API key is named after the secret sync", func() {
+			BeforeEach(func() {
+				os.Setenv("ARCADE_API_KEYS", "ci=ci-key,"+secretsync.Caller+"=sync-key")
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError(`ARCADE_API_KEYS: the name "secret-sync" is reserved`))
+			})
+		})
+	})
+})
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/homedepot/arcade/internal/cache"
+	"github.com/redis/go-redis/v9"
+)
+
+// newCacheStore returns the configured store for cached tokens and the
+// secret it encrypts them with, or nil if tokens are only cached in memory.
+func newCacheStore(cfg config) (cache.Store, []byte, error) {
+	if cfg.cacheFile == "" && cfg.cacheRedisAddress == "" {
+		return nil, nil, nil
+	}
+
+	if cfg.cacheFile != "" && cfg.cacheRedisAddress != "" {
+		return nil, nil, errors.New("only one of a cache file and a Redis cache may be configured")
+	}
+
+	// Tokens shared through Redis are still used by other replicas when
+	// one shuts down, so they must not be revoked.
+	if cfg.cacheRedisAddress != "" && cfg.revokeTokensOnShutdown {
+		return nil, nil, errors.New("tokens cannot be revoked on shutdown when shared through a Redis cache")
+	}
+
+	secret, err := cacheKey(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if cfg.cacheFile != "" {
+		s, err := cache.NewFileStore(cfg.cacheFile, secret)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return s, secret, nil
+	}
+
+	opts := &redis.Options{
+		Addr:     cfg.cacheRedisAddress,
+		Password: os.Getenv("ARCADE_CACHE_REDIS_PASSWORD"),
+		DB:       cfg.cacheRedisDB,
+		// Requests fall back to the in-memory cache when Redis is
+		// unavailable, so fail fast rather than delay them.
+		DialTimeout:  time.Second,
+		ReadTimeout:  time.Second,
+		WriteTimeout: time.Second,
+		MaxRetries:   -1,
+	}
+
+	if cfg.cacheRedisTLS {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	s, err := cache.NewRedisStore(redis.NewClient(opts), secret)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return s, secret, nil
+}
+
+// cacheKey returns the secret that stored tokens are encrypted with, read
+// from the key file if one is configured, otherwise from ARCADE_CACHE_KEY.
+func cacheKey(cfg config) ([]byte, error) {
+	if cfg.cacheKeyFile != "" {
+		b, err := os.ReadFile(cfg.cacheKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading cache key file: %w", err)
+		}
+
+		b = bytes.TrimSpace(b)
+		if len(b) == 0 {
+			return nil, errors.New("cache key file is empty")
+		}
+
+		return b, nil
+	}
+
+	if k := os.Getenv("ARCADE_CACHE_KEY"); k != "" {
+		return []byte(k), nil
+	}
+
+	return nil, errors.New("ARCADE_CACHE_KEY or a cache key file is required to store tokens")
+}
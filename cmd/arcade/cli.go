@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/homedepot/arcade/internal/cli"
+)
+
+// runCLI runs a command of the arcade command line client, returning the
+// process exit code.
+func runCLI(args []string) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	c := cli.New(cli.NewClient(os.Getenv), os.Stdin, os.Stdout, os.Stderr, os.Getenv)
+
+	err := c.Run(ctx, args)
+
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, cli.ErrUsage):
+		fmt.Fprintf(os.Stderr, "arcade: %s\n", err)
+
+		return 2
+	default:
+		fmt.Fprintf(os.Stderr, "arcade: %s\n", err)
+
+		return 1
+	}
+}
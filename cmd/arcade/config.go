@@ -0,0 +1,173 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// config holds the server configuration. Every setting can be provided as
+// a command line flag or through its environment variable, with the flag
+// taking precedence.
+type config struct {
+	configDirectory        string
+	listenAddress          string
+	grpcListenAddress      string
+	readTimeout            time.Duration
+	writeTimeout           time.Duration
+	idleTimeout            time.Duration
+	maxHeaderBytes         int
+	shutdownTimeout        time.Duration
+	revokeTokensOnShutdown bool
+	readinessRequiresToken bool
+	disableDefaultProvider bool
+	logFormat              string
+	logLevel               string
+	auditSink              string
+	auditFile              string
+	auditFileMaxBytes      int64
+	auditFileMaxBackups    int
+	auditWebhookURL        string
+	auditWebhookTimeout    time.Duration
+	auditBufferSize        int
+	callerRateLimit        float64
+	callerRateBurst        int
+	providerRateLimit      float64
+	providerRateBurst      int
+	maxConcurrentRefreshes int
+	cacheFile              string
+	cacheKeyFile           string
+	cacheRedisAddress      string
+	cacheRedisDB           int
+	cacheRedisTLS          bool
+	fileSinkConfig         string
+	secretSyncConfig       string
+}
+
+// flagEnv maps each flag to the environment variable that sets its default.
+var flagEnv = map[string]string{
+	"config-directory":          "ARCADE_CONFIG_DIRECTORY",
+	"listen-address":            "ARCADE_LISTEN_ADDRESS",
+	"grpc-listen-address":       "ARCADE_GRPC_LISTEN_ADDRESS",
+	"read-timeout":              "ARCADE_READ_TIMEOUT",
+	"write-timeout":             "ARCADE_WRITE_TIMEOUT",
+	"idle-timeout":              "ARCADE_IDLE_TIMEOUT",
+	"max-header-bytes":          "ARCADE_MAX_HEADER_BYTES",
+	"shutdown-timeout":          "ARCADE_SHUTDOWN_TIMEOUT",
+	"revoke-tokens-on-shutdown": "ARCADE_REVOKE_TOKENS_ON_SHUTDOWN",
+	"readiness-requires-token":  "ARCADE_READINESS_REQUIRES_TOKEN",
+	"disable-default-provider":  "ARCADE_DISABLE_DEFAULT_PROVIDER",
+	"log-format":                "ARCADE_LOG_FORMAT",
+	"log-level":                 "ARCADE_LOG_LEVEL",
+	"audit-sink":                "ARCADE_AUDIT_SINK",
+	"audit-file":                "ARCADE_AUDIT_FILE",
+	"audit-file-max-bytes":      "ARCADE_AUDIT_FILE_MAX_BYTES",
+	"audit-file-max-backups":    "ARCADE_AUDIT_FILE_MAX_BACKUPS",
+	"audit-webhook-url":         "ARCADE_AUDIT_WEBHOOK_URL",
+	"audit-webhook-timeout":     "ARCADE_AUDIT_WEBHOOK_TIMEOUT",
+	"audit-buffer-size":         "ARCADE_AUDIT_BUFFER_SIZE",
+	"caller-rate-limit":         "ARCADE_CALLER_RATE_LIMIT",
+	"caller-rate-burst":         "ARCADE_CALLER_RATE_BURST",
+	"provider-rate-limit":       "ARCADE_PROVIDER_RATE_LIMIT",
+	"provider-rate-burst":       "ARCADE_PROVIDER_RATE_BURST",
+	"max-concurrent-refreshes":  "ARCADE_MAX_CONCURRENT_REFRESHES",
+	"cache-file":                "ARCADE_CACHE_FILE",
+	"cache-key-file":            "ARCADE_CACHE_KEY_FILE",
+	"cache-redis-address":       "ARCADE_CACHE_REDIS_ADDRESS",
+	"cache-redis-db":            "ARCADE_CACHE_REDIS_DB",
+	"cache-redis-tls":           "ARCADE_CACHE_REDIS_TLS",
+	"file-sink-config":          "ARCADE_FILE_SINK_CONFIG",
+	"secret-sync-config":        "ARCADE_SECRET_SYNC_CONFIG",
+}
+
+// parseConfig reads the configuration from the environment and the given
+// command line arguments.
+func parseConfig(args []string) (config, error) {
+	cfg := config{}
+	fs := flag.NewFlagSet("arcade", flag.ContinueOnError)
+
+	fs.StringVar(&cfg.configDirectory, "config-directory", "",
+		"directory containing the token provider configuration files")
+	fs.StringVar(&cfg.listenAddress, "listen-address", ":1982",
+		"address the HTTP server listens on")
+	fs.StringVar(&cfg.grpcListenAddress, "grpc-listen-address", "",
+		"address the gRPC server listens on, or empty to disable it")
+	fs.DurationVar(&cfg.readTimeout, "read-timeout", 30*time.Second,
+		"maximum duration for reading an entire request")
+	// The write timeout must leave room for the upstream provider timeout.
+	fs.DurationVar(&cfg.writeTimeout, "write-timeout", 60*time.Second,
+		"maximum duration before timing out writes of a response")
+	fs.DurationVar(&cfg.idleTimeout, "idle-timeout", 120*time.Second,
+		"maximum duration to wait for the next request on a keep-alive connection")
+	fs.IntVar(&cfg.maxHeaderBytes, "max-header-bytes", 1<<20,
+		"maximum size of request headers in bytes")
+	fs.DurationVar(&cfg.shutdownTimeout, "shutdown-timeout", 30*time.Second,
+		"maximum duration to wait for in-flight requests to drain on shutdown")
+	fs.BoolVar(&cfg.revokeTokensOnShutdown, "revoke-tokens-on-shutdown", false,
+		"revoke tokens issued by providers that support it on shutdown")
+	fs.BoolVar(&cfg.readinessRequiresToken, "readiness-requires-token", false,
+		"report ready only once every provider has produced a token")
+	fs.BoolVar(&cfg.disableDefaultProvider, "disable-default-provider", false,
+		"require token requests to name their provider instead of defaulting to google")
+	fs.StringVar(&cfg.logFormat, "log-format", "json",
+		"log format, either json or text")
+	fs.StringVar(&cfg.logLevel, "log-level", "info",
+		"minimum log level, one of debug, info, warn or error")
+	fs.StringVar(&cfg.auditSink, "audit-sink", "none",
+		"where token requests are audited, one of none, stdout, file or webhook")
+	fs.StringVar(&cfg.auditFile, "audit-file", "arcade-audit.log",
+		"file the audit log is written to by the file sink")
+	fs.Int64Var(&cfg.auditFileMaxBytes, "audit-file-max-bytes", 100<<20,
+		"size in bytes at which the audit file is rotated, or 0 to never rotate")
+	fs.IntVar(&cfg.auditFileMaxBackups, "audit-file-max-backups", 5,
+		"number of rotated audit files to keep")
+	fs.StringVar(&cfg.auditWebhookURL, "audit-webhook-url", "",
+		"URL audit events are posted to by the webhook sink")
+	fs.DurationVar(&cfg.auditWebhookTimeout, "audit-webhook-timeout", 5*time.Second,
+		"maximum duration of each request to the audit webhook")
+	fs.IntVar(&cfg.auditBufferSize, "audit-buffer-size", 1024,
+		"number of audit events buffered before new events are dropped")
+	fs.Float64Var(&cfg.callerRateLimit, "caller-rate-limit", 0,
+		"token requests per second allowed for each API key, or 0 for no limit")
+	fs.IntVar(&cfg.callerRateBurst, "caller-rate-burst", 20,
+		"token requests each API key may make at once above its rate limit")
+	fs.Float64Var(&cfg.providerRateLimit, "provider-rate-limit", 0,
+		"token requests per second allowed for each provider, or 0 for no limit")
+	fs.IntVar(&cfg.providerRateBurst, "provider-rate-burst", 50,
+		"token requests each provider may receive at once above its rate limit")
+	fs.IntVar(&cfg.maxConcurrentRefreshes, "max-concurrent-refreshes", 0,
+		"maximum concurrent requests to each upstream provider, or 0 for no limit")
+	fs.StringVar(&cfg.cacheFile, "cache-file", "",
+		"file tokens are persisted to, encrypted, to survive restarts")
+	fs.StringVar(&cfg.cacheKeyFile, "cache-key-file", "",
+		"file containing the key tokens are encrypted with, instead of ARCADE_CACHE_KEY")
+	fs.StringVar(&cfg.cacheRedisAddress, "cache-redis-address", "",
+		"address of a Redis server tokens are shared through, encrypted, between replicas")
+	fs.IntVar(&cfg.cacheRedisDB, "cache-redis-db", 0,
+		"Redis database tokens are shared through")
+	fs.BoolVar(&cfg.cacheRedisTLS, "cache-redis-tls", false,
+		"connect to Redis using TLS")
+	fs.StringVar(&cfg.fileSinkConfig, "file-sink-config", "",
+		"file listing the files provider tokens are written to, or empty to write none")
+	fs.StringVar(&cfg.secretSyncConfig, "secret-sync-config", "",
+		"file listing the Kubernetes Secrets provider tokens are synced to, or empty to sync none")
+
+	fs.VisitAll(func(f *flag.Flag) {
+		f.Usage = fmt.Sprintf("%s (env %s)", f.Usage, flagEnv[f.Name])
+	})
+
+	for name, env := range flagEnv {
+		if v := os.Getenv(env); v != "" {
+			if err := fs.Set(name, v); err != nil {
+				return cfg, fmt.Errorf("invalid value %q for %s: %w", v, env, err)
+			}
+		}
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,143 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	arcade "github.com/homedepot/arcade/pkg"
+)
+
+const (
+	OutcomeSuccess             = "success"
+	OutcomeError               = "error"
+	OutcomeUnsupportedProvider = "unsupported_provider"
+	OutcomeRateLimited         = "rate_limited"
+	OutcomeForbidden           = "forbidden"
+
+	// ActionReportInvalid records a caller reporting that a token was
+	// rejected, and ActionRefresh and ActionInvalidate an admin refreshing
+	// or invalidating a cached token. Events of token requests have no
+	// action.
+	ActionReportInvalid = "report_invalid"
+	ActionRefresh       = "refresh"
+	ActionInvalidate    = "invalidate"
+
+	defaultBufferSize = 1024
+)
+
+// Event records a single token request, or another action on the token of
+// a provider.
+type Event struct {
+	Time             time.Time `json:"time"`
+	RequestID        string    `json:"requestId,omitempty"`
+	Caller           string    `json:"caller,omitempty"`
+	SourceAddress    string    `json:"sourceAddress,omitempty"`
+	Action           string    `json:"action,omitempty"`
+	Provider         string    `json:"provider"`
+	Cluster          string    `json:"cluster,omitempty"`
+	Outcome          string    `json:"outcome"`
+	CacheHit         bool      `json:"cacheHit"`
+	TokenFingerprint string    `json:"tokenFingerprint,omitempty"`
+}
+
+// Fingerprint returns a value identifying a token without revealing it.
+func Fingerprint(token string) string {
+	return arcade.Fingerprint(token)
+}
+
+// Sink is a destination for audit events.
+type Sink interface {
+	Write(context.Context, Event) error
+	Close() error
+}
+
+// Auditor records events to a Sink in the background, so that a slow or
+// failing sink never delays or fails token requests. Events recorded while
+// the buffer is full are dropped and logged. A nil Auditor records
+// nothing.
+type Auditor struct {
+	sink   Sink
+	events chan Event
+	done   chan struct{}
+	// mux guards closed, so that events are not sent once events is
+	// closed.
+	mux    sync.Mutex
+	closed bool
+}
+
+// New returns an Auditor writing to sink, buffering up to bufferSize
+// events. A default buffer size is used if bufferSize is not positive.
+func New(sink Sink, bufferSize int) *Auditor {
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+
+	a := &Auditor{
+		sink:   sink,
+		events: make(chan Event, bufferSize),
+		done:   make(chan struct{}),
+	}
+
+	go a.run()
+
+	return a
+}
+
+func (a *Auditor) run() {
+	defer close(a.done)
+
+	for e := range a.events {
+		if err := a.sink.Write(context.Background(), e); err != nil {
+			slog.Error("audit: error writing event",
+				slog.String("request_id", e.RequestID), slog.Any("error", err))
+		}
+	}
+}
+
+// Record queues an event to be written to the sink. Events recorded once
+// the Auditor is closed are dropped and logged.
+func (a *Auditor) Record(e Event) {
+	if a == nil {
+		return
+	}
+
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	if a.closed {
+		slog.Error("audit: auditor closed, dropping event", slog.String("request_id", e.RequestID))
+
+		return
+	}
+
+	select {
+	case a.events <- e:
+	default:
+		slog.Error("audit: buffer full, dropping event", slog.String("request_id", e.RequestID))
+	}
+}
+
+// Close stops accepting events, waiting until the buffered events have
+// been written or ctx is done, and closes the sink.
+func (a *Auditor) Close(ctx context.Context) error {
+	if a == nil {
+		return nil
+	}
+
+	a.mux.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.events)
+	}
+	a.mux.Unlock()
+
+	select {
+	case <-a.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return a.sink.Close()
+}
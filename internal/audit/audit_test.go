@@ -0,0 +1,211 @@
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/homedepot/arcade/internal/audit"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeSink struct {
+	mux    sync.Mutex
+	events []audit.Event
+	err    error
+	block  chan struct{}
+	closed bool
+}
+
+func (f *fakeSink) Write(_ context.Context, e audit.Event) error {
+	if f.block != nil {
+		<-f.block
+	}
+
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	f.events = append(f.events, e)
+
+	return f.err
+}
+
+func (f *fakeSink) Close() error {
+	f.closed = true
+
+	return nil
+}
+
+var _ = Describe("Audit", func() {
+	Describe("#Fingerprint", func() {
+		It("identifies a token without revealing it", func() {
+			f := audit.Fingerprint("some-token")
+			Expect(f).To(HavePrefix("sha256:"))
+			Expect(f).ToNot(ContainSubstring("some-token"))
+			Expect(audit.Fingerprint("some-token")).To(Equal(f))
+			Expect(audit.Fingerprint("other-token")).ToNot(Equal(f))
+			Expect(audit.Fingerprint("")).To(BeEmpty())
+		})
+	})
+
+	Describe("#Auditor", func() {
+		var (
+			sink    *fakeSink
+			auditor *audit.Auditor
+		)
+
+		BeforeEach(func() {
+			sink = &fakeSink{}
+		})
+
+		When("the auditor is nil", func() {
+			It("records nothing", func() {
+				auditor.Record(audit.Event{Provider: "google"})
+				Expect(auditor.Close(context.Background())).To(Succeed())
+			})
+		})
+
+		When("the sink fails", func() {
+			BeforeEach(func() {
+				sink.err = errors.New("error writing event")
+				auditor = audit.New(sink, 0)
+			})
+
+			It("keeps recording events", func() {
+				auditor.Record(audit.Event{Provider: "google"})
+				auditor.Record(audit.Event{Provider: "rancher"})
+				Expect(auditor.Close(context.Background())).To(Succeed())
+				Expect(sink.events).To(HaveLen(2))
+				Expect(sink.closed).To(BeTrue())
+			})
+		})
+
+		When("events are recorded after it is closed", func() {
+			BeforeEach(func() {
+				auditor = audit.New(sink, 0)
+			})
+
+			It("drops them", func() {
+				auditor.Record(audit.Event{Provider: "google"})
+				Expect(auditor.Close(context.Background())).To(Succeed())
+
+				Expect(func() { auditor.Record(audit.Event{Provider: "rancher"}) }).ToNot(Panic())
+				Expect(auditor.Close(context.Background())).To(Succeed())
+				Expect(sink.events).To(HaveLen(1))
+			})
+		})
+
+		When("the buffer is full", func() {
+			BeforeEach(func() {
+				sink.block = make(chan struct{})
+				auditor = audit.New(sink, 1)
+			})
+
+			It("drops events instead of blocking", func() {
+				for i := 0; i < 10; i++ {
+					auditor.Record(audit.Event{Provider: "google"})
+				}
+
+				close(sink.block)
+				Expect(auditor.Close(context.Background())).To(Succeed())
+				Expect(len(sink.events)).To(BeNumerically("<", 10))
+			})
+		})
+	})
+
+	Describe("#FileSink", func() {
+		var (
+			dir  string
+			path string
+			sink *audit.FileSink
+		)
+
+		BeforeEach(func() {
+			var err error
+
+			dir, err = os.MkdirTemp("", "arcade-audit")
+			Expect(err).ToNot(HaveOccurred())
+			path = filepath.Join(dir, "audit.log")
+			sink, err = audit.NewFileSink(path, 200, 2)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("writes events as JSON lines", func() {
+			Expect(sink.Write(context.Background(), audit.Event{Provider: "google", Outcome: audit.OutcomeSuccess})).To(Succeed())
+			Expect(sink.Close()).To(Succeed())
+
+			b, err := os.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+
+			var e audit.Event
+			Expect(json.Unmarshal(b, &e)).To(Succeed())
+			Expect(e.Provider).To(Equal("google"))
+		})
+
+		It("rotates the file, keeping the configured number of backups", func() {
+			for i := 0; i < 10; i++ {
+				Expect(sink.Write(context.Background(), audit.Event{Provider: "google", Outcome: audit.OutcomeSuccess})).To(Succeed())
+			}
+
+			Expect(sink.Close()).To(Succeed())
+			Expect(path + ".1").To(BeAnExistingFile())
+			Expect(path + ".2").To(BeAnExistingFile())
+			Expect(path + ".3").ToNot(BeAnExistingFile())
+
+			info, err := os.Stat(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Size()).To(BeNumerically("<=", 200))
+		})
+	})
+
+	Describe("#WebhookSink", func() {
+		var (
+			svr    *httptest.Server
+			status int
+			got    audit.Event
+		)
+
+		BeforeEach(func() {
+			status = http.StatusNoContent
+			svr = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				_ = json.Unmarshal(b, &got)
+				w.WriteHeader(status)
+			}))
+		})
+
+		AfterEach(func() {
+			svr.Close()
+		})
+
+		It("posts the event", func() {
+			sink := audit.NewWebhookSink(svr.URL, time.Second)
+			Expect(sink.Write(context.Background(), audit.Event{Provider: "rancher"})).To(Succeed())
+			Expect(got.Provider).To(Equal("rancher"))
+		})
+
+		When("the webhook returns an error", func() {
+			BeforeEach(func() {
+				status = http.StatusInternalServerError
+			})
+
+			It("returns an error", func() {
+				sink := audit.NewWebhookSink(svr.URL, time.Second)
+				err := sink.Write(context.Background(), audit.Event{Provider: "rancher"})
+				Expect(err).To(MatchError("webhook returned 500 Internal Server Error"))
+			})
+		})
+	})
+})
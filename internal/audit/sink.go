@@ -0,0 +1,189 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// WriterSink writes events to an io.Writer as a stream of JSON objects,
+// one per line.
+type WriterSink struct {
+	mux sync.Mutex
+	w   io.Writer
+}
+
+// NewWriterSink returns a WriterSink writing to w.
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+func (s *WriterSink) Write(_ context.Context, e Event) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	_, err = s.w.Write(append(b, '\n'))
+
+	return err
+}
+
+func (s *WriterSink) Close() error {
+	return nil
+}
+
+// FileSink writes events to a file as JSON lines, rotating it once it
+// reaches a maximum size. Rotated files are named after the file with a
+// numeric suffix, ".1" being the most recent.
+type FileSink struct {
+	mux        sync.Mutex
+	path       string
+	maxBytes   int64
+	maxBackups int
+	f          *os.File
+	size       int64
+}
+
+// NewFileSink opens, or creates, the file at path for appending events.
+// The file is rotated when it exceeds maxBytes, keeping maxBackups rotated
+// files. Files are never rotated if maxBytes is not positive.
+func NewFileSink(path string, maxBytes int64, maxBackups int) (*FileSink, error) {
+	s := &FileSink{
+		path:       path,
+		maxBytes:   maxBytes,
+		maxBackups: maxBackups,
+	}
+
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *FileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("error opening audit file: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+
+		return fmt.Errorf("error opening audit file: %w", err)
+	}
+
+	s.f = f
+	s.size = info.Size()
+
+	return nil
+}
+
+func (s *FileSink) rotate() error {
+	if err := s.f.Close(); err != nil {
+		return err
+	}
+
+	if s.maxBackups > 0 {
+		for i := s.maxBackups - 1; i > 0; i-- {
+			_ = os.Rename(fmt.Sprintf("%s.%d", s.path, i), fmt.Sprintf("%s.%d", s.path, i+1))
+		}
+
+		if err := os.Rename(s.path, s.path+".1"); err != nil {
+			return err
+		}
+	} else if err := os.Remove(s.path); err != nil {
+		return err
+	}
+
+	return s.open()
+}
+
+func (s *FileSink) Write(_ context.Context, e Event) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	b = append(b, '\n')
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.maxBytes > 0 && s.size > 0 && s.size+int64(len(b)) > s.maxBytes {
+		if err := s.rotate(); err != nil {
+			return fmt.Errorf("error rotating audit file: %w", err)
+		}
+	}
+
+	n, err := s.f.Write(b)
+	s.size += int64(n)
+
+	return err
+}
+
+func (s *FileSink) Close() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.f.Close()
+}
+
+// WebhookSink posts each event as JSON to a URL.
+type WebhookSink struct {
+	c   *http.Client
+	url string
+}
+
+// NewWebhookSink returns a WebhookSink posting to url, giving up on each
+// request after timeout.
+func NewWebhookSink(url string, timeout time.Duration) *WebhookSink {
+	return &WebhookSink{
+		c:   &http.Client{Timeout: timeout},
+		url: url,
+	}
+}
+
+func (s *WebhookSink) Write(ctx context.Context, e Event) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := s.c.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", res.Status)
+	}
+
+	return nil
+}
+
+func (s *WebhookSink) Close() error {
+	return nil
+}
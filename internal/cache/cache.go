@@ -0,0 +1,453 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/homedepot/arcade/pkg/provider"
+)
+
+const (
+	// lockTTL bounds how long a process may hold the lock on a token in
+	// a shared store, and how long others wait for it.
+	lockTTL = 30 * time.Second
+	// lockPollInterval is how often a process waiting for another to
+	// refresh a token checks the store.
+	lockPollInterval = 100 * time.Millisecond
+)
+
+// Policy controls how long tokens are cached.
+type Policy struct {
+	// RefreshRatio is the fraction of a token's lifetime after which it
+	// is refreshed, for example 0.9 to refresh tokens once 90% of their
+	// lifetime has passed. Tokens are used until they expire if zero.
+	RefreshRatio float64
+	// MinTTL is the lifetime a cached token must have left to be
+	// returned. Tokens are refreshed once less remains.
+	MinTTL time.Duration
+	// MaxTTL is the longest a token is cached, whatever its expiry. It is
+	// also how long tokens without an expiration are cached; such tokens
+	// are not cached if MaxTTL is zero.
+	MaxTTL time.Duration
+	// ErrorTTL is how long an error from the upstream provider is
+	// returned to callers before the token is requested again. Errors are
+	// not cached if zero.
+	ErrorTTL time.Duration
+}
+
+// freshUntil returns until when a token fetched at now should be returned
+// from cache. Tokens that should not be cached are fresh until now.
+func (p Policy) freshUntil(now time.Time, t provider.Token) time.Time {
+	if t.Expiry.IsZero() {
+		return now.Add(p.MaxTTL)
+	}
+
+	until := t.Expiry
+	if p.RefreshRatio > 0 {
+		until = now.Add(time.Duration(float64(t.Expiry.Sub(now)) * p.RefreshRatio))
+	}
+
+	if p.MaxTTL > 0 && until.After(now.Add(p.MaxTTL)) {
+		until = now.Add(p.MaxTTL)
+	}
+
+	if latest := t.Expiry.Add(-p.MinTTL); until.After(latest) {
+		until = latest
+	}
+
+	return until
+}
+
+type entry struct {
+	token      string
+	expiry     time.Time
+	err        error
+	freshUntil time.Time
+}
+
+// Cache is a Client returning tokens from a Fetcher, caching them
+// according to a Policy. Tokens are cached separately for each value of
+// provider.ProviderKey in the request context, such as the cluster of a
+// Vault K8s provider.
+//
+// Cache does not serialize requests; concurrent requests for a token that
+// is not cached each fetch a new one.
+type Cache struct {
+	fetcher   provider.Fetcher
+	policy    Policy
+	mux       sync.Mutex
+	entries   map[string]entry
+	changed   map[string]chan struct{}
+	store     Store
+	namespace string
+}
+
+// New returns a Cache of the tokens fetched by f.
+func New(f provider.Fetcher, p Policy) *Cache {
+	return &Cache{
+		fetcher: f,
+		policy:  p,
+		entries: map[string]entry{},
+		changed: map[string]chan struct{}{},
+	}
+}
+
+// WithStore sets a Store that tokens are also saved to, and looked up in
+// when not cached in memory. Tokens are stored under keys prefixed with
+// namespace, which should identify the provider configuration so that
+// tokens are not shared between different configurations. Errors are
+// only cached in memory.
+func (c *Cache) WithStore(s Store, namespace string) {
+	c.store = s
+	c.namespace = namespace
+}
+
+// Token returns a cached token if it is still fresh, otherwise it fetches
+// and caches a new one. A new token is always fetched if ctx requests
+// that the cache be bypassed with provider.WithNoCache.
+func (c *Cache) Token(ctx context.Context) (string, error) {
+	key, _ := ctx.Value(provider.ProviderKey).(string)
+
+	if !provider.NoCache(ctx) {
+		c.mux.Lock()
+		e, ok := c.entries[key]
+		c.mux.Unlock()
+
+		if ok && time.Now().Before(e.freshUntil) {
+			return e.token, e.err
+		}
+
+		if e, ok := c.load(ctx, key); ok {
+			return e.token, nil
+		}
+
+		unlock, e, ok := c.lock(ctx, key)
+		defer unlock()
+
+		if ok {
+			return e.token, nil
+		}
+	}
+
+	var t provider.Token
+
+	err := provider.Refresh(ctx, func(ctx context.Context) (time.Time, error) {
+		var err error
+
+		t, err = c.fetcher.Fetch(ctx)
+
+		return t.Expiry, err
+	})
+
+	now := time.Now()
+
+	var e entry
+
+	switch {
+	case err == nil:
+		e = entry{token: t.AccessToken, expiry: t.Expiry, freshUntil: c.policy.freshUntil(now, t)}
+	case ctx.Err() == nil && !errors.Is(err, provider.ErrRefreshLimited):
+		// Only errors from the upstream provider are cached, not those
+		// caused by the request being canceled or limited.
+		e = entry{err: err, freshUntil: now.Add(c.policy.ErrorTTL)}
+	default:
+		return "", err
+	}
+
+	c.mux.Lock()
+	if e.freshUntil.After(now) {
+		c.entries[key] = e
+	} else {
+		delete(c.entries, key)
+	}
+	c.notify(key)
+	c.mux.Unlock()
+
+	if e.err == nil && e.freshUntil.After(now) {
+		c.save(ctx, key, e)
+	}
+
+	return e.token, e.err
+}
+
+// load looks key up in the store, caching a fresh token in memory.
+func (c *Cache) load(ctx context.Context, key string) (entry, bool) {
+	if c.store == nil {
+		return entry{}, false
+	}
+
+	se, ok, err := c.store.Get(ctx, c.namespace+"/"+key)
+	if err != nil {
+		slog.WarnContext(ctx, "error reading token from cache store", slog.Any("error", err))
+
+		return entry{}, false
+	}
+
+	if !ok || !time.Now().Before(se.FreshUntil) {
+		return entry{}, false
+	}
+
+	e := entry{token: se.Token, expiry: se.Expiry, freshUntil: se.FreshUntil}
+
+	c.mux.Lock()
+	c.entries[key] = e
+	c.notify(key)
+	c.mux.Unlock()
+
+	return e, true
+}
+
+// lock acquires the lock on key if the store is shared between processes,
+// so that only one of them refreshes the token. While another process
+// holds the lock, lock waits for it to store a new token and returns that
+// token. The token is refreshed without the lock if the store is
+// unavailable or the other process fails to store a token in time.
+func (c *Cache) lock(ctx context.Context, key string) (func(), entry, bool) {
+	noop := func() {}
+
+	l, ok := c.store.(Locker)
+	if !ok {
+		return noop, entry{}, false
+	}
+
+	deadline := time.Now().Add(lockTTL)
+
+	for {
+		unlock, acquired, err := l.Lock(ctx, c.namespace+"/"+key, lockTTL)
+		if err != nil {
+			slog.WarnContext(ctx, "error locking token in cache store", slog.Any("error", err))
+
+			return noop, entry{}, false
+		}
+
+		if acquired {
+			// Another process may have stored a token since it was
+			// last looked up.
+			if e, ok := c.load(ctx, key); ok {
+				unlock()
+
+				return noop, e, true
+			}
+
+			return unlock, entry{}, false
+		}
+
+		select {
+		case <-ctx.Done():
+			return noop, entry{}, false
+		case <-time.After(lockPollInterval):
+		}
+
+		if e, ok := c.load(ctx, key); ok {
+			return noop, e, true
+		}
+
+		if time.Now().After(deadline) {
+			return noop, entry{}, false
+		}
+	}
+}
+
+// save saves a token to the store.
+func (c *Cache) save(ctx context.Context, key string, e entry) {
+	if c.store == nil {
+		return
+	}
+
+	err := c.store.Set(ctx, c.namespace+"/"+key, Entry{Token: e.token, Expiry: e.expiry, FreshUntil: e.freshUntil})
+	if err != nil {
+		slog.WarnContext(ctx, "error writing token to cache store", slog.Any("error", err))
+	}
+}
+
+// Len returns the number of fresh tokens cached in memory.
+func (c *Cache) Len() int {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	n := 0
+	now := time.Now()
+
+	for _, e := range c.entries {
+		if e.err == nil && now.Before(e.freshUntil) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Lookup returns the token cached in memory for the request parameters in
+// ctx, whether or not it is still fresh.
+func (c *Cache) Lookup(ctx context.Context) (Entry, bool) {
+	key, _ := ctx.Value(provider.ProviderKey).(string)
+
+	c.mux.Lock()
+	e, ok := c.entries[key]
+	c.mux.Unlock()
+
+	if !ok || e.err != nil {
+		return Entry{}, false
+	}
+
+	return Entry{Token: e.token, Expiry: e.expiry, FreshUntil: e.freshUntil}, true
+}
+
+// Expiries returns when each token cached in memory expires, keyed by the
+// value of provider.ProviderKey it was requested with. Errors and tokens
+// that have expired or whose expiry is unknown are omitted, so tokens
+// removed by Invalidate, Clear or a failed refresh are no longer returned.
+func (c *Cache) Expiries() map[string]time.Time {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	expiries := map[string]time.Time{}
+	now := time.Now()
+
+	for key, e := range c.entries {
+		if e.err == nil && e.expiry.After(now) {
+			expiries[key] = e.expiry
+		}
+	}
+
+	return expiries
+}
+
+// Changed returns a channel that is closed the next time the token cached
+// for the request parameters in ctx is replaced or removed, such as when
+// it is refreshed.
+func (c *Cache) Changed(ctx context.Context) <-chan struct{} {
+	key, _ := ctx.Value(provider.ProviderKey).(string)
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	ch, ok := c.changed[key]
+	if !ok {
+		ch = make(chan struct{})
+		c.changed[key] = ch
+	}
+
+	return ch
+}
+
+// notify closes the channel returned by Changed for key, if any. c.mux
+// must be held.
+func (c *Cache) notify(key string) {
+	if ch, ok := c.changed[key]; ok {
+		close(ch)
+		delete(c.changed, key)
+	}
+}
+
+// Expiry returns when token expires if it is the token cached for the
+// request parameters in ctx and its expiry is known.
+func (c *Cache) Expiry(ctx context.Context, token string) (time.Time, bool) {
+	key, _ := ctx.Value(provider.ProviderKey).(string)
+
+	c.mux.Lock()
+	e, ok := c.entries[key]
+	c.mux.Unlock()
+
+	if !ok || e.err != nil || e.token != token || e.expiry.IsZero() {
+		return time.Time{}, false
+	}
+
+	return e.expiry, true
+}
+
+// Invalidate removes the token cached for the request parameters in ctx,
+// including from the store, so that the next request fetches a new one.
+// Other processes sharing the store keep the token cached in their memory
+// until it is due for refresh.
+func (c *Cache) Invalidate(ctx context.Context) {
+	key, _ := ctx.Value(provider.ProviderKey).(string)
+
+	c.mux.Lock()
+	delete(c.entries, key)
+	c.notify(key)
+	c.mux.Unlock()
+
+	if c.store == nil {
+		return
+	}
+
+	if err := c.store.Delete(ctx, c.namespace+"/"+key); err != nil {
+		slog.WarnContext(ctx, "error deleting token from cache store", slog.Any("error", err))
+	}
+}
+
+// Reject removes the token cached for the request parameters in ctx,
+// including from the store, if match reports that it is the token a
+// caller found to be invalid. It returns whether the token was removed.
+// Tokens that have already been replaced are kept, so that callers
+// reporting a stale token do not cause the new one to be discarded.
+func (c *Cache) Reject(ctx context.Context, match func(token string) bool) bool {
+	key, _ := ctx.Value(provider.ProviderKey).(string)
+
+	c.mux.Lock()
+	e, ok := c.entries[key]
+	c.mux.Unlock()
+
+	if !ok || e.err != nil || !time.Now().Before(e.freshUntil) {
+		e, ok = c.load(ctx, key)
+	}
+
+	if !ok || e.err != nil || !match(e.token) {
+		return false
+	}
+
+	c.Invalidate(ctx)
+
+	return true
+}
+
+// Clear removes every cached token, including those saved to the store,
+// so that the next request fetches a new one.
+func (c *Cache) Clear(ctx context.Context) {
+	c.mux.Lock()
+	entries := c.entries
+	c.entries = map[string]entry{}
+	for key := range c.changed {
+		c.notify(key)
+	}
+	c.mux.Unlock()
+
+	if c.store == nil {
+		return
+	}
+
+	for key := range entries {
+		if err := c.store.Delete(ctx, c.namespace+"/"+key); err != nil {
+			slog.WarnContext(ctx, "error deleting token from cache store", slog.Any("error", err))
+		}
+	}
+}
+
+// Revoke revokes the tokens issued by the Fetcher, if it supports
+// revocation, and clears the cache. It must not be called when the store
+// is shared, as other processes may still use the revoked tokens.
+func (c *Cache) Revoke(ctx context.Context) error {
+	r, ok := c.fetcher.(interface {
+		Revoke(context.Context) error
+	})
+	if !ok {
+		return nil
+	}
+
+	if err := r.Revoke(ctx); err != nil {
+		return err
+	}
+
+	c.Clear(ctx)
+
+	return nil
+}
+
+// Fetcher returns the Fetcher whose tokens are cached.
+func (c *Cache) Fetcher() provider.Fetcher {
+	return c.fetcher
+}
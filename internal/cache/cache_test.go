@@ -0,0 +1,365 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/homedepot/arcade/internal/cache"
+	"github.com/homedepot/arcade/pkg/provider"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeRevoker struct {
+	provider.FetcherFunc
+	revoked bool
+}
+
+func (f *fakeRevoker) Revoke(context.Context) error {
+	f.revoked = true
+
+	return nil
+}
+
+var _ = Describe("Cache", func() {
+	var (
+		c        *cache.Cache
+		policy   cache.Policy
+		fetches  int
+		expiry   time.Duration
+		fetchErr error
+		fetcher  provider.FetcherFunc
+		ctx      context.Context
+		token    string
+		err      error
+	)
+
+	BeforeEach(func() {
+		policy = cache.Policy{}
+		fetches = 0
+		expiry = time.Hour
+		fetchErr = nil
+		ctx = context.Background()
+		fetcher = func(ctx context.Context) (provider.Token, error) {
+			fetches++
+
+			if fetchErr != nil {
+				return provider.Token{}, fetchErr
+			}
+
+			t := provider.Token{AccessToken: fmt.Sprintf("token-%d", fetches)}
+			if expiry != 0 {
+				t.Expiry = time.Now().Add(expiry)
+			}
+
+			if key, ok := ctx.Value(provider.ProviderKey).(string); ok {
+				t.AccessToken = key + "-" + t.AccessToken
+			}
+
+			return t, nil
+		}
+	})
+
+	JustBeforeEach(func() {
+		c = cache.New(fetcher, policy)
+		token, err = c.Token(ctx)
+	})
+
+	When("the token is fresh", func() {
+		It("returns the cached token", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(token).To(Equal("token-1"))
+
+			token, err = c.Token(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(token).To(Equal("token-1"))
+			Expect(fetches).To(Equal(1))
+		})
+	})
+
+	When("the token has passed its refresh ratio", func() {
+		BeforeEach(func() {
+			expiry = 100 * time.Millisecond
+			policy.RefreshRatio = 0.5
+		})
+
+		It("fetches a new token", func() {
+			time.Sleep(60 * time.Millisecond)
+
+			token, err = c.Token(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(token).To(Equal("token-2"))
+		})
+	})
+
+	When("the token has less than the minimum TTL left", func() {
+		BeforeEach(func() {
+			expiry = time.Minute
+			policy.MinTTL = 2 * time.Minute
+		})
+
+		It("is not cached", func() {
+			_, _ = c.Token(ctx)
+			Expect(fetches).To(Equal(2))
+		})
+	})
+
+	When("the token outlives the maximum TTL", func() {
+		BeforeEach(func() {
+			policy.MaxTTL = 50 * time.Millisecond
+		})
+
+		It("fetches a new token once the maximum TTL has passed", func() {
+			time.Sleep(60 * time.Millisecond)
+
+			token, err = c.Token(ctx)
+			Expect(token).To(Equal("token-2"))
+		})
+	})
+
+	When("the token has no expiration", func() {
+		BeforeEach(func() {
+			expiry = 0
+		})
+
+		It("is not cached", func() {
+			token, err = c.Token(ctx)
+			Expect(token).To(Equal("token-2"))
+		})
+
+		When("there is a maximum TTL", func() {
+			BeforeEach(func() {
+				policy.MaxTTL = time.Minute
+			})
+
+			It("is cached for the maximum TTL", func() {
+				token, err = c.Token(ctx)
+				Expect(token).To(Equal("token-1"))
+			})
+		})
+	})
+
+	When("requests carry different parameters", func() {
+		BeforeEach(func() {
+			ctx = context.WithValue(ctx, provider.ProviderKey, "vault-k8s-dv-one")
+		})
+
+		It("caches tokens separately", func() {
+			Expect(token).To(Equal("vault-k8s-dv-one-token-1"))
+
+			token, err = c.Token(context.WithValue(context.Background(), provider.ProviderKey, "vault-k8s-dv-two"))
+			Expect(token).To(Equal("vault-k8s-dv-two-token-2"))
+
+			token, err = c.Token(ctx)
+			Expect(token).To(Equal("vault-k8s-dv-one-token-1"))
+		})
+	})
+
+	When("fetching fails", func() {
+		BeforeEach(func() {
+			fetchErr = errors.New("error getting token")
+		})
+
+		It("does not cache the error", func() {
+			Expect(err).To(MatchError("error getting token"))
+
+			_, err = c.Token(ctx)
+			Expect(err).To(HaveOccurred())
+			Expect(fetches).To(Equal(2))
+		})
+
+		When("errors are cached", func() {
+			BeforeEach(func() {
+				policy.ErrorTTL = time.Minute
+			})
+
+			It("returns the cached error", func() {
+				Expect(err).To(MatchError("error getting token"))
+
+				_, err = c.Token(ctx)
+				Expect(err).To(MatchError("error getting token"))
+				Expect(fetches).To(Equal(1))
+			})
+		})
+
+		When("the refresh was limited", func() {
+			BeforeEach(func() {
+				policy.ErrorTTL = time.Minute
+				fetchErr = provider.ErrRefreshLimited
+			})
+
+			It("does not cache the error", func() {
+				_, _ = c.Token(ctx)
+				Expect(fetches).To(Equal(2))
+			})
+		})
+	})
+
+	When("a token is fetched", func() {
+		var refreshed bool
+
+		BeforeEach(func() {
+			refreshed = false
+			ctx = provider.WithTrace(ctx, &provider.Trace{
+				RefreshStart: func() {
+					refreshed = true
+				},
+			})
+		})
+
+		It("reports the refresh to the trace", func() {
+			Expect(refreshed).To(BeTrue())
+
+			refreshed = false
+			_, _ = c.Token(ctx)
+			Expect(refreshed).To(BeFalse())
+		})
+	})
+
+	When("the request bypasses the cache", func() {
+		It("fetches and caches a new token", func() {
+			token, err = c.Token(provider.WithNoCache(ctx))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(token).To(Equal("token-2"))
+
+			token, _ = c.Token(ctx)
+			Expect(token).To(Equal("token-2"))
+			Expect(fetches).To(Equal(2))
+		})
+	})
+
+	Describe("#Invalidate", func() {
+		BeforeEach(func() {
+			ctx = context.WithValue(ctx, provider.ProviderKey, "vault-k8s-dv-one")
+		})
+
+		It("removes only the token cached for the request parameters", func() {
+			other := context.WithValue(context.Background(), provider.ProviderKey, "vault-k8s-dv-two")
+			_, _ = c.Token(other)
+
+			c.Invalidate(ctx)
+
+			token, _ = c.Token(ctx)
+			Expect(token).To(Equal("vault-k8s-dv-one-token-3"))
+
+			token, _ = c.Token(other)
+			Expect(token).To(Equal("vault-k8s-dv-two-token-2"))
+		})
+	})
+
+	Describe("#Len", func() {
+		It("counts the fresh tokens", func() {
+			Expect(c.Len()).To(Equal(1))
+
+			_, _ = c.Token(context.WithValue(ctx, provider.ProviderKey, "vault-k8s-dv-one"))
+			Expect(c.Len()).To(Equal(2))
+
+			c.Clear(ctx)
+			Expect(c.Len()).To(BeZero())
+		})
+	})
+
+	Describe("#Expiry", func() {
+		It("returns the expiry of the cached token", func() {
+			expiry, ok := c.Expiry(ctx, "token-1")
+			Expect(ok).To(BeTrue())
+			Expect(expiry).To(BeTemporally("~", time.Now().Add(time.Hour), time.Second))
+
+			_, ok = c.Expiry(ctx, "token-0")
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("#Lookup", func() {
+		It("returns the cached token", func() {
+			e, ok := c.Lookup(ctx)
+			Expect(ok).To(BeTrue())
+			Expect(e.Token).To(Equal("token-1"))
+			Expect(e.Expiry).To(BeTemporally("~", time.Now().Add(time.Hour), time.Second))
+			Expect(e.FreshUntil).To(Equal(e.Expiry))
+
+			_, ok = c.Lookup(context.WithValue(ctx, provider.ProviderKey, "vault-k8s-dv-one"))
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("#Expiries", func() {
+		It("returns the expiry of each cached token", func() {
+			_, _ = c.Token(context.WithValue(ctx, provider.ProviderKey, "vault-k8s-dv-one"))
+
+			expiries := c.Expiries()
+			Expect(expiries).To(HaveLen(2))
+			Expect(expiries[""]).To(BeTemporally("~", time.Now().Add(time.Hour), time.Second))
+			Expect(expiries["vault-k8s-dv-one"]).To(BeTemporally("~", time.Now().Add(time.Hour), time.Second))
+
+			c.Invalidate(ctx)
+			Expect(c.Expiries()).To(HaveKey("vault-k8s-dv-one"))
+			Expect(c.Expiries()).ToNot(HaveKey(""))
+		})
+
+		When("the refresh fails", func() {
+			BeforeEach(func() {
+				policy.ErrorTTL = time.Minute
+			})
+
+			It("omits the cached error", func() {
+				fetchErr = errors.New("upstream error")
+				_, err = c.Token(provider.WithNoCache(ctx))
+				Expect(err).To(HaveOccurred())
+				Expect(c.Expiries()).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("#Changed", func() {
+		It("is closed once the cached token is replaced", func() {
+			changed := c.Changed(ctx)
+			other := c.Changed(context.WithValue(ctx, provider.ProviderKey, "vault-k8s-dv-one"))
+
+			_, _ = c.Token(ctx)
+			Consistently(changed).ShouldNot(BeClosed())
+
+			_, _ = c.Token(provider.WithNoCache(ctx))
+			Expect(changed).To(BeClosed())
+			Expect(other).ToNot(BeClosed())
+		})
+
+		It("is closed once the cache is cleared", func() {
+			changed := c.Changed(ctx)
+
+			c.Clear(ctx)
+			Expect(changed).To(BeClosed())
+		})
+	})
+
+	Describe("#Reject", func() {
+		It("removes the cached token only if it matches", func() {
+			Expect(c.Reject(ctx, func(t string) bool { return t == "token-0" })).To(BeFalse())
+			token, _ = c.Token(ctx)
+			Expect(token).To(Equal("token-1"))
+
+			Expect(c.Reject(ctx, func(t string) bool { return t == "token-1" })).To(BeTrue())
+			token, _ = c.Token(ctx)
+			Expect(token).To(Equal("token-2"))
+		})
+	})
+
+	Describe("#Revoke", func() {
+		It("revokes tokens if the fetcher supports it and clears the cache", func() {
+			Expect(c.Revoke(ctx)).To(Succeed())
+			token, _ = c.Token(ctx)
+			Expect(token).To(Equal("token-1"))
+
+			r := &fakeRevoker{FetcherFunc: fetcher}
+			c = cache.New(r, policy)
+			_, _ = c.Token(ctx)
+			Expect(c.Revoke(ctx)).To(Succeed())
+			Expect(r.revoked).To(BeTrue())
+
+			token, _ = c.Token(ctx)
+			Expect(token).To(Equal("token-3"))
+		})
+	})
+})
@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"context"
+	"crypto/cipher"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileStore is a Store persisting entries to a file encrypted with
+// AES-GCM. Entries are held in memory and the whole file is rewritten
+// whenever they change. Entries past their freshness are evicted.
+type FileStore struct {
+	aead    cipher.AEAD
+	path    string
+	mux     sync.Mutex
+	entries map[string]Entry
+}
+
+// NewFileStore returns a FileStore persisting entries to the file at path,
+// encrypted with a key derived from secret. Still fresh entries are loaded
+// from the file if it exists. A file that cannot be read, such as one
+// encrypted with another key, is removed and the store starts empty, as
+// its tokens can be fetched again.
+func NewFileStore(path string, secret []byte) (*FileStore, error) {
+	aead, err := newAEAD(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &FileStore{
+		aead:    aead,
+		path:    path,
+		entries: map[string]Entry{},
+	}
+
+	if err := s.load(); err != nil {
+		slog.Warn("discarding unreadable cache file", slog.String("path", path), slog.Any("error", err))
+
+		s.entries = map[string]Entry{}
+
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("error removing cache file", slog.String("path", path), slog.Any("error", err))
+		}
+	}
+
+	s.evict(time.Now())
+
+	return s, nil
+}
+
+// load reads the entries in the file, if it exists.
+func (s *FileStore) load() error {
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("error reading cache file: %w", err)
+	}
+
+	plaintext, err := open(s.aead, b)
+	if err != nil {
+		return fmt.Errorf("error decrypting cache file: %w", err)
+	}
+
+	if err := json.Unmarshal(plaintext, &s.entries); err != nil {
+		return fmt.Errorf("error decoding cache file: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) Get(_ context.Context, key string) (Entry, bool, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	e, ok := s.entries[key]
+	if ok && !time.Now().Before(e.FreshUntil) {
+		return Entry{}, false, nil
+	}
+
+	return e, ok, nil
+}
+
+func (s *FileStore) Set(_ context.Context, key string, e Entry) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.entries[key] = e
+
+	return s.save()
+}
+
+func (s *FileStore) Delete(_ context.Context, key string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if _, ok := s.entries[key]; !ok {
+		return nil
+	}
+
+	delete(s.entries, key)
+
+	return s.save()
+}
+
+// evict removes entries that are no longer fresh at now.
+func (s *FileStore) evict(now time.Time) {
+	for key, e := range s.entries {
+		if !now.Before(e.FreshUntil) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// save atomically replaces the file with the current entries.
+func (s *FileStore) save() error {
+	s.evict(time.Now())
+
+	plaintext, err := json.Marshal(s.entries)
+	if err != nil {
+		return err
+	}
+
+	ciphertext, err := seal(s.aead, plaintext)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("error writing cache file: %w", err)
+	}
+
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(ciphertext); err != nil {
+		_ = f.Close()
+
+		return fmt.Errorf("error writing cache file: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing cache file: %w", err)
+	}
+
+	if err := os.Rename(f.Name(), s.path); err != nil {
+		return fmt.Errorf("error writing cache file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package cache_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/homedepot/arcade/internal/cache"
+	"github.com/homedepot/arcade/pkg/provider"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileStore", func() {
+	var (
+		dir    string
+		path   string
+		secret []byte
+		store  *cache.FileStore
+		err    error
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		dir, err = os.MkdirTemp("", "arcade-cache")
+		Expect(err).ToNot(HaveOccurred())
+		path = filepath.Join(dir, "tokens")
+		secret = []byte("test-secret")
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	JustBeforeEach(func() {
+		store, err = cache.NewFileStore(path, secret)
+	})
+
+	When("the key is empty", func() {
+		BeforeEach(func() {
+			secret = nil
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError("cache encryption key is empty"))
+		})
+	})
+
+	When("the file does not exist", func() {
+		It("starts empty", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			_, ok, err := store.Get(ctx, "google/")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	When("entries have been stored", func() {
+		JustBeforeEach(func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(store.Set(ctx, "rancher/", cache.Entry{Token: "fresh-token", FreshUntil: time.Now().Add(time.Hour)})).To(Succeed())
+			Expect(store.Set(ctx, "vault/cluster", cache.Entry{Token: "stale-token", FreshUntil: time.Now().Add(50 * time.Millisecond)})).To(Succeed())
+		})
+
+		It("encrypts the file", func() {
+			b, err := os.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(b)).ToNot(ContainSubstring("fresh-token"))
+
+			info, err := os.Stat(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+		})
+
+		It("reloads fresh entries and evicts stale ones", func() {
+			time.Sleep(60 * time.Millisecond)
+
+			reloaded, err := cache.NewFileStore(path, secret)
+			Expect(err).ToNot(HaveOccurred())
+
+			e, ok, err := reloaded.Get(ctx, "rancher/")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(e.Token).To(Equal("fresh-token"))
+
+			_, ok, _ = reloaded.Get(ctx, "vault/cluster")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("deletes entries", func() {
+			Expect(store.Delete(ctx, "rancher/")).To(Succeed())
+
+			reloaded, err := cache.NewFileStore(path, secret)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, ok, _ := reloaded.Get(ctx, "rancher/")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("discards the file when read with another key", func() {
+			reloaded, err := cache.NewFileStore(path, []byte("other-secret"))
+			Expect(err).ToNot(HaveOccurred())
+
+			_, ok, _ := reloaded.Get(ctx, "rancher/")
+			Expect(ok).To(BeFalse())
+
+			_, err = os.Stat(path)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
+
+	When("the file is corrupt", func() {
+		BeforeEach(func() {
+			Expect(os.WriteFile(path, []byte("not encrypted"), 0600)).To(Succeed())
+		})
+
+		It("removes it and starts empty", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			_, ok, err := store.Get(ctx, "google/")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+
+			_, err = os.Stat(path)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		It("stores new entries", func() {
+			Expect(store.Set(ctx, "google/", cache.Entry{Token: "token", FreshUntil: time.Now().Add(time.Hour)})).To(Succeed())
+
+			reloaded, err := cache.NewFileStore(path, secret)
+			Expect(err).ToNot(HaveOccurred())
+
+			e, ok, _ := reloaded.Get(ctx, "google/")
+			Expect(ok).To(BeTrue())
+			Expect(e.Token).To(Equal("token"))
+		})
+	})
+
+	Describe("a Cache using the store", func() {
+		var fetches int
+
+		fetcher := provider.FetcherFunc(func(context.Context) (provider.Token, error) {
+			fetches++
+
+			return provider.Token{AccessToken: "stored-token", Expiry: time.Now().Add(time.Hour)}, nil
+		})
+
+		BeforeEach(func() {
+			fetches = 0
+		})
+
+		It("reuses tokens across restarts", func() {
+			c := cache.New(fetcher, cache.Policy{})
+			c.WithStore(store, "config-hash")
+
+			t, err := c.Token(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(t).To(Equal("stored-token"))
+
+			restarted, err := cache.NewFileStore(path, secret)
+			Expect(err).ToNot(HaveOccurred())
+
+			c = cache.New(fetcher, cache.Policy{})
+			c.WithStore(restarted, "config-hash")
+
+			t, err = c.Token(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(t).To(Equal("stored-token"))
+			Expect(fetches).To(Equal(1))
+
+			By("not sharing tokens between provider configurations")
+			c = cache.New(fetcher, cache.Policy{})
+			c.WithStore(restarted, "other-config-hash")
+
+			_, _ = c.Token(ctx)
+			Expect(fetches).To(Equal(2))
+		})
+	})
+})
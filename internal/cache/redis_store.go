@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const redisKeyPrefix = "arcade:token:"
+
+// unlockScript deletes a lock only if it is still held by the caller.
+var unlockScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0
+`)
+
+// RedisStore is a Store sharing entries between processes through Redis.
+// Entries are encrypted with AES-GCM and expire from Redis once they are
+// due for refresh.
+type RedisStore struct {
+	aead   cipher.AEAD
+	client redis.UniversalClient
+}
+
+// NewRedisStore returns a RedisStore using client, encrypting entries with
+// a key derived from secret.
+func NewRedisStore(client redis.UniversalClient, secret []byte) (*RedisStore, error) {
+	aead, err := newAEAD(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RedisStore{
+		aead:   aead,
+		client: client,
+	}, nil
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) (Entry, bool, error) {
+	b, err := s.client.Get(ctx, redisKeyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return Entry{}, false, nil
+	}
+
+	if err != nil {
+		return Entry{}, false, err
+	}
+
+	plaintext, err := open(s.aead, b)
+	if err != nil {
+		return Entry{}, false, fmt.Errorf("error decrypting cached token: %w", err)
+	}
+
+	var e Entry
+	if err := json.Unmarshal(plaintext, &e); err != nil {
+		return Entry{}, false, fmt.Errorf("error decoding cached token: %w", err)
+	}
+
+	return e, true, nil
+}
+
+func (s *RedisStore) Set(ctx context.Context, key string, e Entry) error {
+	ttl := time.Until(e.FreshUntil)
+	if ttl <= 0 {
+		return nil
+	}
+
+	plaintext, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	ciphertext, err := seal(s.aead, plaintext)
+	if err != nil {
+		return err
+	}
+
+	return s.client.Set(ctx, redisKeyPrefix+key, ciphertext, ttl).Err()
+}
+
+func (s *RedisStore) Delete(ctx context.Context, key string) error {
+	return s.client.Del(ctx, redisKeyPrefix+key).Err()
+}
+
+// Lock acquires a lock on key that expires after ttl, reporting whether
+// it was acquired. The returned function releases the lock.
+func (s *RedisStore) Lock(ctx context.Context, key string, ttl time.Duration) (func(), bool, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, false, err
+	}
+
+	lockKey := redisKeyPrefix + key + ":lock"
+	value := hex.EncodeToString(b)
+
+	ok, err := s.client.SetNX(ctx, lockKey, value, ttl).Result()
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	unlock := func() {
+		// Release the lock even if the caller has given up.
+		_ = unlockScript.Run(context.WithoutCancel(ctx), s.client, []string{lockKey}, value).Err()
+	}
+
+	return unlock, true, nil
+}
@@ -0,0 +1,142 @@
+package cache_test
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/homedepot/arcade/internal/cache"
+	"github.com/homedepot/arcade/pkg/provider"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/redis/go-redis/v9"
+)
+
+var _ = Describe("RedisStore", func() {
+	var (
+		mr     *miniredis.Miniredis
+		client *redis.Client
+		store  *cache.RedisStore
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		mr, err = miniredis.Run()
+		Expect(err).ToNot(HaveOccurred())
+		client = redis.NewClient(&redis.Options{
+			Addr:        mr.Addr(),
+			DialTimeout: 100 * time.Millisecond,
+			MaxRetries:  -1,
+		})
+		store, err = cache.NewRedisStore(client, []byte("test-secret"))
+		Expect(err).ToNot(HaveOccurred())
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		client.Close()
+		mr.Close()
+	})
+
+	It("stores encrypted entries until they are due for refresh", func() {
+		Expect(store.Set(ctx, "rancher/", cache.Entry{Token: "shared-token", FreshUntil: time.Now().Add(time.Minute)})).To(Succeed())
+
+		e, ok, err := store.Get(ctx, "rancher/")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(e.Token).To(Equal("shared-token"))
+
+		raw, err := mr.Get("arcade:token:rancher/")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(raw).ToNot(ContainSubstring("shared-token"))
+		Expect(mr.TTL("arcade:token:rancher/")).To(BeNumerically("~", time.Minute, time.Second))
+
+		Expect(store.Delete(ctx, "rancher/")).To(Succeed())
+		_, ok, err = store.Get(ctx, "rancher/")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+	})
+
+	It("locks keys", func() {
+		unlock, ok, err := store.Lock(ctx, "rancher/", time.Minute)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+
+		_, ok, err = store.Lock(ctx, "rancher/", time.Minute)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+
+		unlock()
+
+		_, ok, err = store.Lock(ctx, "rancher/", time.Minute)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+	})
+
+	Describe("Caches sharing the store", func() {
+		var (
+			fetches int32
+			release chan struct{}
+			fetcher provider.FetcherFunc
+		)
+
+		BeforeEach(func() {
+			fetches = 0
+			release = make(chan struct{})
+			fetcher = func(context.Context) (provider.Token, error) {
+				atomic.AddInt32(&fetches, 1)
+				<-release
+
+				return provider.Token{AccessToken: "shared-token", Expiry: time.Now().Add(time.Hour)}, nil
+			}
+		})
+
+		It("refresh a token only once between them", func() {
+			replicas := []*cache.Cache{cache.New(fetcher, cache.Policy{}), cache.New(fetcher, cache.Policy{})}
+			tokens := make(chan string, len(replicas))
+
+			for _, c := range replicas {
+				c.WithStore(store, "config-hash")
+
+				go func(c *cache.Cache) {
+					defer GinkgoRecover()
+
+					t, err := c.Token(ctx)
+					Expect(err).ToNot(HaveOccurred())
+					tokens <- t
+				}(c)
+			}
+
+			Eventually(func() int32 { return atomic.LoadInt32(&fetches) }).Should(Equal(int32(1)))
+			close(release)
+
+			Eventually(tokens).Should(Receive(Equal("shared-token")))
+			Eventually(tokens).Should(Receive(Equal("shared-token")))
+			Expect(atomic.LoadInt32(&fetches)).To(Equal(int32(1)))
+		})
+
+		When("the store is unavailable", func() {
+			BeforeEach(func() {
+				close(release)
+				mr.Close()
+			})
+
+			It("falls back to caching in memory", func() {
+				c := cache.New(fetcher, cache.Policy{})
+				c.WithStore(store, "config-hash")
+
+				t, err := c.Token(ctx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(t).To(Equal("shared-token"))
+
+				t, err = c.Token(ctx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(t).To(Equal("shared-token"))
+				Expect(atomic.LoadInt32(&fetches)).To(Equal(int32(1)))
+			})
+		})
+	})
+})
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"time"
+)
+
+// Entry is a token held by a Store.
+type Entry struct {
+	Token string `json:"token"`
+	// Expiry is when the token expires, zero if unknown.
+	Expiry time.Time `json:"expiry"`
+	// FreshUntil is when the token should be refreshed.
+	FreshUntil time.Time `json:"freshUntil"`
+}
+
+// Store holds cached tokens outside of the process, so that they survive
+// restarts or can be shared.
+type Store interface {
+	// Get returns the entry stored under key, and whether there is one.
+	Get(ctx context.Context, key string) (Entry, bool, error)
+	Set(ctx context.Context, key string, e Entry) error
+	Delete(ctx context.Context, key string) error
+}
+
+// Locker is implemented by Stores shared between processes, so that a
+// single process at a time refreshes a token.
+type Locker interface {
+	// Lock acquires a lock on key that expires after ttl, reporting
+	// whether it was acquired. The returned function releases the lock.
+	Lock(ctx context.Context, key string, ttl time.Duration) (func(), bool, error)
+}
+
+// newAEAD returns an AES-256-GCM cipher whose key is derived from secret.
+func newAEAD(secret []byte) (cipher.AEAD, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("cache encryption key is empty")
+	}
+
+	key := sha256.Sum256(secret)
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext, prefixing it with a random nonce.
+func seal(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// open decrypts ciphertext produced by seal.
+func open(aead cipher.AEAD, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
@@ -0,0 +1,258 @@
+// Package cli implements the arcade command line client, which requests
+// tokens from an arcade server.
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	arcade "github.com/homedepot/arcade/pkg"
+)
+
+// Environment variables configuring the client.
+const (
+	EnvURL    = "ARCADE_URL"
+	EnvAPIKey = "ARCADE_API_KEY"
+)
+
+// ErrUsage is returned when a command is run with invalid arguments.
+var ErrUsage = errors.New("invalid usage")
+
+type command struct {
+	usage string
+	run   func(*CLI, context.Context, []string) error
+}
+
+var commands = map[string]command{
+	"token": {
+		usage: "print a token of a provider",
+		run:   (*CLI).token,
+	},
+	"providers": {
+		usage: "list the providers arcade serves tokens for",
+		run:   (*CLI).providers,
+	},
+	"kubeconfig": {
+		usage: "print a kubeconfig authenticating to a cluster with arcade",
+		run:   (*CLI).kubeconfig,
+	},
+	"exec-credential": {
+		usage: "print a token of a provider as a kubectl ExecCredential",
+		run:   (*CLI).execCredential,
+	},
+	"docker-credential": {
+		usage: "act as a Docker credential helper, as when run as " + DockerCredentialHelper,
+		run:   (*CLI).dockerCredential,
+	},
+	"git-credential": {
+		usage: "act as a git credential helper, as when run as " + GitCredentialHelper,
+		run:   (*CLI).gitCredential,
+	},
+}
+
+// IsCommand reports whether name is a command of the CLI, rather than an
+// argument of the arcade server.
+func IsCommand(name string) bool {
+	_, ok := commands[name]
+
+	return ok || name == "help"
+}
+
+// CLI runs commands of the arcade command line client.
+type CLI struct {
+	client arcade.Client
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+}
+
+// New returns a CLI requesting tokens with c, reading input from stdin and
+// writing output to stdout and usage to stderr. Environment variables are
+// looked up with getenv.
+func New(c arcade.Client, stdin io.Reader, stdout, stderr io.Writer, getenv func(string) string) *CLI {
+	return &CLI{
+		client: c,
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+		getenv: getenv,
+	}
+}
+
+// NewClient returns a client for the arcade server at the URL in
+// ARCADE_URL, or the default URL, authenticated with the API key in
+// ARCADE_API_KEY. Environment variables are looked up with getenv.
+func NewClient(getenv func(string) string) arcade.Client {
+	opts := []arcade.Option{arcade.WithRetries(2, arcade.DefaultBackoff)}
+
+	if url := getenv(EnvURL); url != "" {
+		return arcade.NewClient(url, getenv(EnvAPIKey), opts...)
+	}
+
+	return arcade.NewDefaultClient(getenv(EnvAPIKey), opts...)
+}
+
+// Run runs the command named by the first argument with the remaining
+// arguments.
+func (c *CLI) Run(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] == "help" {
+		c.usage()
+
+		return nil
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		c.usage()
+
+		return fmt.Errorf("%w: unknown command %q", ErrUsage, args[0])
+	}
+
+	return cmd.run(c, ctx, args[1:])
+}
+
+func (c *CLI) usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var b strings.Builder
+
+	b.WriteString("Usage: arcade <command> [flags]\n\nCommands:\n")
+
+	for _, name := range names {
+		fmt.Fprintf(&b, "  %-16s %s\n", name, commands[name].usage)
+	}
+
+	fmt.Fprintf(&b, "\nThe client connects to %s, authenticated with %s.\n", EnvURL, EnvAPIKey)
+	fmt.Fprint(&b, "Run 'arcade <command> -h' for the flags of a command.\n")
+
+	_, _ = io.WriteString(c.stderr, b.String())
+}
+
+// flagSet returns a FlagSet for the named command, writing its usage to
+// stderr.
+func (c *CLI) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("arcade "+name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+
+	return fs
+}
+
+// parse parses the flags of a command, which takes no positional
+// arguments.
+func parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+
+		return fmt.Errorf("%w: %s", ErrUsage, err)
+	}
+
+	if fs.NArg() > 0 {
+		return fmt.Errorf("%w: unexpected argument %q", ErrUsage, fs.Arg(0))
+	}
+
+	return nil
+}
+
+// readConfig reads the JSON configuration file in the environment variable
+// env, or the named file in the arcade directory of the user's
+// configuration directory, into v.
+func (c *CLI) readConfig(env, name string, v any) error {
+	path := c.getenv(env)
+	if path == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return err
+		}
+
+		path = filepath.Join(dir, "arcade", name)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading configuration: %w", err)
+	}
+
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("error parsing configuration %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *CLI) token(ctx context.Context, args []string) error {
+	fs := c.flagSet("token")
+	tokenProvider := fs.String("provider", "", "name of the token provider (required)")
+	noCache := fs.Bool("no-cache", false, "request a new token rather than a cached one")
+
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if *tokenProvider == "" {
+		return fmt.Errorf("%w: -provider is required", ErrUsage)
+	}
+
+	var (
+		t   string
+		err error
+	)
+
+	if *noCache {
+		t, err = c.client.TokenNoCacheContext(ctx, *tokenProvider)
+	} else {
+		t, err = c.client.TokenContext(ctx, *tokenProvider)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(c.stdout, t)
+
+	return err
+}
+
+func (c *CLI) providers(ctx context.Context, args []string) error {
+	fs := c.flagSet("providers")
+	asJSON := fs.Bool("json", false, "print the details of every provider as JSON")
+
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	providers, err := c.client.ProvidersContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(providers)
+	}
+
+	for _, p := range providers {
+		if _, err := fmt.Fprintln(c.stdout, p.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/homedepot/arcade/internal/cli"
+	arcade "github.com/homedepot/arcade/pkg"
+	"github.com/homedepot/arcade/pkg/arcadefakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CLI", func() {
+	var (
+		fakeClient *arcadefakes.FakeClient
+		stdin      *strings.Reader
+		stdout     *bytes.Buffer
+		stderr     *bytes.Buffer
+		env        map[string]string
+		args       []string
+		err        error
+	)
+
+	BeforeEach(func() {
+		fakeClient = &arcadefakes.FakeClient{}
+		stdin = strings.NewReader("")
+		stdout = &bytes.Buffer{}
+		stderr = &bytes.Buffer{}
+		env = map[string]string{}
+	})
+
+	JustBeforeEach(func() {
+		getenv := func(key string) string { return env[key] }
+		err = cli.New(fakeClient, stdin, stdout, stderr, getenv).Run(context.Background(), args)
+	})
+
+	Describe("#IsCommand", func() {
+		It("reports whether the argument is a command", func() {
+			Expect(cli.IsCommand("token")).To(BeTrue())
+			Expect(cli.IsCommand("help")).To(BeTrue())
+			Expect(cli.IsCommand("-listen-address")).To(BeFalse())
+		})
+	})
+
+	When("no command is given", func() {
+		BeforeEach(func() {
+			args = nil
+		})
+
+		It("prints the usage", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stderr.String()).To(ContainSubstring("exec-credential"))
+		})
+	})
+
+	When("the command is unknown", func() {
+		BeforeEach(func() {
+			args = []string{"fake"}
+		})
+
+		It("returns a usage error", func() {
+			Expect(errors.Is(err, cli.ErrUsage)).To(BeTrue())
+		})
+	})
+
+	Describe("token", func() {
+		BeforeEach(func() {
+			args = []string{"token", "-provider", "google"}
+			fakeClient.TokenContextReturns("some.bearer.token", nil)
+			fakeClient.TokenNoCacheContextReturns("new.bearer.token", nil)
+		})
+
+		When("no provider is given", func() {
+			BeforeEach(func() {
+				args = []string{"token"}
+			})
+
+			It("returns a usage error", func() {
+				Expect(errors.Is(err, cli.ErrUsage)).To(BeTrue())
+			})
+		})
+
+		When("getting the token fails", func() {
+			BeforeEach(func() {
+				fakeClient.TokenContextReturns("", errors.New("error getting token"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError("error getting token"))
+				Expect(stdout.String()).To(BeEmpty())
+			})
+		})
+
+		When("a new token is requested", func() {
+			BeforeEach(func() {
+				args = append(args, "-no-cache")
+			})
+
+			It("bypasses the cache", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stdout.String()).To(Equal("new.bearer.token\n"))
+				_, p := fakeClient.TokenNoCacheContextArgsForCall(0)
+				Expect(p).To(Equal("google"))
+			})
+		})
+
+		It("prints the token", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stdout.String()).To(Equal("some.bearer.token\n"))
+			_, p := fakeClient.TokenContextArgsForCall(0)
+			Expect(p).To(Equal("google"))
+		})
+	})
+
+	Describe("providers", func() {
+		BeforeEach(func() {
+			args = []string{"providers"}
+			fakeClient.ProvidersContextReturns([]arcade.Provider{
+				{Name: "google", Type: "google"},
+				{Name: "vault-k8s-dv", Type: "vault-k8s", Parameters: []string{"cluster"}},
+			}, nil)
+		})
+
+		When("listing providers fails", func() {
+			BeforeEach(func() {
+				fakeClient.ProvidersContextReturns(nil, errors.New("error listing providers"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError("error listing providers"))
+			})
+		})
+
+		When("details are requested", func() {
+			BeforeEach(func() {
+				args = append(args, "-json")
+			})
+
+			It("prints them as JSON", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stdout.String()).To(ContainSubstring(`"parameters": [
+      "cluster"
+    ]`))
+			})
+		})
+
+		It("prints a provider per line", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stdout.String()).To(Equal("google\nvault-k8s-dv\n"))
+		})
+	})
+})
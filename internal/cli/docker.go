@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/homedepot/arcade/internal/credentials"
+)
+
+const (
+	// DockerCredentialHelper is the name the arcade binary is run as by
+	// Docker when configured as a credential helper.
+	DockerCredentialHelper = "docker-credential-arcade"
+	// EnvDockerConfig is the path of the file mapping registries to
+	// providers, which defaults to arcade/docker.json in the user's
+	// configuration directory.
+	EnvDockerConfig = "ARCADE_DOCKER_CONFIG"
+)
+
+// errCredentialsNotFound is the error Docker expects from a credential
+// helper with no credentials for a registry.
+var errCredentialsNotFound = errors.New("credentials not found in native keychain")
+
+// dockerConfig maps registry hostnames, such as gcr.io or *.azurecr.io,
+// to the provider of their tokens.
+type dockerConfig struct {
+	Registries map[string]registry `json:"registries"`
+}
+
+type registry struct {
+	Provider string `json:"provider"`
+	// Username defaults to the username expected by the registries of
+	// Google and Microsoft.
+	Username string `json:"username,omitempty"`
+}
+
+type dockerCredentials struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+// dockerCredential implements the Docker credential helper protocol. Only
+// the get and list actions are supported, as credentials are managed by
+// arcade. As Docker expects, errors are also written to stdout.
+func (c *CLI) dockerCredential(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: expected one of get, list, store or erase", ErrUsage)
+	}
+
+	var err error
+
+	switch args[0] {
+	case "get":
+		err = c.dockerGet(ctx)
+	case "list":
+		err = c.dockerList()
+	case "store", "erase":
+		_, _ = io.Copy(io.Discard, c.stdin)
+		err = fmt.Errorf("%s is not supported, credentials are managed by arcade", args[0])
+	default:
+		return fmt.Errorf("%w: unknown action %q", ErrUsage, args[0])
+	}
+
+	if err != nil {
+		fmt.Fprintln(c.stdout, err)
+	}
+
+	return err
+}
+
+func (c *CLI) dockerGet(ctx context.Context) error {
+	b, err := io.ReadAll(c.stdin)
+	if err != nil {
+		return err
+	}
+
+	serverURL := strings.TrimSpace(string(b))
+
+	cfg, err := c.dockerConfig()
+	if err != nil {
+		return err
+	}
+
+	host := registryHost(serverURL)
+
+	r, ok := cfg.lookup(host)
+	if !ok {
+		return errCredentialsNotFound
+	}
+
+	username := r.Username
+	if username == "" {
+		username = credentials.DefaultRegistryUsername(host)
+	}
+
+	if username == "" {
+		return fmt.Errorf("no username configured for registry %s", host)
+	}
+
+	t, err := c.client.TokenContext(ctx, r.Provider)
+	if err != nil {
+		return err
+	}
+
+	return json.NewEncoder(c.stdout).Encode(dockerCredentials{
+		ServerURL: serverURL,
+		Username:  username,
+		Secret:    t,
+	})
+}
+
+// dockerList lists the username of every registry configured by hostname,
+// rather than by pattern.
+func (c *CLI) dockerList() error {
+	cfg, err := c.dockerConfig()
+	if err != nil {
+		return err
+	}
+
+	list := map[string]string{}
+
+	for host, r := range cfg.Registries {
+		if strings.HasPrefix(host, "*.") {
+			continue
+		}
+
+		list[host] = r.Username
+		if list[host] == "" {
+			list[host] = credentials.DefaultRegistryUsername(host)
+		}
+	}
+
+	return json.NewEncoder(c.stdout).Encode(list)
+}
+
+// dockerConfig reads the file mapping registries to providers.
+func (c *CLI) dockerConfig() (dockerConfig, error) {
+	var cfg dockerConfig
+
+	err := c.readConfig(EnvDockerConfig, "docker.json", &cfg)
+
+	return cfg, err
+}
+
+// lookup returns the registry configured for host, either by hostname or
+// by the longest matching pattern such as *.azurecr.io.
+func (cfg dockerConfig) lookup(host string) (registry, bool) {
+	if r, ok := cfg.Registries[host]; ok {
+		return r, true
+	}
+
+	var (
+		match string
+		found registry
+	)
+
+	for pattern, r := range cfg.Registries {
+		suffix, ok := strings.CutPrefix(pattern, "*")
+		if ok && strings.HasSuffix(host, suffix) && len(suffix) > len(match) {
+			match, found = suffix, r
+		}
+	}
+
+	return found, match != ""
+}
+
+// registryHost returns the hostname of a registry given by Docker, which
+// may be a URL or a hostname followed by a path.
+func registryHost(serverURL string) string {
+	if strings.Contains(serverURL, "://") {
+		if u, err := url.Parse(serverURL); err == nil {
+			return strings.ToLower(u.Host)
+		}
+	}
+
+	host, _, _ := strings.Cut(serverURL, "/")
+
+	return strings.ToLower(host)
+}
@@ -0,0 +1,200 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/homedepot/arcade/internal/cli"
+	"github.com/homedepot/arcade/pkg/arcadefakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Docker", func() {
+	var (
+		fakeClient *arcadefakes.FakeClient
+		stdin      string
+		stdout     *bytes.Buffer
+		env        map[string]string
+		args       []string
+		err        error
+		dir        string
+	)
+
+	BeforeEach(func() {
+		fakeClient = &arcadefakes.FakeClient{}
+		fakeClient.TokenContextReturns("some.bearer.token", nil)
+		stdout = &bytes.Buffer{}
+
+		dir, err = os.MkdirTemp("", "arcade-cli")
+		Expect(err).ToNot(HaveOccurred())
+
+		path := filepath.Join(dir, "docker.json")
+		Expect(os.WriteFile(path, []byte(`{
+			"registries": {
+				"gcr.io": {"provider": "google"},
+				"*.pkg.dev": {"provider": "google"},
+				"*.azurecr.io": {"provider": "microsoft"},
+				"team.azurecr.io": {"provider": "microsoft-team"},
+				"registry.example.com": {"provider": "vault-k8s-dv", "username": "robot"},
+				"other.example.com": {"provider": "rancher"}
+			}
+		}`), 0600)).To(Succeed())
+
+		env = map[string]string{cli.EnvDockerConfig: path}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	JustBeforeEach(func() {
+		getenv := func(key string) string { return env[key] }
+		c := cli.New(fakeClient, strings.NewReader(stdin), stdout, &bytes.Buffer{}, getenv)
+		err = c.Run(context.Background(), append([]string{"docker-credential"}, args...))
+	})
+
+	Describe("get", func() {
+		BeforeEach(func() {
+			args = []string{"get"}
+			stdin = "https://gcr.io\n"
+		})
+
+		When("the registry is not configured", func() {
+			BeforeEach(func() {
+				stdin = "index.docker.io"
+			})
+
+			It("reports that there are no credentials", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(stdout.String()).To(Equal("credentials not found in native keychain\n"))
+				Expect(fakeClient.TokenContextCallCount()).To(BeZero())
+			})
+		})
+
+		When("the configuration file does not exist", func() {
+			BeforeEach(func() {
+				env[cli.EnvDockerConfig] = "/does/not/exist.json"
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError(ContainSubstring("error reading configuration")))
+			})
+		})
+
+		When("getting the token fails", func() {
+			BeforeEach(func() {
+				fakeClient.TokenContextReturns("", errors.New("error getting token"))
+			})
+
+			It("writes the error to stdout", func() {
+				Expect(err).To(MatchError("error getting token"))
+				Expect(stdout.String()).To(Equal("error getting token\n"))
+			})
+		})
+
+		When("the registry matches a pattern", func() {
+			BeforeEach(func() {
+				stdin = "us-docker.pkg.dev/project/repo"
+			})
+
+			It("returns its credentials", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stdout.String()).To(MatchJSON(`{"ServerURL":"us-docker.pkg.dev/project/repo","Username":"oauth2accesstoken","Secret":"some.bearer.token"}`))
+			})
+		})
+
+		When("the registry is an Azure container registry", func() {
+			BeforeEach(func() {
+				stdin = "other.azurecr.io"
+			})
+
+			It("returns the Microsoft username", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stdout.String()).To(ContainSubstring(`"Username":"00000000-0000-0000-0000-000000000000"`))
+				_, p := fakeClient.TokenContextArgsForCall(0)
+				Expect(p).To(Equal("microsoft"))
+			})
+		})
+
+		When("the registry matches both a hostname and a pattern", func() {
+			BeforeEach(func() {
+				stdin = "team.azurecr.io"
+			})
+
+			It("uses the hostname", func() {
+				_, p := fakeClient.TokenContextArgsForCall(0)
+				Expect(p).To(Equal("microsoft-team"))
+			})
+		})
+
+		When("the registry has a configured username", func() {
+			BeforeEach(func() {
+				stdin = "https://registry.example.com"
+			})
+
+			It("uses it", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stdout.String()).To(ContainSubstring(`"Username":"robot"`))
+			})
+		})
+
+		When("the registry has no known username", func() {
+			BeforeEach(func() {
+				stdin = "other.example.com"
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError("no username configured for registry other.example.com"))
+			})
+		})
+
+		It("returns the credentials", func() {
+			Expect(err).ToNot(HaveOccurred())
+			_, p := fakeClient.TokenContextArgsForCall(0)
+			Expect(p).To(Equal("google"))
+			Expect(stdout.String()).To(MatchJSON(`{"ServerURL":"https://gcr.io","Username":"oauth2accesstoken","Secret":"some.bearer.token"}`))
+		})
+	})
+
+	Describe("list", func() {
+		BeforeEach(func() {
+			args = []string{"list"}
+		})
+
+		It("lists the configured registries", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stdout.String()).To(MatchJSON(`{
+				"gcr.io": "oauth2accesstoken",
+				"team.azurecr.io": "00000000-0000-0000-0000-000000000000",
+				"registry.example.com": "robot",
+				"other.example.com": ""
+			}`))
+		})
+	})
+
+	Describe("store", func() {
+		BeforeEach(func() {
+			args = []string{"store"}
+			stdin = `{"ServerURL":"gcr.io","Username":"user","Secret":"secret"}`
+		})
+
+		It("is not supported", func() {
+			Expect(err).To(MatchError(ContainSubstring("store is not supported")))
+		})
+	})
+
+	When("the action is unknown", func() {
+		BeforeEach(func() {
+			args = []string{"fake"}
+		})
+
+		It("returns a usage error", func() {
+			Expect(errors.Is(err, cli.ErrUsage)).To(BeTrue())
+		})
+	})
+})
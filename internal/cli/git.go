@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	// GitCredentialHelper is the name the arcade binary is run as by git
+	// when configured as a credential helper.
+	GitCredentialHelper = "git-credential-arcade"
+	// EnvGitConfig is the path of the file mapping git servers to
+	// providers, which defaults to arcade/git.json in the user's
+	// configuration directory.
+	EnvGitConfig = "ARCADE_GIT_CONFIG"
+
+	defaultGitUsername = "arcade"
+)
+
+// gitConfig maps git servers to the provider of their tokens.
+type gitConfig struct {
+	// Credentials are matched in order, the first match being used.
+	Credentials []gitCredential `json:"credentials"`
+}
+
+type gitCredential struct {
+	// Host is a pattern matched against the host of a repository, such as
+	// dev.azure.com or *.example.com.
+	Host string `json:"host"`
+	// Path is a pattern matched against the leading segments of the path
+	// of a repository, such as myorg or myorg/*. Any path matches if
+	// empty. Git only provides the path if credential.useHttpPath is set.
+	Path     string `json:"path,omitempty"`
+	Provider string `json:"provider"`
+	// Username defaults to the username requested by git, if any, or
+	// arcade.
+	Username string `json:"username,omitempty"`
+}
+
+// matches reports whether the credential is configured for a repository.
+func (g gitCredential) matches(host, repoPath string) bool {
+	if ok, _ := path.Match(g.Host, host); !ok {
+		return false
+	}
+
+	if g.Path == "" {
+		return true
+	}
+
+	segments := strings.Split(strings.Trim(repoPath, "/"), "/")
+
+	for i := range segments {
+		if ok, _ := path.Match(g.Path, strings.Join(segments[:i+1], "/")); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// gitCredential implements the git credential helper protocol. Tokens are
+// returned by the get action. The store action is ignored, as credentials
+// are managed by arcade, and the erase action, run by git when a token is
+// rejected, reports the token to arcade as invalid.
+func (c *CLI) gitCredential(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: expected one of get, store or erase", ErrUsage)
+	}
+
+	action := args[0]
+	if action != "get" && action != "store" && action != "erase" {
+		return fmt.Errorf("%w: unknown action %q", ErrUsage, action)
+	}
+
+	attrs, err := readGitAttributes(c.stdin)
+	if err != nil {
+		return err
+	}
+
+	if action == "store" || (attrs["protocol"] != "https" && attrs["protocol"] != "http") {
+		return nil
+	}
+
+	var cfg gitConfig
+
+	if err := c.readConfig(EnvGitConfig, "git.json", &cfg); err != nil {
+		return err
+	}
+
+	var cred *gitCredential
+
+	for i := range cfg.Credentials {
+		if cfg.Credentials[i].matches(attrs["host"], attrs["path"]) {
+			cred = &cfg.Credentials[i]
+
+			break
+		}
+	}
+
+	// Other helpers may provide credentials for repositories that are
+	// not configured.
+	if cred == nil {
+		return nil
+	}
+
+	if action == "erase" {
+		if attrs["password"] == "" {
+			return nil
+		}
+
+		return c.client.ReportInvalidTokenContext(ctx, cred.Provider, attrs["password"])
+	}
+
+	t, expiry, err := c.client.TokenWithExpiryContext(ctx, cred.Provider)
+	if err != nil {
+		return err
+	}
+
+	username := cred.Username
+	if username == "" {
+		username = attrs["username"]
+	}
+
+	if username == "" {
+		username = defaultGitUsername
+	}
+
+	fmt.Fprintf(c.stdout, "username=%s\npassword=%s\n", username, t)
+
+	if !expiry.IsZero() {
+		fmt.Fprintf(c.stdout, "password_expiry_utc=%s\n", strconv.FormatInt(expiry.Unix(), 10))
+	}
+
+	return nil
+}
+
+// readGitAttributes reads the key=value lines written by git to a
+// credential helper, up to a blank line or the end of input.
+func readGitAttributes(r io.Reader) (map[string]string, error) {
+	attrs := map[string]string{}
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+
+		if key, value, ok := strings.Cut(line, "="); ok {
+			attrs[key] = value
+		}
+	}
+
+	return attrs, scanner.Err()
+}
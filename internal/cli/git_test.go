@@ -0,0 +1,166 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/homedepot/arcade/internal/cli"
+	"github.com/homedepot/arcade/pkg/arcadefakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Git", func() {
+	var (
+		fakeClient *arcadefakes.FakeClient
+		stdin      string
+		stdout     *bytes.Buffer
+		env        map[string]string
+		action     string
+		err        error
+		dir        string
+	)
+
+	BeforeEach(func() {
+		fakeClient = &arcadefakes.FakeClient{}
+		fakeClient.TokenWithExpiryContextReturns("some.bearer.token", time.Time{}, nil)
+		stdout = &bytes.Buffer{}
+		action = "get"
+		stdin = "protocol=https\nhost=dev.azure.com\npath=myorg/project/_git/repo\n\n"
+
+		dir, err = os.MkdirTemp("", "arcade-cli")
+		Expect(err).ToNot(HaveOccurred())
+
+		path := filepath.Join(dir, "git.json")
+		Expect(os.WriteFile(path, []byte(`{
+			"credentials": [
+				{"host": "dev.azure.com", "path": "myorg", "provider": "microsoft", "username": "azure"},
+				{"host": "dev.azure.com", "path": "other*/project", "provider": "microsoft-other"},
+				{"host": "*.example.com", "provider": "rancher"}
+			]
+		}`), 0600)).To(Succeed())
+
+		env = map[string]string{cli.EnvGitConfig: path}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	JustBeforeEach(func() {
+		getenv := func(key string) string { return env[key] }
+		c := cli.New(fakeClient, strings.NewReader(stdin), stdout, &bytes.Buffer{}, getenv)
+		err = c.Run(context.Background(), []string{"git-credential", action})
+	})
+
+	Describe("get", func() {
+		When("the repository is not configured", func() {
+			BeforeEach(func() {
+				stdin = "protocol=https\nhost=github.com\n"
+			})
+
+			It("returns no credentials", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stdout.String()).To(BeEmpty())
+				Expect(fakeClient.TokenWithExpiryContextCallCount()).To(BeZero())
+			})
+		})
+
+		When("the protocol is not HTTP", func() {
+			BeforeEach(func() {
+				stdin = "protocol=ssh\nhost=dev.azure.com\n"
+			})
+
+			It("returns no credentials", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stdout.String()).To(BeEmpty())
+			})
+		})
+
+		When("getting the token fails", func() {
+			BeforeEach(func() {
+				fakeClient.TokenWithExpiryContextReturns("", time.Time{}, errors.New("error getting token"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError("error getting token"))
+			})
+		})
+
+		When("the path matches a pattern", func() {
+			BeforeEach(func() {
+				stdin = "protocol=https\nhost=dev.azure.com\npath=otherorg/project/_git/repo\nusername=someone\n"
+			})
+
+			It("uses the username requested by git", func() {
+				Expect(err).ToNot(HaveOccurred())
+				_, p := fakeClient.TokenWithExpiryContextArgsForCall(0)
+				Expect(p).To(Equal("microsoft-other"))
+				Expect(stdout.String()).To(Equal("username=someone\npassword=some.bearer.token\n"))
+			})
+		})
+
+		When("the host matches a pattern", func() {
+			BeforeEach(func() {
+				stdin = "protocol=https\nhost=git.example.com\n"
+				fakeClient.TokenWithExpiryContextReturns("some.bearer.token", time.Unix(1893553445, 0), nil)
+			})
+
+			It("uses the default username and returns the expiry", func() {
+				Expect(err).ToNot(HaveOccurred())
+				_, p := fakeClient.TokenWithExpiryContextArgsForCall(0)
+				Expect(p).To(Equal("rancher"))
+				Expect(stdout.String()).To(Equal("username=arcade\npassword=some.bearer.token\npassword_expiry_utc=1893553445\n"))
+			})
+		})
+
+		It("returns the credentials", func() {
+			Expect(err).ToNot(HaveOccurred())
+			_, p := fakeClient.TokenWithExpiryContextArgsForCall(0)
+			Expect(p).To(Equal("microsoft"))
+			Expect(stdout.String()).To(Equal("username=azure\npassword=some.bearer.token\n"))
+		})
+	})
+
+	Describe("store", func() {
+		BeforeEach(func() {
+			action = "store"
+			stdin += "username=azure\npassword=some.bearer.token\n"
+		})
+
+		It("does nothing", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fakeClient.Invocations()).To(BeEmpty())
+		})
+	})
+
+	Describe("erase", func() {
+		BeforeEach(func() {
+			action = "erase"
+			stdin = "protocol=https\nhost=dev.azure.com\npath=myorg/repo\nusername=azure\npassword=some.bearer.token\n\n"
+		})
+
+		It("reports the token as invalid", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fakeClient.ReportInvalidTokenContextCallCount()).To(Equal(1))
+			_, p, t := fakeClient.ReportInvalidTokenContextArgsForCall(0)
+			Expect(p).To(Equal("microsoft"))
+			Expect(t).To(Equal("some.bearer.token"))
+		})
+	})
+
+	When("the action is unknown", func() {
+		BeforeEach(func() {
+			action = "fake"
+		})
+
+		It("returns a usage error", func() {
+			Expect(errors.Is(err, cli.ErrUsage)).To(BeTrue())
+		})
+	})
+})
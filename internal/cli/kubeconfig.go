@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/homedepot/arcade/internal/credentials"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	execCredentialV1      = "client.authentication.k8s.io/v1"
+	execCredentialV1beta1 = "client.authentication.k8s.io/v1beta1"
+	// envExecInfo is set by kubectl to describe the ExecCredential it
+	// expects from a credential plugin.
+	envExecInfo = "KUBERNETES_EXEC_INFO"
+)
+
+// execCredential is a Kubernetes ExecCredential, the output of a kubectl
+// credential plugin.
+type execCredential struct {
+	APIVersion string               `json:"apiVersion"`
+	Kind       string               `json:"kind"`
+	Status     execCredentialStatus `json:"status"`
+}
+
+type execCredentialStatus struct {
+	Token               string `json:"token"`
+	ExpirationTimestamp string `json:"expirationTimestamp,omitempty"`
+}
+
+func (c *CLI) execCredential(ctx context.Context, args []string) error {
+	fs := c.flagSet("exec-credential")
+	tokenProvider := fs.String("provider", "", "name of the token provider (required)")
+
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if *tokenProvider == "" {
+		return fmt.Errorf("%w: -provider is required", ErrUsage)
+	}
+
+	apiVersion := execCredentialV1
+
+	if info := c.getenv(envExecInfo); info != "" {
+		var requested struct {
+			APIVersion string `json:"apiVersion"`
+		}
+
+		if err := json.Unmarshal([]byte(info), &requested); err != nil {
+			return fmt.Errorf("error parsing %s: %w", envExecInfo, err)
+		}
+
+		switch requested.APIVersion {
+		case execCredentialV1, execCredentialV1beta1:
+			apiVersion = requested.APIVersion
+		default:
+			return fmt.Errorf("unsupported ExecCredential version %q", requested.APIVersion)
+		}
+	}
+
+	t, expiry, err := c.client.TokenWithExpiryContext(ctx, *tokenProvider)
+	if err != nil {
+		return err
+	}
+
+	cred := execCredential{
+		APIVersion: apiVersion,
+		Kind:       "ExecCredential",
+		Status:     execCredentialStatus{Token: t},
+	}
+
+	// expirationTimestamp is set from the token's expiry, and omitted
+	// when arcade does not know it.
+	if !expiry.IsZero() {
+		cred.Status.ExpirationTimestamp = expiry.UTC().Format(time.RFC3339)
+	}
+
+	return json.NewEncoder(c.stdout).Encode(cred)
+}
+
+func (c *CLI) kubeconfig(_ context.Context, args []string) error {
+	fs := c.flagSet("kubeconfig")
+	cluster := fs.String("cluster", "", "name of the cluster (required)")
+	server := fs.String("server", "", "URL of the cluster's API server (required)")
+	tokenProvider := fs.String("provider", "", "name of the token provider, defaults to the cluster name")
+	caFile := fs.String("certificate-authority", "", "file containing the cluster's CA certificates")
+	insecure := fs.Bool("insecure-skip-tls-verify", false, "skip verifying the API server's certificate")
+	command := fs.String("command", "arcade", "path of the arcade binary run by kubectl")
+
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if *cluster == "" || *server == "" {
+		return fmt.Errorf("%w: -cluster and -server are required", ErrUsage)
+	}
+
+	if *tokenProvider == "" {
+		*tokenProvider = *cluster
+	}
+
+	kc := &credentials.KubeconfigCluster{
+		Server:                *server,
+		InsecureSkipTLSVerify: *insecure,
+	}
+
+	if *caFile != "" {
+		b, err := os.ReadFile(*caFile)
+		if err != nil {
+			return fmt.Errorf("error reading certificate authority: %w", err)
+		}
+
+		kc.CertificateAuthorityData = base64.StdEncoding.EncodeToString(b)
+	}
+
+	user := "arcade-" + *cluster
+
+	cfg := credentials.Kubeconfig{
+		APIVersion: "v1",
+		Kind:       "Config",
+		Clusters:   []credentials.KubeconfigEntry{{Name: *cluster, Cluster: kc}},
+		Users: []credentials.KubeconfigEntry{{
+			Name: user,
+			User: &credentials.KubeconfigUser{Exec: &credentials.KubeconfigExec{
+				APIVersion:      execCredentialV1,
+				Command:         *command,
+				Args:            []string{"exec-credential", "-provider", *tokenProvider},
+				InteractiveMode: "Never",
+			}},
+		}},
+		Contexts: []credentials.KubeconfigEntry{{
+			Name:    *cluster,
+			Context: &credentials.KubeconfigContext{Cluster: *cluster, User: user},
+		}},
+		CurrentContext: *cluster,
+	}
+
+	enc := yaml.NewEncoder(c.stdout)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(cfg); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
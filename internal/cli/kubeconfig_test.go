@@ -0,0 +1,169 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/homedepot/arcade/internal/cli"
+	"github.com/homedepot/arcade/pkg/arcadefakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Kubeconfig", func() {
+	var (
+		fakeClient *arcadefakes.FakeClient
+		stdout     *bytes.Buffer
+		env        map[string]string
+		args       []string
+		err        error
+	)
+
+	BeforeEach(func() {
+		fakeClient = &arcadefakes.FakeClient{}
+		stdout = &bytes.Buffer{}
+		env = map[string]string{}
+	})
+
+	JustBeforeEach(func() {
+		getenv := func(key string) string { return env[key] }
+		err = cli.New(fakeClient, nil, stdout, &bytes.Buffer{}, getenv).Run(context.Background(), args)
+	})
+
+	Describe("exec-credential", func() {
+		BeforeEach(func() {
+			args = []string{"exec-credential", "-provider", "rancher"}
+			fakeClient.TokenWithExpiryContextReturns("some.bearer.token", time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC), nil)
+		})
+
+		When("getting the token fails", func() {
+			BeforeEach(func() {
+				fakeClient.TokenWithExpiryContextReturns("", time.Time{}, errors.New("error getting token"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError("error getting token"))
+			})
+		})
+
+		When("the expiry of the token is unknown", func() {
+			BeforeEach(func() {
+				fakeClient.TokenWithExpiryContextReturns("some.bearer.token", time.Time{}, nil)
+			})
+
+			It("prints a credential without expiration", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stdout.String()).To(MatchJSON(`{
+					"apiVersion": "client.authentication.k8s.io/v1",
+					"kind": "ExecCredential",
+					"status": {"token": "some.bearer.token"}
+				}`))
+			})
+		})
+
+		When("kubectl requests v1beta1", func() {
+			BeforeEach(func() {
+				env["KUBERNETES_EXEC_INFO"] = `{"apiVersion":"client.authentication.k8s.io/v1beta1","kind":"ExecCredential"}`
+			})
+
+			It("prints a v1beta1 credential", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stdout.String()).To(ContainSubstring(`"apiVersion":"client.authentication.k8s.io/v1beta1"`))
+			})
+		})
+
+		When("kubectl requests an unsupported version", func() {
+			BeforeEach(func() {
+				env["KUBERNETES_EXEC_INFO"] = `{"apiVersion":"client.authentication.k8s.io/v2"}`
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError(`unsupported ExecCredential version "client.authentication.k8s.io/v2"`))
+			})
+		})
+
+		It("prints the credential", func() {
+			Expect(err).ToNot(HaveOccurred())
+			_, p := fakeClient.TokenWithExpiryContextArgsForCall(0)
+			Expect(p).To(Equal("rancher"))
+			Expect(stdout.String()).To(MatchJSON(`{
+				"apiVersion": "client.authentication.k8s.io/v1",
+				"kind": "ExecCredential",
+				"status": {
+					"token": "some.bearer.token",
+					"expirationTimestamp": "2030-01-02T03:04:05Z"
+				}
+			}`))
+		})
+	})
+
+	Describe("kubeconfig", func() {
+		BeforeEach(func() {
+			args = []string{"kubeconfig", "-cluster", "vault-k8s-dv-cluster", "-server", "https://10.0.0.1"}
+		})
+
+		When("the server is not given", func() {
+			BeforeEach(func() {
+				args = []string{"kubeconfig", "-cluster", "vault-k8s-dv-cluster"}
+			})
+
+			It("returns a usage error", func() {
+				Expect(errors.Is(err, cli.ErrUsage)).To(BeTrue())
+			})
+		})
+
+		When("a certificate authority is given", func() {
+			var dir string
+
+			BeforeEach(func() {
+				var err error
+				dir, err = os.MkdirTemp("", "arcade-cli")
+				Expect(err).ToNot(HaveOccurred())
+
+				path := filepath.Join(dir, "ca.crt")
+				Expect(os.WriteFile(path, []byte("some-ca"), 0600)).To(Succeed())
+				args = append(args, "-certificate-authority", path, "-provider", "rancher")
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(dir)
+			})
+
+			It("embeds it", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stdout.String()).To(ContainSubstring("certificate-authority-data: c29tZS1jYQ==\n"))
+				Expect(stdout.String()).To(ContainSubstring("- rancher\n"))
+			})
+		})
+
+		It("prints a kubeconfig running exec-credential", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stdout.String()).To(MatchYAML(`
+apiVersion: v1
+kind: Config
+clusters:
+- name: vault-k8s-dv-cluster
+  cluster:
+    server: https://10.0.0.1
+users:
+- name: arcade-vault-k8s-dv-cluster
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1
+      command: arcade
+      args: [exec-credential, -provider, vault-k8s-dv-cluster]
+      interactiveMode: Never
+contexts:
+- name: vault-k8s-dv-cluster
+  context:
+    cluster: vault-k8s-dv-cluster
+    user: arcade-vault-k8s-dv-cluster
+current-context: vault-k8s-dv-cluster
+`))
+		})
+	})
+})
@@ -0,0 +1,117 @@
+package coalesce
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/homedepot/arcade/pkg/provider"
+	"golang.org/x/sync/singleflight"
+)
+
+// timeout bounds the shared call, which outlives the requests waiting on
+// it.
+const timeout = 60 * time.Second
+
+// Wrap wraps a token provider client so that concurrent requests for the
+// same token share a single call to the client. Requests are for the same
+// token if they carry the same provider.ProviderKey value, such as the
+// cluster of a Vault K8s provider.
+//
+// The shared call is made with the context of the first request, without
+// its cancellation or provider.Trace, so that a caller giving up does not
+// fail the others. Each caller still stops waiting once its own context is
+// done, and the refresh made by the shared call, if any, is reported to
+// the Trace of each caller waiting on it.
+func Wrap(_ string, c provider.Client) provider.Client {
+	return &client{client: c}
+}
+
+type client struct {
+	client provider.Client
+	group  singleflight.Group
+}
+
+// flight is the result of a shared call.
+type flight struct {
+	token string
+	// refresh is the refresh made by the call, if any.
+	refresh *provider.RefreshInfo
+	// reported is set once the refresh is reported to a caller, so that
+	// others report it as shared.
+	reported atomic.Bool
+}
+
+func (c *client) Token(ctx context.Context) (string, error) {
+	key, _ := ctx.Value(provider.ProviderKey).(string)
+	// Requests bypassing the cache must not share the result of one that
+	// does not.
+	if provider.NoCache(ctx) {
+		key += "\x00no-cache"
+	}
+
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		f := &flight{}
+
+		// The hooks of the first caller must not run once it has
+		// returned, so the refresh is recorded and reported to each
+		// caller instead.
+		ctx := provider.WithTrace(provider.WithoutTrace(context.WithoutCancel(ctx)), &provider.Trace{
+			RefreshDone: func(info provider.RefreshInfo) {
+				f.refresh = &info
+			},
+		})
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		var err error
+
+		f.token, err = c.client.Token(ctx)
+
+		return f, err
+	})
+
+	select {
+	case res := <-ch:
+		f := res.Val.(*flight)
+		f.report(ctx)
+
+		if res.Err != nil {
+			return "", res.Err
+		}
+
+		return f.token, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// report runs the hooks of the Trace in ctx for the refresh made by the
+// shared call, if any.
+func (f *flight) report(ctx context.Context) {
+	if f.refresh == nil {
+		return
+	}
+
+	info := *f.refresh
+	info.Shared = !f.reported.CompareAndSwap(false, true)
+
+	trace := provider.ContextTrace(ctx)
+	if trace == nil {
+		return
+	}
+
+	if trace.RefreshStart != nil {
+		trace.RefreshStart()
+	}
+
+	if trace.RefreshDone != nil {
+		trace.RefreshDone(info)
+	}
+}
+
+// Unwrap returns the wrapped client.
+func (c *client) Unwrap() provider.Client {
+	return c.client
+}
@@ -0,0 +1,231 @@
+package coalesce_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/homedepot/arcade/internal/coalesce"
+	"github.com/homedepot/arcade/pkg/provider"
+	"github.com/homedepot/arcade/pkg/provider/providerfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Coalesce", func() {
+	var (
+		fakeClient *providerfakes.FakeClient
+		client     provider.Client
+		started    chan struct{}
+		release    chan struct{}
+		tokenErr   error
+	)
+
+	withCluster := func(cluster string) context.Context {
+		return context.WithValue(context.Background(), provider.ProviderKey, cluster)
+	}
+
+	BeforeEach(func() {
+		started = make(chan struct{}, 10)
+		release = make(chan struct{})
+		tokenErr = nil
+		fakeClient = &providerfakes.FakeClient{}
+		fakeClient.TokenStub = func(ctx context.Context) (string, error) {
+			started <- struct{}{}
+			<-release
+
+			if tokenErr != nil {
+				return "", tokenErr
+			}
+
+			return ctx.Value(provider.ProviderKey).(string) + "-token", ctx.Err()
+		}
+		client = coalesce.Wrap("vault-k8s-dv", fakeClient)
+	})
+
+	// request requests a token from client n times concurrently, releasing
+	// the wrapped client once every request has been made.
+	request := func(ctx context.Context, n int) ([]string, []error) {
+		var wg sync.WaitGroup
+
+		tokens := make([]string, n)
+		errs := make([]error, n)
+
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				tokens[i], errs[i] = client.Token(ctx)
+			}(i)
+
+			if i == 0 {
+				<-started
+			}
+		}
+
+		// Give the remaining requests time to join the shared call.
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		return tokens, errs
+	}
+
+	When("requests for the same token are concurrent", func() {
+		It("shares a single call", func() {
+			tokens, errs := request(withCluster("vault-k8s-dv-cluster"), 5)
+			Expect(fakeClient.TokenCallCount()).To(Equal(1))
+
+			for i := range tokens {
+				Expect(errs[i]).ToNot(HaveOccurred())
+				Expect(tokens[i]).To(Equal("vault-k8s-dv-cluster-token"))
+			}
+		})
+	})
+
+	When("the shared call fails", func() {
+		BeforeEach(func() {
+			tokenErr = errors.New("error reading kubeconfig from vault")
+		})
+
+		It("returns the error to every caller", func() {
+			_, errs := request(withCluster("vault-k8s-dv-cluster"), 3)
+			Expect(fakeClient.TokenCallCount()).To(Equal(1))
+
+			for _, err := range errs {
+				Expect(err).To(MatchError("error reading kubeconfig from vault"))
+			}
+		})
+	})
+
+	When("requests are for different tokens", func() {
+		It("does not share calls", func() {
+			var wg sync.WaitGroup
+
+			for _, cluster := range []string{"vault-k8s-dv-one", "vault-k8s-dv-two"} {
+				wg.Add(1)
+
+				go func(cluster string) {
+					defer wg.Done()
+
+					t, err := client.Token(withCluster(cluster))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(t).To(Equal(cluster + "-token"))
+				}(cluster)
+				<-started
+			}
+
+			Expect(fakeClient.TokenCallCount()).To(Equal(2))
+			close(release)
+			wg.Wait()
+		})
+	})
+
+	When("the first caller is canceled", func() {
+		It("does not fail the others", func() {
+			ctx, cancel := context.WithCancel(withCluster("vault-k8s-dv-cluster"))
+			errc := make(chan error, 1)
+
+			go func() {
+				_, err := client.Token(ctx)
+				errc <- err
+			}()
+			<-started
+
+			resc := make(chan string, 1)
+
+			go func() {
+				t, _ := client.Token(withCluster("vault-k8s-dv-cluster"))
+				resc <- t
+			}()
+
+			cancel()
+			Expect(<-errc).To(MatchError(context.Canceled))
+
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+			Expect(<-resc).To(Equal("vault-k8s-dv-cluster-token"))
+			Expect(fakeClient.TokenCallCount()).To(Equal(1))
+		})
+	})
+
+	When("the shared call refreshes the token", func() {
+		var (
+			mux     sync.Mutex
+			refresh []provider.RefreshInfo
+		)
+
+		// traced returns a context whose Trace records the refreshes it is
+		// told of.
+		traced := func(ctx context.Context) context.Context {
+			return provider.WithTrace(ctx, &provider.Trace{
+				RefreshDone: func(info provider.RefreshInfo) {
+					mux.Lock()
+					defer mux.Unlock()
+
+					refresh = append(refresh, info)
+				},
+			})
+		}
+
+		BeforeEach(func() {
+			refresh = nil
+			fakeClient.TokenStub = func(ctx context.Context) (string, error) {
+				err := provider.Refresh(ctx, func(context.Context) (time.Time, error) {
+					started <- struct{}{}
+					<-release
+
+					return time.Time{}, nil
+				})
+
+				return "token", err
+			}
+		})
+
+		It("reports it to every caller, once as not shared", func() {
+			_, errs := request(traced(withCluster("vault-k8s-dv-cluster")), 3)
+			Expect(errs).To(Equal([]error{nil, nil, nil}))
+			Expect(fakeClient.TokenCallCount()).To(Equal(1))
+
+			Expect(refresh).To(HaveLen(3))
+
+			shared := 0
+			for _, info := range refresh {
+				if info.Shared {
+					shared++
+				}
+			}
+
+			Expect(shared).To(Equal(2))
+		})
+
+		It("does not report it to a caller that was canceled", func() {
+			ctx, cancel := context.WithCancel(traced(withCluster("vault-k8s-dv-cluster")))
+			errc := make(chan error, 1)
+
+			go func() {
+				_, err := client.Token(ctx)
+				errc <- err
+			}()
+			<-started
+
+			cancel()
+			Expect(<-errc).To(MatchError(context.Canceled))
+
+			close(release)
+			Expect(client.Token(withCluster("vault-k8s-dv-cluster"))).To(Equal("token"))
+
+			mux.Lock()
+			defer mux.Unlock()
+
+			Expect(refresh).To(BeEmpty())
+		})
+	})
+
+	It("unwraps to the original client", func() {
+		Expect(client.(provider.Wrapper).Unwrap()).To(BeIdenticalTo(fakeClient))
+	})
+})
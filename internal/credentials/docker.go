@@ -0,0 +1,56 @@
+// Package credentials renders tokens in the formats of the tools that
+// consume them, such as Docker config.json files and kubeconfigs, for both
+// the command line client and the sinks of the server.
+package credentials
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+const (
+	usernameGoogle    = "oauth2accesstoken"
+	usernameMicrosoft = "00000000-0000-0000-0000-000000000000"
+)
+
+// DefaultRegistryUsername returns the username registries of Google and
+// Microsoft expect with an access token.
+func DefaultRegistryUsername(host string) string {
+	switch {
+	case host == "gcr.io", strings.HasSuffix(host, ".gcr.io"), strings.HasSuffix(host, ".pkg.dev"):
+		return usernameGoogle
+	case strings.HasSuffix(host, ".azurecr.io"):
+		return usernameMicrosoft
+	}
+
+	return ""
+}
+
+// DockerConfigJSON returns a Docker config.json authenticating with the
+// registry at host as username with token, as also used by image pull
+// Secrets.
+func DockerConfigJSON(host, username, token string) ([]byte, error) {
+	type auth struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Auth     string `json:"auth"`
+	}
+
+	b, err := json.MarshalIndent(struct {
+		Auths map[string]auth `json:"auths"`
+	}{
+		Auths: map[string]auth{
+			host: {
+				Username: username,
+				Password: token,
+				Auth:     base64.StdEncoding.EncodeToString([]byte(username + ":" + token)),
+			},
+		},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(b, '\n'), nil
+}
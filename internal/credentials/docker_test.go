@@ -0,0 +1,30 @@
+package credentials_test
+
+import (
+	"github.com/homedepot/arcade/internal/credentials"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Docker", func() {
+	Describe("#DefaultRegistryUsername", func() {
+		It("returns the username of Google and Microsoft registries", func() {
+			Expect(credentials.DefaultRegistryUsername("gcr.io")).To(Equal("oauth2accesstoken"))
+			Expect(credentials.DefaultRegistryUsername("us-docker.pkg.dev")).To(Equal("oauth2accesstoken"))
+			Expect(credentials.DefaultRegistryUsername("example.azurecr.io")).To(Equal("00000000-0000-0000-0000-000000000000"))
+			Expect(credentials.DefaultRegistryUsername("registry.example.com")).To(BeEmpty())
+		})
+	})
+
+	Describe("#DockerConfigJSON", func() {
+		It("authenticates with the registry", func() {
+			b, err := credentials.DockerConfigJSON("gcr.io", "oauth2accesstoken", "some.token")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(MatchJSON(`{"auths": {"gcr.io": {
+				"username": "oauth2accesstoken",
+				"password": "some.token",
+				"auth": "b2F1dGgyYWNjZXNzdG9rZW46c29tZS50b2tlbg=="
+			}}}`))
+		})
+	})
+})
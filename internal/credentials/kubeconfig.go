@@ -0,0 +1,72 @@
+package credentials
+
+// Kubeconfig is a Kubernetes client configuration file, limited to the
+// fields arcade writes.
+type Kubeconfig struct {
+	APIVersion     string            `yaml:"apiVersion"`
+	Kind           string            `yaml:"kind"`
+	Clusters       []KubeconfigEntry `yaml:"clusters"`
+	Users          []KubeconfigEntry `yaml:"users"`
+	Contexts       []KubeconfigEntry `yaml:"contexts"`
+	CurrentContext string            `yaml:"current-context"`
+}
+
+// KubeconfigEntry is a named cluster, user or context of a Kubeconfig.
+type KubeconfigEntry struct {
+	Name    string             `yaml:"name"`
+	Cluster *KubeconfigCluster `yaml:"cluster,omitempty"`
+	User    *KubeconfigUser    `yaml:"user,omitempty"`
+	Context *KubeconfigContext `yaml:"context,omitempty"`
+}
+
+// KubeconfigCluster is the API server of a cluster.
+type KubeconfigCluster struct {
+	Server                   string `yaml:"server"`
+	CertificateAuthorityData string `yaml:"certificate-authority-data,omitempty"`
+	InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify,omitempty"`
+}
+
+// KubeconfigUser authenticates either with a static token or with a
+// credential plugin.
+type KubeconfigUser struct {
+	Token string          `yaml:"token,omitempty"`
+	Exec  *KubeconfigExec `yaml:"exec,omitempty"`
+}
+
+// KubeconfigExec runs a credential plugin, such as arcade exec-credential.
+type KubeconfigExec struct {
+	APIVersion      string   `yaml:"apiVersion"`
+	Command         string   `yaml:"command"`
+	Args            []string `yaml:"args"`
+	InteractiveMode string   `yaml:"interactiveMode"`
+}
+
+// KubeconfigContext pairs a cluster with the user to access it as.
+type KubeconfigContext struct {
+	Cluster string `yaml:"cluster"`
+	User    string `yaml:"user"`
+}
+
+// TokenKubeconfig returns a Kubeconfig authenticating with the API server
+// at server using token, whose cluster, user and context are all named
+// name. caData is the base64 encoded CA certificates of the cluster, if
+// any.
+func TokenKubeconfig(name, server, caData, token string) Kubeconfig {
+	return Kubeconfig{
+		APIVersion: "v1",
+		Kind:       "Config",
+		Clusters: []KubeconfigEntry{{
+			Name:    name,
+			Cluster: &KubeconfigCluster{Server: server, CertificateAuthorityData: caData},
+		}},
+		Users: []KubeconfigEntry{{
+			Name: name,
+			User: &KubeconfigUser{Token: token},
+		}},
+		Contexts: []KubeconfigEntry{{
+			Name:    name,
+			Context: &KubeconfigContext{Cluster: name, User: name},
+		}},
+		CurrentContext: name,
+	}
+}
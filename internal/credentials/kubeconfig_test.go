@@ -0,0 +1,36 @@
+package credentials_test
+
+import (
+	"github.com/homedepot/arcade/internal/credentials"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+)
+
+var _ = Describe("Kubeconfig", func() {
+	Describe("#TokenKubeconfig", func() {
+		It("authenticates with the token", func() {
+			b, err := yaml.Marshal(credentials.TokenKubeconfig("rancher", "https://rancher.example.com", "Y2E=", "some.token"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(MatchYAML(`
+apiVersion: v1
+kind: Config
+clusters:
+  - name: rancher
+    cluster:
+      server: https://rancher.example.com
+      certificate-authority-data: Y2E=
+users:
+  - name: rancher
+    user:
+      token: some.token
+contexts:
+  - name: rancher
+    context:
+      cluster: rancher
+      user: rancher
+current-context: rancher
+`))
+		})
+	})
+})
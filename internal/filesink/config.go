@@ -0,0 +1,167 @@
+package filesink
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/template"
+
+	"github.com/homedepot/arcade/internal/credentials"
+	"github.com/homedepot/arcade/internal/tokenwatch"
+)
+
+// Formats of the files written by the sink.
+const (
+	// FormatToken is the token alone.
+	FormatToken = "token"
+	// FormatEnv is an environment file setting the token and its expiry.
+	FormatEnv = "env"
+	// FormatKubeconfig is a kubeconfig authenticating with the token.
+	FormatKubeconfig = "kubeconfig"
+	// FormatDocker is a Docker config.json authenticating with a registry.
+	FormatDocker = "docker"
+
+	// DefaultEnvVar is the variable the token is set to in env files.
+	DefaultEnvVar = "ARCADE_TOKEN"
+	// defaultMode is the permissions of files unless configured otherwise.
+	defaultMode = 0600
+)
+
+// Config lists the files tokens are written to.
+type Config struct {
+	Files []File `json:"files"`
+}
+
+// File is a file the token of a provider is written to, replaced each
+// time the token is refreshed.
+type File struct {
+	// Provider is the name of the provider whose token is written.
+	Provider string `json:"provider"`
+	// Cluster is the cluster of Vault K8s providers.
+	Cluster string `json:"cluster,omitempty"`
+	// Path is where the file is written.
+	Path string `json:"path"`
+	// Mode is the permissions of the file in octal, 0600 by default.
+	Mode string `json:"mode,omitempty"`
+	// Format is one of token, env, kubeconfig or docker, token by
+	// default.
+	Format string `json:"format,omitempty"`
+	// Template is the path of a Go template the file is rendered from
+	// instead of a format.
+	Template string `json:"template,omitempty"`
+	// EnvVar is the variable env files set to the token.
+	EnvVar string `json:"envVar,omitempty"`
+	// Server and CertificateAuthorityData are the address and base64
+	// encoded CA certificates of the cluster of kubeconfig files.
+	Server                   string `json:"server,omitempty"`
+	CertificateAuthorityData string `json:"certificateAuthorityData,omitempty"`
+	// Registry is the registry docker files authenticate with, as
+	// Username. Username defaults to the username expected by the
+	// registries of Google and Microsoft.
+	Registry string `json:"registry,omitempty"`
+	Username string `json:"username,omitempty"`
+
+	mode os.FileMode
+	tmpl *template.Template
+}
+
+// LoadConfig reads the files tokens are written to from the JSON file at
+// path.
+func LoadConfig(path string) ([]File, error) {
+	var cfg Config
+	if err := tokenwatch.LoadConfig(path, &cfg); err != nil {
+		return nil, fmt.Errorf("error loading file sink configuration %s: %w", path, err)
+	}
+
+	paths := map[string]bool{}
+
+	for i := range cfg.Files {
+		f := &cfg.Files[i]
+
+		if err := f.init(); err != nil {
+			return nil, fmt.Errorf("invalid file sink configuration %s: file %d: %w", path, i+1, err)
+		}
+
+		if paths[filepath.Clean(f.Path)] {
+			return nil, fmt.Errorf("invalid file sink configuration %s: file %d: %s is written more than once", path, i+1, f.Path)
+		}
+
+		paths[filepath.Clean(f.Path)] = true
+	}
+
+	return cfg.Files, nil
+}
+
+// init validates f, setting its defaults and parsing its template.
+func (f *File) init() error {
+	if f.Provider == "" {
+		return errors.New("a provider is required")
+	}
+
+	if f.Path == "" {
+		return errors.New("a path is required")
+	}
+
+	f.mode = defaultMode
+
+	if f.Mode != "" {
+		mode, err := strconv.ParseUint(f.Mode, 8, 32)
+		if err != nil || mode > 0777 {
+			return fmt.Errorf("invalid mode %q", f.Mode)
+		}
+
+		f.mode = os.FileMode(mode)
+	}
+
+	if f.Template != "" {
+		if f.Format != "" {
+			return errors.New("only one of a format and a template may be set")
+		}
+
+		tmpl, err := template.New(filepath.Base(f.Template)).Funcs(funcs).ParseFiles(f.Template)
+		if err != nil {
+			return fmt.Errorf("error parsing template: %w", err)
+		}
+
+		f.tmpl = tmpl
+
+		return nil
+	}
+
+	switch f.Format {
+	case "":
+		f.Format = FormatToken
+	case FormatToken:
+	case FormatEnv:
+		if f.EnvVar == "" {
+			f.EnvVar = DefaultEnvVar
+		}
+	case FormatKubeconfig:
+		if f.Server == "" {
+			return errors.New("a server is required for kubeconfig files")
+		}
+	case FormatDocker:
+		if f.Registry == "" {
+			return errors.New("a registry is required for docker files")
+		}
+
+		if f.Username == "" {
+			f.Username = credentials.DefaultRegistryUsername(f.Registry)
+		}
+
+		if f.Username == "" {
+			return fmt.Errorf("no username configured for registry %s", f.Registry)
+		}
+	default:
+		return fmt.Errorf("unknown format %q", f.Format)
+	}
+
+	return nil
+}
+
+// provider returns the name the provider of f is requested by.
+func (f File) provider() string {
+	return tokenwatch.Provider(f.Provider, f.Cluster)
+}
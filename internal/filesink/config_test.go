@@ -0,0 +1,147 @@
+package filesink_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/homedepot/arcade/internal/filesink"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LoadConfig", func() {
+	var (
+		dir   string
+		cfg   string
+		files []filesink.File
+		err   error
+	)
+
+	BeforeEach(func() {
+		dir, _ = os.MkdirTemp("", "arcade-file-sink")
+		cfg = `{"files": [
+			{"provider": "google", "path": "/var/run/arcade/token"},
+			{"provider": "google", "path": "/var/run/arcade/config.json", "format": "docker", "registry": "us-docker.pkg.dev"}
+		]}`
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	JustBeforeEach(func() {
+		path := filepath.Join(dir, "file-sink.json")
+		_ = os.WriteFile(path, []byte(cfg), 0600)
+
+		files, err = filesink.LoadConfig(path)
+	})
+
+	It("loads the files with their defaults", func() {
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).To(HaveLen(2))
+		Expect(files[0].Format).To(Equal(filesink.FormatToken))
+		Expect(files[1].Username).To(Equal("oauth2accesstoken"))
+	})
+
+	When("the configuration has an unknown field", func() {
+		BeforeEach(func() {
+			cfg = `{"files": [{"provider": "google", "path": "/token", "owner": "root"}]}`
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`unknown field "owner"`))
+		})
+	})
+
+	When("a file has no provider", func() {
+		BeforeEach(func() {
+			cfg = `{"files": [{"path": "/token"}]}`
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HaveSuffix("file 1: a provider is required"))
+		})
+	})
+
+	When("a path is written more than once", func() {
+		BeforeEach(func() {
+			cfg = `{"files": [
+				{"provider": "google", "path": "/token"},
+				{"provider": "microsoft", "path": "/token"}
+			]}`
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HaveSuffix("file 2: /token is written more than once"))
+		})
+	})
+
+	When("the mode is invalid", func() {
+		BeforeEach(func() {
+			cfg = `{"files": [{"provider": "google", "path": "/token", "mode": "0999"}]}`
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HaveSuffix(`invalid mode "0999"`))
+		})
+	})
+
+	When("the format is unknown", func() {
+		BeforeEach(func() {
+			cfg = `{"files": [{"provider": "google", "path": "/token", "format": "netrc"}]}`
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HaveSuffix(`unknown format "netrc"`))
+		})
+	})
+
+	When("a kubeconfig has no server", func() {
+		BeforeEach(func() {
+			cfg = `{"files": [{"provider": "rancher", "path": "/kubeconfig", "format": "kubeconfig"}]}`
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HaveSuffix("a server is required for kubeconfig files"))
+		})
+	})
+
+	When("no username is known for a docker registry", func() {
+		BeforeEach(func() {
+			cfg = `{"files": [{"provider": "google", "path": "/config.json", "format": "docker", "registry": "registry.example.com"}]}`
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HaveSuffix("no username configured for registry registry.example.com"))
+		})
+	})
+
+	When("the template does not exist", func() {
+		BeforeEach(func() {
+			cfg = `{"files": [{"provider": "google", "path": "/token", "template": "/does/not/exist.tmpl"}]}`
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("error parsing template"))
+		})
+	})
+
+	When("both a format and a template are set", func() {
+		BeforeEach(func() {
+			cfg = `{"files": [{"provider": "google", "path": "/token", "format": "env", "template": "/token.tmpl"}]}`
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HaveSuffix("only one of a format and a template may be set"))
+		})
+	})
+})
@@ -0,0 +1,105 @@
+// Package filesink writes the tokens of providers to files, for consumers
+// that can only read credentials from disk.
+package filesink
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	arcadehttp "github.com/homedepot/arcade/internal/http"
+	"github.com/homedepot/arcade/internal/tokenwatch"
+)
+
+// Caller is the name the sink requests tokens as, which providers with an
+// access list must allow.
+const Caller = "file-sink"
+
+// Sink writes tokens to files, rewriting them every time their token is
+// refreshed.
+type Sink struct {
+	watcher tokenwatch.Watcher
+	files   map[string][]File
+}
+
+// New returns a Sink writing the tokens watched with w to files.
+func New(w tokenwatch.Watcher, files []File) *Sink {
+	s := &Sink{watcher: w, files: map[string][]File{}}
+
+	for _, f := range files {
+		s.files[f.provider()] = append(s.files[f.provider()], f)
+	}
+
+	return s
+}
+
+// Validate returns an error if the file sink requests tokens from providers
+// that do not exist or do not allow it.
+func (s *Sink) Validate() error {
+	return tokenwatch.Validate(s.watcher, Caller, slices.Collect(maps.Keys(s.files)))
+}
+
+// Run writes tokens to their files until ctx is done. Providers whose
+// token cannot be requested are retried with backoff.
+func (s *Sink) Run(ctx context.Context) {
+	tokenwatch.Run(ctx, Caller, slices.Collect(maps.Keys(s.files)), s.watch)
+}
+
+// watch writes the tokens of a provider to its files until ctx is done.
+func (s *Sink) watch(ctx context.Context, name string) {
+	tokenwatch.Watch(ctx, s.watcher, name, func(t arcadehttp.TokenResponse) {
+		for _, f := range s.files[name] {
+			if err := write(f, Data{Provider: name, Token: t.Token, Expiry: t.Expiry}); err != nil {
+				slog.ErrorContext(ctx, "error writing token file", slog.String("path", f.Path), slog.Any("error", err))
+			}
+		}
+	})
+}
+
+// write replaces the file f with its content for the token in d
+// atomically, so that readers never see a partially written file.
+func write(f File, d Data) error {
+	b, err := f.render(d)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), "."+filepath.Base(f.Path)+".tmp")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(f.mode); err != nil {
+		_ = tmp.Close()
+
+		return err
+	}
+
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), f.Path); err != nil {
+		return fmt.Errorf("error replacing %s: %w", f.Path, err)
+	}
+
+	return nil
+}
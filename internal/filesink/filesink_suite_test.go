@@ -0,0 +1,13 @@
+package filesink_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestFileSink(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "File Sink Suite")
+}
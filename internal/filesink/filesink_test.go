@@ -0,0 +1,166 @@
+package filesink_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/homedepot/arcade/internal/filesink"
+	arcadehttp "github.com/homedepot/arcade/internal/http"
+	"github.com/homedepot/arcade/internal/tokenwatch/tokenwatchfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sink", func() {
+	var (
+		dir     string
+		cfg     string
+		watcher *tokenwatchfakes.FakeWatcher
+		ctx     context.Context
+		cancel  context.CancelFunc
+		done    chan struct{}
+		expiry  time.Time
+	)
+
+	BeforeEach(func() {
+		dir, _ = os.MkdirTemp("", "arcade-file-sink")
+		watcher = tokenwatchfakes.NewFakeWatcher()
+		expiry = time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+		cfg = fmt.Sprintf(`{"files": [{"provider": "google", "path": %q}]}`, filepath.Join(dir, "token"))
+	})
+
+	AfterEach(func() {
+		cancel()
+		Eventually(done).Should(BeClosed())
+		os.RemoveAll(dir)
+	})
+
+	JustBeforeEach(func() {
+		path := filepath.Join(dir, "file-sink.json")
+		Expect(os.WriteFile(path, []byte(cfg), 0600)).To(Succeed())
+
+		files, err := filesink.LoadConfig(path)
+		Expect(err).ToNot(HaveOccurred())
+
+		ctx, cancel = context.WithCancel(context.Background())
+		done = make(chan struct{})
+
+		go func() {
+			defer close(done)
+
+			filesink.New(watcher, files).Run(ctx)
+		}()
+	})
+
+	read := func(name string) string {
+		b, _ := os.ReadFile(filepath.Join(dir, name))
+
+		return string(b)
+	}
+
+	It("writes every token to the file with restrictive permissions", func() {
+		watcher.Tokens <- arcadehttp.TokenResponse{Token: "token-1", Expiry: expiry}
+		Eventually(func() string { return read("token") }).Should(Equal("token-1"))
+
+		info, err := os.Stat(filepath.Join(dir, "token"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+		watcher.Tokens <- arcadehttp.TokenResponse{Token: "token-2", Expiry: expiry}
+		Eventually(func() string { return read("token") }).Should(Equal("token-2"))
+
+		entries, _ := os.ReadDir(dir)
+		Expect(entries).To(HaveLen(2))
+	})
+
+	It("requests tokens as the file sink", func() {
+		Eventually(watcher.Requests).Should(ConsistOf(arcadehttp.TokenRequest{Provider: "google"}))
+		Expect(watcher.Callers()).To(ConsistOf(filesink.Caller))
+	})
+
+	When("several files are written for a provider", func() {
+		BeforeEach(func() {
+			tmpl := filepath.Join(dir, "token.tmpl")
+			Expect(os.WriteFile(tmpl, []byte(`{{ .Provider }} {{ .Token | base64 }} {{ .Expiry.Unix }}`), 0600)).To(Succeed())
+
+			cfg = fmt.Sprintf(`{"files": [
+				{"provider": "google", "path": %q, "format": "env", "mode": "0640"},
+				{"provider": "google", "path": %q, "format": "docker", "registry": "gcr.io"},
+				{"provider": "vault-k8s-np", "cluster": "cluster1", "path": %q, "format": "kubeconfig",
+					"server": "https://cluster1.example.com", "certificateAuthorityData": "Y2E="},
+				{"provider": "google", "path": %q, "template": %q}
+			]}`, filepath.Join(dir, "env"), filepath.Join(dir, "config.json"),
+				filepath.Join(dir, "kubeconfig"), filepath.Join(dir, "custom"), tmpl)
+		})
+
+		It("watches each provider once and renders every file", func() {
+			Eventually(watcher.Requests).Should(ConsistOf(
+				arcadehttp.TokenRequest{Provider: "google"},
+				arcadehttp.TokenRequest{Provider: "vault-k8s-np-cluster1"},
+			))
+
+			watcher.Tokens <- arcadehttp.TokenResponse{Token: "token-1", Expiry: expiry}
+			watcher.Tokens <- arcadehttp.TokenResponse{Token: "token-1", Expiry: expiry}
+
+			Eventually(func() string { return read("env") }).Should(Equal("ARCADE_TOKEN=token-1\nARCADE_TOKEN_EXPIRY=2030-01-02T03:04:05Z\n"))
+
+			info, _ := os.Stat(filepath.Join(dir, "env"))
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0640)))
+
+			auth := base64.StdEncoding.EncodeToString([]byte("oauth2accesstoken:token-1"))
+			Eventually(func() string { return read("config.json") }).Should(MatchJSON(
+				fmt.Sprintf(`{"auths": {"gcr.io": {"username": "oauth2accesstoken", "password": "token-1", "auth": %q}}}`, auth)))
+
+			Eventually(func() string { return read("kubeconfig") }).Should(MatchYAML(`
+apiVersion: v1
+kind: Config
+clusters:
+- name: vault-k8s-np-cluster1
+  cluster:
+    server: https://cluster1.example.com
+    certificate-authority-data: Y2E=
+users:
+- name: vault-k8s-np-cluster1
+  user:
+    token: token-1
+contexts:
+- name: vault-k8s-np-cluster1
+  context:
+    cluster: vault-k8s-np-cluster1
+    user: vault-k8s-np-cluster1
+current-context: vault-k8s-np-cluster1
+`))
+
+			Eventually(func() string { return read("custom") }).Should(Equal(
+				fmt.Sprintf("google %s %d", base64.StdEncoding.EncodeToString([]byte("token-1")), expiry.Unix())))
+		})
+	})
+
+	When("a provider cannot be accessed", func() {
+		BeforeEach(func() {
+			watcher.AccessErrs = map[string]error{"google": errors.New("Token provider not allowed: google")}
+		})
+
+		It("fails validation", func() {
+			files, err := filesink.LoadConfig(filepath.Join(dir, "file-sink.json"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filesink.New(watcher, files).Validate()).To(MatchError("Token provider not allowed: google"))
+		})
+	})
+
+	When("the token cannot be requested", func() {
+		BeforeEach(func() {
+			watcher.Err = errors.New("error getting token")
+		})
+
+		It("retries with backoff", func() {
+			Eventually(watcher.Requests, 2*time.Second).Should(HaveLen(2))
+			Consistently(watcher.Requests, 500*time.Millisecond).Should(HaveLen(2))
+		})
+	})
+})
@@ -0,0 +1,78 @@
+package filesink
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/homedepot/arcade/internal/credentials"
+	"gopkg.in/yaml.v3"
+)
+
+// funcs are the functions available to templates.
+var funcs = template.FuncMap{
+	"base64": func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	},
+}
+
+// Data is the data templates are rendered with.
+type Data struct {
+	// Provider is the name the token was requested by.
+	Provider string
+	Token    string
+	// Expiry is when the token expires, or zero if unknown.
+	Expiry time.Time
+}
+
+// render returns the content of f for the token in d.
+func (f File) render(d Data) ([]byte, error) {
+	if f.tmpl != nil {
+		var b bytes.Buffer
+		if err := f.tmpl.Execute(&b, d); err != nil {
+			return nil, fmt.Errorf("error rendering template: %w", err)
+		}
+
+		return b.Bytes(), nil
+	}
+
+	switch f.Format {
+	case FormatEnv:
+		return f.env(d), nil
+	case FormatKubeconfig:
+		return f.kubeconfig(d)
+	case FormatDocker:
+		return f.docker(d)
+	}
+
+	return []byte(d.Token), nil
+}
+
+// env returns an environment file setting EnvVar to the token, and
+// EnvVar_EXPIRY to its expiry if known.
+func (f File) env(d Data) []byte {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s=%s\n", f.EnvVar, d.Token)
+
+	if !d.Expiry.IsZero() {
+		fmt.Fprintf(&b, "%s_EXPIRY=%s\n", f.EnvVar, d.Expiry.UTC().Format(time.RFC3339))
+	}
+
+	return []byte(b.String())
+}
+
+// kubeconfig returns a kubeconfig authenticating with Server using the
+// token, whose cluster, user and context are named after the provider.
+func (f File) kubeconfig(d Data) ([]byte, error) {
+	return yaml.Marshal(credentials.TokenKubeconfig(d.Provider, f.Server, f.CertificateAuthorityData, d.Token))
+}
+
+// docker returns a Docker config.json authenticating with Registry as
+// Username using the token.
+func (f File) docker(d Data) ([]byte, error) {
+	return credentials.DockerConfigJSON(f.Registry, f.Username, d.Token)
+}
@@ -2,9 +2,10 @@ package google
 
 import (
 	"context"
-	"sync"
-	"time"
+	"net/http"
 
+	"github.com/homedepot/arcade/pkg/provider"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 )
@@ -13,37 +14,33 @@ var (
 	clientScopes = []string{
 		"https://www.googleapis.com/auth/cloud-platform",
 	}
-	mux         sync.Mutex
-	expiration  time.Time
-	cachedToken *oauth2.Token
 )
 
 func NewClient() *Client {
-	return &Client{}
+	return &Client{
+		c: &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)},
+	}
+}
+
+type Client struct {
+	c *http.Client
 }
 
-type Client struct{}
-
-func (*Client) Token(ctx context.Context) (string, error) {
-	mux.Lock()
-	defer mux.Unlock()
-
-	if time.Now().UTC().After(expiration) || cachedToken == nil {
-		tokenSource, err := google.DefaultTokenSource(ctx, clientScopes...)
-		if err != nil {
-			return "", err
-		}
-
-		token, err := tokenSource.Token()
-		if err != nil {
-			return "", err
-		}
-		// Set the expiration for the Google token to be 90% expiry-threshold.
-		// Expiry looks something like '2021-03-26 15:53:24.513497 -0400 EDT m=+3599.302993422'
-		expiration = time.Now().UTC().Add((time.Until(token.Expiry) / 10) * 9)
-		// Set the cached token.
-		cachedToken = token
+// Fetch retrieves a new access token using the application default
+// credentials.
+func (c *Client) Fetch(ctx context.Context) (provider.Token, error) {
+	// The token source makes its requests with the client held by ctx.
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, c.c)
+
+	tokenSource, err := google.DefaultTokenSource(ctx, clientScopes...)
+	if err != nil {
+		return provider.Token{}, err
+	}
+
+	token, err := tokenSource.Token()
+	if err != nil {
+		return provider.Token{}, err
 	}
 
-	return cachedToken.AccessToken, nil
+	return provider.Token{AccessToken: token.AccessToken, Expiry: token.Expiry}, nil
 }
@@ -0,0 +1,110 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/homedepot/arcade/internal/logging"
+	"github.com/homedepot/arcade/internal/middleware"
+	"github.com/homedepot/arcade/pkg/arcadepb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// MetadataRequestID is the metadata key of the request ID, as the
+// X-Request-Id header of HTTP requests.
+const MetadataRequestID = "x-request-id"
+
+// interceptor authenticates and logs every call, as the middleware of the
+// HTTP server does for requests.
+type interceptor struct {
+	apiKeys map[string]string
+	logger  *slog.Logger
+}
+
+func (i interceptor) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	var res any
+
+	err := i.handle(ctx, info.FullMethod, func(ctx context.Context) error {
+		var err error
+
+		res, err = handler(ctx, req)
+
+		return err
+	})
+
+	return res, err
+}
+
+func (i interceptor) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return i.handle(ss.Context(), info.FullMethod, func(ctx context.Context) error {
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	})
+}
+
+// handle calls handler with a context holding the request ID and the
+// caller authenticated by the call's API key, and logs the call once
+// handled.
+func (i interceptor) handle(ctx context.Context, method string, handler func(context.Context) error) error {
+	start := time.Now()
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	id := middleware.EnsureRequestID(first(md, MetadataRequestID))
+	ctx = logging.NewContext(ctx)
+	logging.AddAttrs(ctx, slog.String(logging.KeyRequestID, id))
+	_ = grpc.SetHeader(ctx, metadata.Pairs(MetadataRequestID, id))
+
+	var err error
+
+	if name, ok := middleware.CallerForKey(i.apiKeys, first(md, arcadepb.MetadataAPIKey)); ok {
+		ctx = middleware.ContextWithCaller(ctx, name)
+		logging.AddAttrs(ctx, slog.String(logging.KeyCaller, name))
+
+		err = handler(ctx)
+	} else {
+		err = status.Error(codes.Unauthenticated, "bad api key")
+	}
+
+	code := status.Code(err)
+	level := slog.LevelInfo
+
+	switch code {
+	case codes.OK, codes.Canceled:
+	case codes.Internal, codes.Unavailable, codes.Unknown:
+		level = slog.LevelError
+	default:
+		level = slog.LevelWarn
+	}
+
+	i.logger.LogAttrs(ctx, level, "request",
+		slog.String("method", method),
+		slog.String("code", code.String()),
+		slog.Duration("latency", time.Since(start)),
+		slog.String("client_ip", sourceAddress(ctx)),
+	)
+
+	return err
+}
+
+// first returns the first value of key in md, if any.
+func first(md metadata.MD, key string) string {
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+
+	return ""
+}
+
+// serverStream is a grpc.ServerStream with the context of the call
+// replaced.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,260 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	arcadehttp "github.com/homedepot/arcade/internal/http"
+	"github.com/homedepot/arcade/internal/ratelimit"
+	"github.com/homedepot/arcade/pkg/arcadepb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// MetadataRetryAfter is the metadata key of the seconds a rate limited
+// caller should wait before retrying.
+const MetadataRetryAfter = "retry-after"
+
+// Server is a gRPC server of the Arcade service, serving tokens from a
+// Controller with the same authentication, rate limits, access lists and
+// audit log as its HTTP handlers.
+type Server struct {
+	*grpc.Server
+	service *service
+}
+
+// NewServer returns a Server of the tokens of ctl, authenticating calls
+// with any of the given API keys, keyed by the name identifying their
+// callers, and logging them with logger.
+func NewServer(ctl *arcadehttp.Controller, apiKeys map[string]string, logger *slog.Logger) *Server {
+	i := interceptor{apiKeys: apiKeys, logger: logger}
+	s := &Server{
+		Server: grpc.NewServer(
+			grpc.ChainUnaryInterceptor(i.unary),
+			grpc.ChainStreamInterceptor(i.stream),
+		),
+		service: &service{ctl: ctl, done: make(chan struct{})},
+	}
+
+	arcadepb.RegisterArcadeServer(s.Server, s.service)
+
+	return s
+}
+
+// Shutdown ends every watch, so that callers reconnect elsewhere, and
+// stops the server once in-flight calls have completed, or when ctx is
+// done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.service.once.Do(func() { close(s.service.done) })
+
+	stopped := make(chan struct{})
+
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.Stop()
+
+		return ctx.Err()
+	}
+}
+
+type service struct {
+	arcadepb.UnimplementedArcadeServer
+	ctl *arcadehttp.Controller
+	// done is closed once the server shuts down.
+	done chan struct{}
+	once sync.Once
+}
+
+func (s *service) GetToken(ctx context.Context, req *arcadepb.GetTokenRequest) (*arcadepb.Token, error) {
+	t, err := s.ctl.Token(ctx, arcadehttp.TokenRequest{
+		Provider:      providerName(req.GetProvider(), req.GetCluster()),
+		NoCache:       req.GetNoCache(),
+		SourceAddress: sourceAddress(ctx),
+	})
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return token(t), nil
+}
+
+func (s *service) ListProviders(ctx context.Context, _ *arcadepb.ListProvidersRequest) (*arcadepb.ListProvidersResponse, error) {
+	res := &arcadepb.ListProvidersResponse{}
+
+	for _, info := range s.ctl.ListProviders(ctx) {
+		p, err := providerInfo(info)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		res.Providers = append(res.Providers, p)
+	}
+
+	return res, nil
+}
+
+func (s *service) WatchToken(req *arcadepb.WatchTokenRequest, stream grpc.ServerStreamingServer[arcadepb.Token]) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-s.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	err := s.ctl.WatchToken(ctx, arcadehttp.TokenRequest{
+		Provider:      providerName(req.GetProvider(), req.GetCluster()),
+		SourceAddress: sourceAddress(ctx),
+	}, func(t arcadehttp.TokenResponse) error {
+		return stream.Send(token(t))
+	})
+
+	select {
+	case <-s.done:
+		return status.Error(codes.Unavailable, "server shutting down")
+	default:
+	}
+
+	return toStatus(ctx, err)
+}
+
+// providerName returns the name a provider is requested by, followed by
+// the cluster of Vault K8s providers.
+func providerName(name, cluster string) string {
+	if name == "" || cluster == "" {
+		return name
+	}
+
+	return name + "-" + cluster
+}
+
+// sourceAddress returns the IP address of the caller.
+func sourceAddress(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
+
+// toStatus converts an error returned by the Controller to a gRPC status
+// error, setting the retry-after header of rate limited calls.
+func toStatus(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+
+	var e *arcadehttp.Error
+	if !errors.As(err, &e) {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	if e.RetryAfter > 0 {
+		_ = grpc.SetHeader(ctx, metadata.Pairs(MetadataRetryAfter, strconv.Itoa(ratelimit.RetryAfter(e.RetryAfter))))
+	}
+
+	return status.Error(code(e.Code), e.Message)
+}
+
+// code returns the gRPC code of an error code.
+func code(errorCode string) codes.Code {
+	switch errorCode {
+	case arcadehttp.ErrorCodeInvalidRequest:
+		return codes.InvalidArgument
+	case arcadehttp.ErrorCodeUnauthorized:
+		return codes.Unauthenticated
+	case arcadehttp.ErrorCodeForbidden:
+		return codes.PermissionDenied
+	case arcadehttp.ErrorCodeUnknownProvider, arcadehttp.ErrorCodeNotFound:
+		return codes.NotFound
+	case arcadehttp.ErrorCodeRateLimited:
+		return codes.ResourceExhausted
+	case arcadehttp.ErrorCodeUpstream:
+		return codes.Unavailable
+	}
+
+	return codes.Internal
+}
+
+func token(t arcadehttp.TokenResponse) *arcadepb.Token {
+	return &arcadepb.Token{Token: t.Token, Expiry: timestamp(t.Expiry)}
+}
+
+func providerInfo(info arcadehttp.ProviderInfo) (*arcadepb.Provider, error) {
+	b, err := json.Marshal(info.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+
+	config, err := structpb.NewStruct(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return &arcadepb.Provider{
+		Name:         info.Name,
+		Type:         info.Type,
+		Parameters:   info.Parameters,
+		Config:       config,
+		CachedTokens: int32(info.CachedTokens),
+		Status: &arcadepb.ProviderStatus{
+			LastSuccess: timestampPtr(info.Status.LastSuccess),
+			LastError:   info.Status.LastError,
+			LastErrorAt: timestampPtr(info.Status.LastErrorAt),
+			Expiry:      timestampPtr(info.Status.Expiry),
+		},
+	}, nil
+}
+
+// timestamp converts t to a Timestamp, or nil if t is zero.
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+
+	return timestamppb.New(t)
+}
+
+func timestampPtr(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+
+	return timestamp(*t)
+}
@@ -0,0 +1,254 @@
+package grpc_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/homedepot/arcade/internal/cache"
+	arcadegrpc "github.com/homedepot/arcade/internal/grpc"
+	arcadehttp "github.com/homedepot/arcade/internal/http"
+	"github.com/homedepot/arcade/internal/middleware"
+	"github.com/homedepot/arcade/pkg/arcadepb"
+	"github.com/homedepot/arcade/pkg/provider"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var _ = Describe("Server", func() {
+	var (
+		srv     *arcadegrpc.Server
+		conn    *grpc.ClientConn
+		client  arcadepb.ArcadeClient
+		ctx     context.Context
+		mux     sync.Mutex
+		fetches int
+		err     error
+	)
+
+	// dial connects to the server with the given API key.
+	dial := func(lis *bufconn.Listener, apiKey string) *grpc.ClientConn {
+		c, err := grpc.NewClient("passthrough:///bufnet",
+			grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+				return lis.Dial()
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithPerRPCCredentials(arcadepb.APIKey(apiKey)),
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		return c
+	}
+
+	var lis *bufconn.Listener
+
+	BeforeEach(func() {
+		fetches = 0
+		ctx = context.Background()
+
+		f := provider.FetcherFunc(func(context.Context) (provider.Token, error) {
+			mux.Lock()
+			defer mux.Unlock()
+
+			fetches++
+
+			return provider.Token{
+				AccessToken: fmt.Sprintf("token-%d", fetches),
+				Expiry:      time.Now().Add(time.Hour),
+			}, nil
+		})
+
+		ctl := &arcadehttp.Controller{
+			Tokenizers: map[string]arcadehttp.Tokenizer{
+				"google":       cache.New(f, cache.Policy{}),
+				"rancher":      cache.New(f, cache.Policy{}),
+				"vault-k8s-dv": cache.New(f, cache.Policy{}),
+			},
+			Providers: map[string]arcadehttp.Provider{
+				"google": {Type: arcadehttp.ProviderTypeGoogle, Name: "google"},
+				"rancher": {
+					Type:           arcadehttp.ProviderTypeRancher,
+					Name:           "rancher",
+					Password:       "some-password",
+					URL:            "https://rancher.example.com",
+					AllowedCallers: []string{"ci"},
+				},
+				"vault-k8s-dv": {Type: arcadehttp.ProviderTypeVaultK8s, Name: "vault-k8s-dv"},
+			},
+		}
+
+		srv = arcadegrpc.NewServer(ctl, map[string]string{
+			middleware.DefaultAPIKeyName: "default-key",
+			"ci":                         "ci-key",
+		}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+		lis = bufconn.Listen(1 << 20)
+
+		go func() {
+			_ = srv.Serve(lis)
+		}()
+
+		conn = dial(lis, "default-key")
+		client = arcadepb.NewArcadeClient(conn)
+	})
+
+	AfterEach(func() {
+		conn.Close()
+		srv.Stop()
+	})
+
+	Describe("#GetToken", func() {
+		var token *arcadepb.Token
+
+		JustBeforeEach(func() {
+			token, err = client.GetToken(ctx, &arcadepb.GetTokenRequest{Provider: "google"})
+		})
+
+		It("returns the token and its expiry", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(token.GetToken()).To(Equal("token-1"))
+			Expect(token.GetExpiry().AsTime()).To(BeTemporally("~", time.Now().Add(time.Hour), time.Second))
+		})
+
+		It("returns cached tokens unless asked not to", func() {
+			token, err = client.GetToken(ctx, &arcadepb.GetTokenRequest{Provider: "google"})
+			Expect(token.GetToken()).To(Equal("token-1"))
+
+			token, err = client.GetToken(ctx, &arcadepb.GetTokenRequest{Provider: "google", NoCache: true})
+			Expect(token.GetToken()).To(Equal("token-2"))
+		})
+
+		It("passes the cluster of Vault K8s providers", func() {
+			_, err = client.GetToken(ctx, &arcadepb.GetTokenRequest{Provider: "vault-k8s-dv"})
+			Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+
+			token, err = client.GetToken(ctx, &arcadepb.GetTokenRequest{Provider: "vault-k8s-dv", Cluster: "some-cluster"})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		When("the provider is unknown", func() {
+			It("returns not found", func() {
+				_, err = client.GetToken(ctx, &arcadepb.GetTokenRequest{Provider: "microsoft"})
+				Expect(status.Code(err)).To(Equal(codes.NotFound))
+				Expect(status.Convert(err).Message()).To(Equal("Unsupported token provider: microsoft"))
+			})
+		})
+
+		When("the caller may not access the provider", func() {
+			It("returns permission denied", func() {
+				_, err = client.GetToken(ctx, &arcadepb.GetTokenRequest{Provider: "rancher"})
+				Expect(status.Code(err)).To(Equal(codes.PermissionDenied))
+			})
+		})
+
+		When("the API key is wrong", func() {
+			BeforeEach(func() {
+				conn.Close()
+				conn = dial(lis, "wrong-key")
+				client = arcadepb.NewArcadeClient(conn)
+			})
+
+			It("returns unauthenticated", func() {
+				Expect(status.Code(err)).To(Equal(codes.Unauthenticated))
+				Expect(fetches).To(BeZero())
+			})
+		})
+	})
+
+	Describe("#ListProviders", func() {
+		It("lists the providers the caller may access", func() {
+			res, err := client.ListProviders(ctx, &arcadepb.ListProvidersRequest{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.GetProviders()).To(HaveLen(2))
+			Expect(res.GetProviders()[0].GetName()).To(Equal("google"))
+			Expect(res.GetProviders()[1].GetParameters()).To(Equal([]string{"cluster"}))
+		})
+
+		When("the caller may access restricted providers", func() {
+			BeforeEach(func() {
+				conn.Close()
+				conn = dial(lis, "ci-key")
+				client = arcadepb.NewArcadeClient(conn)
+			})
+
+			It("lists them without their credentials", func() {
+				res, err := client.ListProviders(ctx, &arcadepb.ListProvidersRequest{})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res.GetProviders()).To(HaveLen(3))
+
+				rancher := res.GetProviders()[1]
+				Expect(rancher.GetName()).To(Equal("rancher"))
+				Expect(rancher.GetConfig().GetFields()["url"].GetStringValue()).To(Equal("https://rancher.example.com"))
+				Expect(rancher.GetConfig().GetFields()).ToNot(HaveKey("password"))
+			})
+		})
+	})
+
+	Describe("#WatchToken", func() {
+		var (
+			stream grpc.ServerStreamingClient[arcadepb.Token]
+			cancel context.CancelFunc
+		)
+
+		BeforeEach(func() {
+			ctx, cancel = context.WithCancel(ctx)
+		})
+
+		JustBeforeEach(func() {
+			stream, err = client.WatchToken(ctx, &arcadepb.WatchTokenRequest{Provider: "google"})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			cancel()
+		})
+
+		It("sends the current token and then every refreshed token", func() {
+			token, err := stream.Recv()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(token.GetToken()).To(Equal("token-1"))
+			Expect(token.GetExpiry()).ToNot(BeNil())
+
+			_, err = client.GetToken(ctx, &arcadepb.GetTokenRequest{Provider: "google", NoCache: true})
+			Expect(err).ToNot(HaveOccurred())
+
+			token, err = stream.Recv()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(token.GetToken()).To(Equal("token-2"))
+		})
+
+		When("the provider is unknown", func() {
+			It("ends the stream with an error", func() {
+				stream, err = client.WatchToken(ctx, &arcadepb.WatchTokenRequest{Provider: "microsoft"})
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = stream.Recv()
+				Expect(status.Code(err)).To(Equal(codes.NotFound))
+			})
+		})
+
+		When("the server shuts down", func() {
+			It("ends the stream as unavailable", func() {
+				_, err := stream.Recv()
+				Expect(err).ToNot(HaveOccurred())
+
+				shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancelShutdown()
+
+				Expect(srv.Shutdown(shutdownCtx)).To(Succeed())
+
+				_, err = stream.Recv()
+				Expect(status.Code(err)).To(Equal(codes.Unavailable))
+			})
+		})
+	})
+})
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/homedepot/arcade/internal/audit"
+	"github.com/homedepot/arcade/internal/cache"
+	"github.com/homedepot/arcade/internal/logging"
+	"github.com/homedepot/arcade/internal/middleware"
+	"github.com/homedepot/arcade/pkg/provider"
+)
+
+// RefreshToken fetches a new token for a given provider, replacing any
+// cached token. The token itself is not returned, but its fingerprint is
+// recorded in the audit log.
+func (ctl *Controller) RefreshToken(c *gin.Context) {
+	providerName := providerName(c)
+	ctx := c.Request.Context()
+
+	event := audit.Event{
+		Time:          time.Now().In(time.UTC),
+		RequestID:     logging.RequestID(ctx),
+		Caller:        middleware.CallerFromContext(ctx),
+		SourceAddress: c.ClientIP(),
+		Action:        audit.ActionRefresh,
+		Provider:      providerName,
+		Outcome:       audit.OutcomeUnsupportedProvider,
+	}
+	defer func() { ctl.Auditor.Record(event) }()
+
+	ctx, tokenizerName, cluster := resolve(ctx, providerName)
+	event.Cluster = cluster
+
+	// Admins may refresh the tokens of every provider, regardless of its
+	// access list.
+	if err := ctl.checkProvider(providerName); err != nil {
+		abort(c, err)
+
+		return
+	}
+
+	logging.AddAttrs(ctx, slog.String(logging.KeyProvider, providerName))
+
+	t, err := ctl.Tokenizers[tokenizerName].Token(provider.WithNoCache(ctx))
+	if errors.Is(err, provider.ErrRefreshLimited) {
+		abort(c, ctl.rateLimited(ctx, &event, RateLimitScopeRefresh, tokenizerName, time.Second))
+
+		return
+	}
+
+	if err != nil {
+		slog.ErrorContext(ctx, "error refreshing token", slog.Any("error", err))
+
+		event.Outcome = audit.OutcomeError
+
+		abort(c, errUpstream(err))
+
+		return
+	}
+
+	event.Outcome = audit.OutcomeSuccess
+	event.TokenFingerprint = audit.Fingerprint(t)
+
+	slog.InfoContext(ctx, "token refreshed")
+	c.Status(http.StatusNoContent)
+}
+
+// InvalidateToken removes the cached token of a given provider, so that
+// the next request for it fetches a new one. For a Vault K8s provider
+// without a cluster, the tokens of every cluster are removed.
+func (ctl *Controller) InvalidateToken(c *gin.Context) {
+	providerName := providerName(c)
+	ctx := c.Request.Context()
+
+	event := audit.Event{
+		Time:          time.Now().In(time.UTC),
+		RequestID:     logging.RequestID(ctx),
+		Caller:        middleware.CallerFromContext(ctx),
+		SourceAddress: c.ClientIP(),
+		Action:        audit.ActionInvalidate,
+		Provider:      providerName,
+		Outcome:       audit.OutcomeUnsupportedProvider,
+	}
+	defer func() { ctl.Auditor.Record(event) }()
+
+	ctx, tokenizerName, cluster := resolve(ctx, providerName)
+	event.Cluster = cluster
+
+	tokenizer, ok := ctl.Tokenizers[tokenizerName]
+	if !ok {
+		unsupportedProvider(c, providerName)
+
+		return
+	}
+
+	logging.AddAttrs(ctx, slog.String(logging.KeyProvider, providerName))
+
+	if tc, ok := provider.As[*cache.Cache](tokenizer); ok {
+		if cluster == "" {
+			tc.Clear(ctx)
+		} else {
+			tc.Invalidate(ctx)
+		}
+	}
+
+	event.Outcome = audit.OutcomeSuccess
+
+	slog.InfoContext(ctx, "token cache invalidated")
+	c.Status(http.StatusNoContent)
+}
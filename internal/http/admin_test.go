@@ -0,0 +1,380 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/homedepot/arcade/internal/audit"
+	"github.com/homedepot/arcade/internal/cache"
+	arcadehttp "github.com/homedepot/arcade/internal/http"
+	"github.com/homedepot/arcade/internal/ratelimit"
+	"github.com/homedepot/arcade/pkg/provider"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Admin", func() {
+	var (
+		adminSvr *httptest.Server
+		ctl      *arcadehttp.Controller
+		fetches  int32
+		fetchErr error
+		expiry   time.Time
+	)
+
+	// get requests a token from the server, returning it.
+	get := func(header http.Header) string {
+		r, _ := http.NewRequest(http.MethodGet, adminSvr.URL+"/tokens?provider=google", nil)
+		for k, v := range header {
+			r.Header[k] = v
+		}
+
+		res, err := http.DefaultClient.Do(r)
+		Expect(err).ToNot(HaveOccurred())
+
+		defer res.Body.Close()
+
+		var t Tokens
+		b, _ := io.ReadAll(res.Body)
+		_ = json.Unmarshal(b, &t)
+
+		return t.Token
+	}
+
+	// do makes a request to the server, returning its response status code
+	// and error message.
+	do := func(method, uri, body string) (int, string) {
+		r, _ := http.NewRequest(method, adminSvr.URL+uri, strings.NewReader(body))
+
+		res, err := http.DefaultClient.Do(r)
+		Expect(err).ToNot(HaveOccurred())
+
+		defer res.Body.Close()
+
+		var t Tokens
+		b, _ := io.ReadAll(res.Body)
+		_ = json.Unmarshal(b, &t)
+
+		return res.StatusCode, t.Error
+	}
+
+	// events closes the auditor of the controller and returns the events it
+	// recorded to auditLog.
+	events := func(auditLog *bytes.Buffer) []audit.Event {
+		Expect(ctl.Auditor.Close(context.Background())).To(Succeed())
+
+		var events []audit.Event
+		dec := json.NewDecoder(auditLog)
+		for dec.More() {
+			var e audit.Event
+			Expect(dec.Decode(&e)).To(Succeed())
+			events = append(events, e)
+		}
+
+		return events
+	}
+
+	BeforeEach(func() {
+		atomic.StoreInt32(&fetches, 0)
+		fetchErr = nil
+		expiry = time.Time{}
+
+		f := provider.FetcherFunc(func(context.Context) (provider.Token, error) {
+			n := atomic.AddInt32(&fetches, 1)
+			if fetchErr != nil {
+				return provider.Token{}, fetchErr
+			}
+
+			return provider.Token{AccessToken: "token-" + strconv.Itoa(int(n)), Expiry: expiry}, nil
+		})
+
+		gin.SetMode(gin.ReleaseMode)
+
+		ctl = &arcadehttp.Controller{
+			Tokenizers: map[string]arcadehttp.Tokenizer{
+				"google": cache.New(f, cache.Policy{MaxTTL: time.Hour}),
+			},
+		}
+
+		r := gin.New()
+		r.GET("/tokens", ctl.GetToken)
+		r.POST("/tokens/refresh", ctl.RefreshToken)
+		r.DELETE("/tokens/cache", ctl.InvalidateToken)
+		r.POST("/tokens/report-invalid", ctl.ReportInvalidToken)
+
+		adminSvr = httptest.NewServer(r)
+	})
+
+	AfterEach(func() {
+		adminSvr.Close()
+	})
+
+	Describe("#GetToken", func() {
+		When("the expiry of the token is known", func() {
+			BeforeEach(func() {
+				expiry = time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+			})
+
+			It("returns it", func() {
+				res, err := http.Get(adminSvr.URL + "/tokens?provider=google")
+				Expect(err).ToNot(HaveOccurred())
+
+				defer res.Body.Close()
+
+				b, _ := io.ReadAll(res.Body)
+				Expect(b).To(MatchJSON(`{"token":"token-1","expiry":"2030-01-02T03:04:05Z"}`))
+			})
+		})
+
+		When("the expiry of the token is unknown", func() {
+			It("is not returned", func() {
+				res, err := http.Get(adminSvr.URL + "/tokens?provider=google")
+				Expect(err).ToNot(HaveOccurred())
+
+				defer res.Body.Close()
+
+				b, _ := io.ReadAll(res.Body)
+				Expect(b).To(MatchJSON(`{"token":"token-1"}`))
+			})
+		})
+
+		When("the request has a Cache-Control: no-cache header", func() {
+			It("fetches a new token", func() {
+				Expect(get(nil)).To(Equal("token-1"))
+				Expect(get(nil)).To(Equal("token-1"))
+				Expect(get(http.Header{"Cache-Control": {"max-age=0, no-cache"}})).To(Equal("token-2"))
+				Expect(get(nil)).To(Equal("token-2"))
+			})
+		})
+	})
+
+	Describe("#RefreshToken", func() {
+		When("the provider is not supported", func() {
+			It("returns bad request", func() {
+				status, msg := do(http.MethodPost, "/tokens/refresh?provider=fake", "")
+				Expect(status).To(Equal(http.StatusBadRequest))
+				Expect(msg).To(Equal("Unsupported token provider: fake"))
+			})
+		})
+
+		When("a vault-k8s provider has no cluster", func() {
+			BeforeEach(func() {
+				ctl.Tokenizers["vault-k8s-np"] = ctl.Tokenizers["google"]
+				ctl.Providers = map[string]arcadehttp.Provider{
+					"vault-k8s-np": {Name: "vault-k8s-np", Type: arcadehttp.ProviderTypeVaultK8s},
+				}
+			})
+
+			It("returns bad request without fetching a token", func() {
+				status, msg := do(http.MethodPost, "/tokens/refresh?provider=vault-k8s-np", "")
+				Expect(status).To(Equal(http.StatusBadRequest))
+				Expect(msg).To(Equal("A cluster is required for token provider: vault-k8s-np"))
+				Expect(atomic.LoadInt32(&fetches)).To(BeZero())
+			})
+		})
+
+		When("the provider has an access list", func() {
+			BeforeEach(func() {
+				ctl.Providers = map[string]arcadehttp.Provider{
+					"google": {Name: "google", Type: arcadehttp.ProviderTypeGoogle, AllowedCallers: []string{"ci"}},
+				}
+			})
+
+			It("still refreshes the token", func() {
+				status, _ := do(http.MethodPost, "/tokens/refresh?provider=google", "")
+				Expect(status).To(Equal(http.StatusNoContent))
+			})
+		})
+
+		When("fetching a new token fails", func() {
+			BeforeEach(func() {
+				fetchErr = errors.New("error fetching token")
+			})
+
+			It("returns an internal server error", func() {
+				status, msg := do(http.MethodPost, "/tokens/refresh?provider=google", "")
+				Expect(status).To(Equal(http.StatusInternalServerError))
+				Expect(msg).To(Equal("error fetching token"))
+			})
+		})
+
+		When("too many tokens are being refreshed", func() {
+			BeforeEach(func() {
+				fetchErr = provider.ErrRefreshLimited
+			})
+
+			It("returns too many requests", func() {
+				r, _ := http.NewRequest(http.MethodPost, adminSvr.URL+"/tokens/refresh?provider=google", nil)
+				res, err := http.DefaultClient.Do(r)
+				Expect(err).ToNot(HaveOccurred())
+
+				defer res.Body.Close()
+
+				Expect(res.StatusCode).To(Equal(http.StatusTooManyRequests))
+				Expect(res.Header.Get("Retry-After")).To(Equal("1"))
+			})
+		})
+
+		When("it succeeds", func() {
+			It("replaces the cached token", func() {
+				Expect(get(nil)).To(Equal("token-1"))
+				status, _ := do(http.MethodPost, "/tokens/refresh?provider=google", "")
+				Expect(status).To(Equal(http.StatusNoContent))
+				Expect(get(nil)).To(Equal("token-2"))
+				Expect(atomic.LoadInt32(&fetches)).To(Equal(int32(2)))
+			})
+		})
+
+		It("records an audit event", func() {
+			auditLog := &bytes.Buffer{}
+			ctl.Auditor = audit.New(audit.NewWriterSink(auditLog), 0)
+
+			status, _ := do(http.MethodPost, "/tokens/refresh?provider=google", "")
+			Expect(status).To(Equal(http.StatusNoContent))
+
+			events := events(auditLog)
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Action).To(Equal(audit.ActionRefresh))
+			Expect(events[0].Provider).To(Equal("google"))
+			Expect(events[0].Outcome).To(Equal(audit.OutcomeSuccess))
+			Expect(events[0].TokenFingerprint).To(Equal(audit.Fingerprint("token-1")))
+		})
+	})
+
+	Describe("#InvalidateToken", func() {
+		When("the provider is not supported", func() {
+			It("returns bad request", func() {
+				status, msg := do(http.MethodDelete, "/tokens/cache?provider=fake", "")
+				Expect(status).To(Equal(http.StatusBadRequest))
+				Expect(msg).To(Equal("Unsupported token provider: fake"))
+			})
+		})
+
+		When("it succeeds", func() {
+			It("removes the cached token", func() {
+				Expect(get(nil)).To(Equal("token-1"))
+				status, _ := do(http.MethodDelete, "/tokens/cache?provider=google", "")
+				Expect(status).To(Equal(http.StatusNoContent))
+				Expect(atomic.LoadInt32(&fetches)).To(Equal(int32(1)))
+				Expect(get(nil)).To(Equal("token-2"))
+			})
+		})
+
+		It("records an audit event", func() {
+			auditLog := &bytes.Buffer{}
+			ctl.Auditor = audit.New(audit.NewWriterSink(auditLog), 0)
+
+			status, _ := do(http.MethodDelete, "/tokens/cache?provider=fake", "")
+			Expect(status).To(Equal(http.StatusBadRequest))
+			status, _ = do(http.MethodDelete, "/tokens/cache?provider=google", "")
+			Expect(status).To(Equal(http.StatusNoContent))
+
+			events := events(auditLog)
+			Expect(events).To(HaveLen(2))
+			Expect(events[0].Action).To(Equal(audit.ActionInvalidate))
+			Expect(events[0].Outcome).To(Equal(audit.OutcomeUnsupportedProvider))
+			Expect(events[1].Action).To(Equal(audit.ActionInvalidate))
+			Expect(events[1].Provider).To(Equal("google"))
+			Expect(events[1].Outcome).To(Equal(audit.OutcomeSuccess))
+		})
+	})
+
+	Describe("#ReportInvalidToken", func() {
+		report := func(token string) (int, string) {
+			return do(http.MethodPost, "/tokens/report-invalid?provider=google",
+				`{"fingerprint":"`+audit.Fingerprint(token)+`"}`)
+		}
+
+		When("the request has no fingerprint", func() {
+			It("returns bad request", func() {
+				status, msg := do(http.MethodPost, "/tokens/report-invalid?provider=google", "{}")
+				Expect(status).To(Equal(http.StatusBadRequest))
+				Expect(msg).To(Equal("A token fingerprint is required"))
+			})
+		})
+
+		When("the provider is not supported", func() {
+			It("returns bad request", func() {
+				status, msg := do(http.MethodPost, "/tokens/report-invalid?provider=fake", `{"fingerprint":"sha256:00"}`)
+				Expect(status).To(Equal(http.StatusBadRequest))
+				Expect(msg).To(Equal("Unsupported token provider: fake"))
+			})
+		})
+
+		When("the reported token is cached", func() {
+			It("replaces it", func() {
+				Expect(get(nil)).To(Equal("token-1"))
+				status, _ := report("token-1")
+				Expect(status).To(Equal(http.StatusNoContent))
+				Expect(atomic.LoadInt32(&fetches)).To(Equal(int32(2)))
+				Expect(get(nil)).To(Equal("token-2"))
+			})
+		})
+
+		When("the reported token has already been replaced", func() {
+			It("keeps the cached token", func() {
+				Expect(get(nil)).To(Equal("token-1"))
+				_, _ = report("token-1")
+				status, _ := report("token-1")
+				Expect(status).To(Equal(http.StatusNoContent))
+				Expect(get(nil)).To(Equal("token-2"))
+				Expect(atomic.LoadInt32(&fetches)).To(Equal(int32(2)))
+			})
+		})
+
+		When("the caller exceeds its rate limit", func() {
+			BeforeEach(func() {
+				ctl.CallerLimiter = ratelimit.New(0.001, 2)
+			})
+
+			It("returns too many requests without fetching a token", func() {
+				Expect(get(nil)).To(Equal("token-1"))
+				status, _ := report("token-1")
+				Expect(status).To(Equal(http.StatusNoContent))
+				status, msg := report("token-2")
+				Expect(status).To(Equal(http.StatusTooManyRequests))
+				Expect(msg).To(Equal("Too many requests"))
+				Expect(atomic.LoadInt32(&fetches)).To(Equal(int32(2)))
+			})
+		})
+
+		When("the provider does not allow the caller", func() {
+			BeforeEach(func() {
+				ctl.Providers = map[string]arcadehttp.Provider{
+					"google": {Name: "google", Type: arcadehttp.ProviderTypeGoogle, AllowedCallers: []string{"ci"}},
+				}
+			})
+
+			It("returns forbidden", func() {
+				status, _ := report("token-1")
+				Expect(status).To(Equal(http.StatusForbidden))
+			})
+		})
+
+		It("records an audit event", func() {
+			auditLog := &bytes.Buffer{}
+			ctl.Auditor = audit.New(audit.NewWriterSink(auditLog), 0)
+
+			Expect(get(nil)).To(Equal("token-1"))
+			status, _ := report("token-1")
+			Expect(status).To(Equal(http.StatusNoContent))
+
+			events := events(auditLog)
+			Expect(events).To(HaveLen(2))
+			Expect(events[1].Action).To(Equal(audit.ActionReportInvalid))
+			Expect(events[1].Provider).To(Equal("google"))
+			Expect(events[1].Outcome).To(Equal(audit.OutcomeSuccess))
+			Expect(events[1].TokenFingerprint).To(Equal(audit.Fingerprint("token-1")))
+		})
+	})
+})
@@ -0,0 +1,416 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// providerFile is a configuration file listing many providers.
+type providerFile struct {
+	Providers []json.RawMessage `json:"providers"`
+}
+
+// loadProviders reads the configuration of every provider from the files
+// in dir. Files are JSON or YAML, holding either a single provider or a
+// list of providers under "providers". Every invalid file and provider is
+// reported at once, identified by its file and name.
+func loadProviders(dir string) ([]Provider, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no token providers found in directory: %s", dir)
+	}
+
+	var (
+		providers []Provider
+		errs      []error
+	)
+
+	// sources holds the file each provider was found in, keyed by its
+	// lowercase name as names are unique regardless of case.
+	sources := map[string]string{}
+
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(dir, f.Name())
+
+		// Handle symlinks for ConfigMaps.
+		target := path
+		if ln, err := filepath.EvalSymlinks(path); err == nil {
+			target = ln
+		}
+
+		b, err := os.ReadFile(target)
+		if err != nil {
+			// Just continue if we're not able to read the 'file' as the file might be a symlink to
+			// a dir when using kubernetes ConfigMaps, for example:
+			//
+			// drwxr-xr-x    2 root     root          4096 Oct  8 20:38 ..2020_10_08_20_38_50.434422700
+			// lrwxrwxrwx    1 root     root            31 Oct  8 20:38 ..data -> ..2020_10_08_20_38_50.434422700
+			continue
+		}
+
+		raw, err := parseProviderFile(b)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+
+			continue
+		}
+
+		for i, r := range raw {
+			p, problems := decodeProvider(r)
+
+			id := fmt.Sprintf("%d", i+1)
+			if p.Name != "" {
+				id = fmt.Sprintf("%q", p.Name)
+			}
+
+			if src, ok := sources[strings.ToLower(p.Name)]; ok && p.Name != "" {
+				problems = append(problems, fmt.Sprintf("duplicate token provider listed: %s, also in %s", p.Name, src))
+			} else if p.Name != "" {
+				sources[strings.ToLower(p.Name)] = path
+			}
+
+			for _, problem := range problems {
+				errs = append(errs, fmt.Errorf("%s: token provider %s: %s", path, id, problem))
+			}
+
+			if len(problems) == 0 {
+				providers = append(providers, p)
+			}
+		}
+	}
+
+	return providers, errors.Join(errs...)
+}
+
+// parseProviderFile returns the configuration of each provider in the
+// JSON or YAML file b.
+func parseProviderFile(b []byte) ([]json.RawMessage, error) {
+	b = bytes.TrimSpace(b)
+
+	// JSON is parsed as such, rather than as YAML, so that its errors are
+	// reported as JSON errors.
+	if !bytes.HasPrefix(b, []byte("{")) {
+		var n yaml.Node
+		if err := yaml.Unmarshal(b, &n); err != nil {
+			return nil, err
+		}
+
+		v, err := yamlValue(&n, false)
+		if err != nil {
+			return nil, err
+		}
+
+		if v == nil {
+			return nil, errors.New("no token providers found")
+		}
+
+		if _, ok := v.(map[string]any); !ok {
+			return nil, fmt.Errorf(`expected a token provider or a list of them under "providers", not %s`, jsonType(v))
+		}
+
+		b, err = json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, jsonError(err)
+	}
+
+	if _, ok := fields["providers"]; !ok {
+		return []json.RawMessage{b}, nil
+	}
+
+	if unknown := unknownFields(fields, providerFile{}); len(unknown) > 0 {
+		return nil, errors.New(strings.Join(unknown, ", "))
+	}
+
+	var f providerFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, jsonError(err)
+	}
+
+	if len(f.Providers) == 0 {
+		return nil, errors.New("no token providers found")
+	}
+
+	return f.Providers, nil
+}
+
+// stringFields holds the names of the fields of a Provider holding
+// strings or lists of strings.
+var stringFields = func() map[string]bool {
+	fields := map[string]bool{}
+
+	t := reflect.TypeFor[Provider]()
+	for i := range t.NumField() {
+		f := t.Field(i)
+
+		if f.Type.Kind() == reflect.String || (f.Type.Kind() == reflect.Slice && f.Type.Elem().Kind() == reflect.String) {
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			fields[name] = true
+		}
+	}
+
+	return fields
+}()
+
+// yamlValue returns the value of the YAML node n, as decoded into an any.
+// Scalars are returned as written if asString is set, as are the values of
+// string fields of providers, so that secrets such as 123456 or 0e12 are
+// not read as numbers.
+func yamlValue(n *yaml.Node, asString bool) (any, error) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil, nil
+		}
+
+		return yamlValue(n.Content[0], asString)
+	case yaml.AliasNode:
+		return yamlValue(n.Alias, asString)
+	case yaml.MappingNode:
+		m := map[string]any{}
+
+		var merged []map[string]any
+
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i].Value
+
+			v, err := yamlValue(n.Content[i+1], stringFields[key])
+			if err != nil {
+				return nil, err
+			}
+
+			// Merge keys, as in <<: *defaults, add the fields of other
+			// mappings not set in this one.
+			if n.Content[i].ShortTag() == "!!merge" {
+				merged = append(merged, mergedMappings(v)...)
+
+				continue
+			}
+
+			m[key] = v
+		}
+
+		for _, mm := range merged {
+			for k, v := range mm {
+				if _, ok := m[k]; !ok {
+					m[k] = v
+				}
+			}
+		}
+
+		return m, nil
+	case yaml.SequenceNode:
+		l := []any{}
+
+		for _, c := range n.Content {
+			v, err := yamlValue(c, asString)
+			if err != nil {
+				return nil, err
+			}
+
+			l = append(l, v)
+		}
+
+		return l, nil
+	}
+
+	if asString && n.ShortTag() != "!!null" {
+		return n.Value, nil
+	}
+
+	var v any
+	if err := n.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// mergedMappings returns the mappings merged by a merge key with value v,
+// either a mapping or a list of them.
+func mergedMappings(v any) []map[string]any {
+	if m, ok := v.(map[string]any); ok {
+		return []map[string]any{m}
+	}
+
+	var mappings []map[string]any
+
+	l, _ := v.([]any)
+	for _, e := range l {
+		if m, ok := e.(map[string]any); ok {
+			mappings = append(mappings, m)
+		}
+	}
+
+	return mappings
+}
+
+// jsonType returns the JSON type of v, as decoded from YAML, with an
+// article.
+func jsonType(v any) string {
+	switch v.(type) {
+	case []any:
+		return "an array"
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	}
+
+	return "a number"
+}
+
+// decodeProvider strictly decodes the configuration of a provider,
+// returning every problem with it.
+func decodeProvider(b []byte) (Provider, []string) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return Provider{}, []string{jsonError(err).Error()}
+	}
+
+	var p Provider
+
+	if err := json.Unmarshal(b, &p); err != nil {
+		// The name is still reported, if it can be decoded.
+		var named struct {
+			Name string `json:"name"`
+		}
+
+		_ = json.Unmarshal(b, &named)
+
+		return Provider{Name: named.Name}, []string{jsonError(err).Error()}
+	}
+
+	return p, append(unknownFields(fields, p), p.validate()...)
+}
+
+// unknownFields returns a problem for each field that does not match the
+// name of a field of v exactly. Unlike encoding/json, which matches names
+// regardless of case, misspellings such as loginEndPoint are reported.
+func unknownFields(fields map[string]json.RawMessage, v any) []string {
+	known := map[string]bool{}
+
+	t := reflect.TypeOf(v)
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		known[name] = true
+	}
+
+	var problems []string
+
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		if !known[name] {
+			problems = append(problems, fmt.Sprintf("unknown field %q", name))
+		}
+	}
+
+	return problems
+}
+
+// jsonError returns err without the prefix of errors from encoding/json,
+// describing values of the wrong type by their field rather than by Go
+// types.
+func jsonError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		value, _, _ := strings.Cut(typeErr.Value, " ")
+
+		return fmt.Errorf("%q must be %s, not %s", typeErr.Field, typeName(typeErr.Type), article(value))
+	}
+
+	if msg, ok := strings.CutPrefix(err.Error(), "json: "); ok {
+		return errors.New(msg)
+	}
+
+	return err
+}
+
+// typeName describes the values of type t accepted in configuration files,
+// with an article.
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "a list"
+	}
+
+	return "an object"
+}
+
+// article returns a JSON type, as named by encoding/json, with an article.
+func article(jsonType string) string {
+	switch jsonType {
+	case "array", "object":
+		return "an " + jsonType
+	case "bool":
+		return "a boolean"
+	}
+
+	return "a " + jsonType
+}
+
+// validate returns every problem with the configuration of p.
+func (p Provider) validate() []string {
+	var problems []string
+
+	if p.Name == "" {
+		problems = append(problems, `no "name" found`)
+	}
+
+	required := func(attribute, value string) {
+		if value == "" {
+			problems = append(problems, fmt.Sprintf("missing required %q attribute", attribute))
+		}
+	}
+
+	switch p.Type {
+	case ProviderTypeGoogle:
+	case ProviderTypeMicrosoft:
+		required("clientId", p.ClientID)
+		required("clientSecret", p.ClientSecret)
+		required("resource", p.Resource)
+		required("loginEndpoint", p.LoginEndpoint)
+	case ProviderTypeRancher:
+		required("username", p.Username)
+		required("password", p.Password)
+		required("url", p.URL)
+	case ProviderTypeVaultK8s:
+		required("password", p.Password)
+		required("url", p.URL)
+	default:
+		problems = append(problems, fmt.Sprintf("unsupported token provider type: %s", p.Type))
+	}
+
+	return problems
+}
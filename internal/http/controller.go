@@ -2,24 +2,35 @@ package http
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"os"
-	"path/filepath"
-	"strings"
+	"slices"
 	"time"
 
+	"github.com/homedepot/arcade/internal/audit"
+	"github.com/homedepot/arcade/internal/cache"
 	"github.com/homedepot/arcade/internal/google"
 	"github.com/homedepot/arcade/internal/vault-k8s"
 	"github.com/homedepot/arcade/internal/microsoft"
+	"github.com/homedepot/arcade/internal/ratelimit"
 	"github.com/homedepot/arcade/internal/rancher"
+	"github.com/homedepot/arcade/pkg/provider"
 )
 
 const (
 	DefaultTimeoutSeconds = 30
+	// DefaultErrorTTLSeconds is how long errors from upstream providers
+	// are cached.
+	DefaultErrorTTLSeconds = 5
+	// DefaultVaultK8sTTLSeconds is how long Vault K8s tokens are cached.
+	DefaultVaultK8sTTLSeconds = 60
 	ProviderTypeRancher   = "rancher"
 	ProviderTypeMicrosoft = "microsoft"
 	ProviderTypeGoogle     = "google"
@@ -29,6 +40,32 @@ const (
 // Controller holds clients used to grab tokens.
 type Controller struct {
 	Tokenizers map[string]Tokenizer
+	// Providers holds the configuration of each token provider, keyed
+	// by name.
+	Providers map[string]Provider
+	// RequireTokenForReadiness makes the readiness endpoint report ready
+	// only once every provider has produced a token, as recorded by
+	// TrackStatus. WarmUp requests their first tokens.
+	RequireTokenForReadiness bool
+	// Auditor records every token request. Nothing is recorded if it is
+	// nil.
+	Auditor *audit.Auditor
+	// CallerLimiter and ProviderLimiter limit the rate of token requests
+	// per caller and per provider. Requests are not limited if nil.
+	CallerLimiter   *ratelimit.Limiter
+	ProviderLimiter *ratelimit.Limiter
+	// OnRateLimited, if set, is called with the scope and key of every
+	// request rejected by a rate limit.
+	OnRateLimited func(scope, key string)
+	// DisableDefaultProvider makes token requests without a provider fail
+	// rather than default to google.
+	DisableDefaultProvider bool
+	// HeartbeatInterval is how often watches of tokens send a heartbeat,
+	// DefaultHeartbeatInterval if zero.
+	HeartbeatInterval time.Duration
+	// Done, if set, ends every watch of tokens once closed, such as when
+	// the server shuts down.
+	Done <-chan struct{}
 }
 
 // Tokenizer defines the interface for a client that can retrieve a token.
@@ -36,6 +73,12 @@ type Tokenizer interface {
 	Token(context.Context) (string, error)
 }
 
+// Revoker is implemented by Tokenizers that are able to invalidate the
+// tokens they have issued with the upstream provider.
+type Revoker interface {
+	Revoke(context.Context) error
+}
+
 // Provider defines the token provider configuration.
 type Provider struct {
 	// General config.
@@ -52,6 +95,78 @@ type Provider struct {
 	ClientSecret  string `json:"clientSecret,omitempty"`
 	Resource      string `json:"resource,omitempty"`
 	LoginEndpoint string `json:"loginEndpoint,omitempty"`
+	// Cache config, overriding the defaults of the provider type. TTLs
+	// are in seconds.
+	RefreshRatio float64 `json:"refreshRatio,omitempty"`
+	MinTTL       int     `json:"minTTL,omitempty"`
+	MaxTTL       int     `json:"maxTTL,omitempty"`
+	ErrorTTL     int     `json:"errorTTL,omitempty"`
+	// AllowedCallers lists the names of the API keys allowed to request
+	// tokens from the provider. Any caller is allowed if empty.
+	AllowedCallers []string `json:"allowedCallers,omitempty"`
+}
+
+// allows reports whether the named caller may request tokens from p.
+func (p Provider) allows(caller string) bool {
+	return len(p.AllowedCallers) == 0 || slices.Contains(p.AllowedCallers, caller)
+}
+
+// redacted returns the configuration of p without its credentials and
+// access list, which may be shown to callers.
+func (p Provider) redacted() Provider {
+	p.Username = ""
+	p.Password = ""
+	p.ClientSecret = ""
+	p.RootCA = ""
+	p.AllowedCallers = nil
+
+	return p
+}
+
+// parameters returns the request parameters accepted by p in addition to
+// its name.
+func (p Provider) parameters() []string {
+	if p.Type == ProviderTypeVaultK8s {
+		return []string{"cluster"}
+	}
+
+	return []string{}
+}
+
+// cachePolicy returns the policy used to cache the tokens of p: the
+// defaults of its type, overridden by its configuration.
+func cachePolicy(p Provider) cache.Policy {
+	policy := cache.Policy{
+		ErrorTTL: DefaultErrorTTLSeconds * time.Second,
+	}
+
+	switch p.Type {
+	case ProviderTypeGoogle, ProviderTypeMicrosoft:
+		policy.RefreshRatio = 0.9
+	case ProviderTypeRancher:
+		policy.MaxTTL = time.Duration(p.ShortExpiration) * time.Second
+	case ProviderTypeVaultK8s:
+		// Kubeconfig tokens stored in Vault carry no expiration.
+		policy.MaxTTL = DefaultVaultK8sTTLSeconds * time.Second
+	}
+
+	if p.RefreshRatio != 0 {
+		policy.RefreshRatio = p.RefreshRatio
+	}
+
+	if p.MinTTL != 0 {
+		policy.MinTTL = time.Duration(p.MinTTL) * time.Second
+	}
+
+	if p.MaxTTL != 0 {
+		policy.MaxTTL = time.Duration(p.MaxTTL) * time.Second
+	}
+
+	if p.ErrorTTL != 0 {
+		policy.ErrorTTL = time.Duration(p.ErrorTTL) * time.Second
+	}
+
+	return policy
 }
 
 var (
@@ -69,140 +184,120 @@ func NewDefaultController() (Controller, error) {
 func NewController(dir string) (Controller, error) {
 	controller := Controller{
 		Tokenizers: map[string]Tokenizer{},
+		Providers:  map[string]Provider{},
 	}
 
-	files, err := os.ReadDir(dir)
+	providers, err := loadProviders(dir)
 	if err != nil {
 		return controller, err
 	}
 
-	if len(files) == 0 {
-		return controller, fmt.Errorf("no token providers found in directory: %s", dir)
+	for _, p := range providers {
+		controller.Tokenizers[p.Name] = cache.New(newFetcher(p), cachePolicy(p))
+		controller.Providers[p.Name] = p
 	}
 
-	for _, f := range files {
-		if !f.IsDir() {
-			path := filepath.Join(dir, f.Name())
+	return controller, nil
+}
 
-			// Handle symlinks for ConfigMaps.
-			ln, err := filepath.EvalSymlinks(path)
-			if err == nil {
-				path = ln
+// newFetcher returns the Fetcher of the tokens of p, which has been
+// validated.
+func newFetcher(p Provider) provider.Fetcher {
+	switch p.Type {
+	case ProviderTypeMicrosoft:
+		client := microsoft.NewClient()
+		client.WithClientID(p.ClientID)
+		client.WithClientSecret(p.ClientSecret)
+		client.WithResource(p.Resource)
+		client.WithLoginEndpoint(p.LoginEndpoint)
+		client.WithTimeout(time.Second * DefaultTimeoutSeconds)
+
+		return client
+	case ProviderTypeRancher:
+		client := rancher.NewClient()
+		// If there's a rootCA, then add to HTTP transport
+		if p.RootCA != "" {
+			rootCAs, _ := x509.SystemCertPool()
+			if rootCAs == nil {
+				rootCAs = x509.NewCertPool()
 			}
 
-			b, err := os.ReadFile(path)
-			if err != nil {
-				// Just continue if we're not able to read the 'file' as the file might be a symlink to
-				// a dir when using kubernetes ConfigMaps, for example:
-				//
-				// drwxr-xr-x    2 root     root          4096 Oct  8 20:38 ..2020_10_08_20_38_50.434422700
-				// lrwxrwxrwx    1 root     root            31 Oct  8 20:38 ..data -> ..2020_10_08_20_38_50.434422700
-				continue
+			rootCAs.AppendCertsFromPEM([]byte(p.RootCA))
+
+			t := &http.Transport{
+				TLSClientConfig: &tls.Config{
+					RootCAs: rootCAs,
+				},
 			}
 
-			p := Provider{}
+			client.WithTransport(t)
+		}
 
-			err = json.Unmarshal(b, &p)
-			if err != nil {
-				return controller, err
-			}
+		client.WithURL(p.URL)
+		client.WithUsername(p.Username)
+		client.WithPassword(p.Password)
+		client.WithTimeout(time.Second * DefaultTimeoutSeconds)
 
-			if p.Name == "" {
-				return controller, fmt.Errorf("no \"name\" found in token provider config file %s", path)
-			}
+		return client
+	case ProviderTypeVaultK8s:
+		client := vaultk8s.NewClient()
+		client.WithPassword(p.Password)
+		client.WithURL(p.URL)
 
-			for name := range controller.Tokenizers {
-				if strings.EqualFold(p.Name, name) {
-					return controller, fmt.Errorf("duplicate token provider listed: %s", p.Name)
-				}
-			}
+		return client
+	}
 
-			t := p.Type
-			switch t {
-			case ProviderTypeGoogle:
-				client := google.NewClient()
-				controller.Tokenizers[p.Name] = client
-			case ProviderTypeMicrosoft:
-				if p.ClientID == "" {
-					return controller, fmt.Errorf("microsoft token provider file %s missing required \"clientId\" attribute", p.Name)
-				}
-
-				if p.ClientSecret == "" {
-					return controller, fmt.Errorf("microsoft token provider file %s missing required \"clientSecret\" attribute", p.Name)
-				}
-
-				if p.Resource == "" {
-					return controller, fmt.Errorf("microsoft token provider file %s missing required \"resource\" attribute", p.Name)
-				}
-
-				if p.LoginEndpoint == "" {
-					return controller, fmt.Errorf("microsoft token provider file %s missing required \"loginEndpoint\" attribute", p.Name)
-				}
-
-				client := microsoft.NewClient()
-				client.WithClientID(p.ClientID)
-				client.WithClientSecret(p.ClientSecret)
-				client.WithResource(p.Resource)
-				client.WithLoginEndpoint(p.LoginEndpoint)
-				client.WithTimeout(time.Second * DefaultTimeoutSeconds)
-				controller.Tokenizers[p.Name] = client
-			case ProviderTypeRancher:
-				if p.Username == "" {
-					return controller, fmt.Errorf("rancher token provider file %s missing required \"username\" attribute", p.Name)
-				}
-
-				if p.Password == "" {
-					return controller, fmt.Errorf("rancher token provider file %s missing required \"password\" attribute", p.Name)
-				}
-
-				if p.URL == "" {
-					return controller, fmt.Errorf("rancher token provider file %s missing required \"url\" attribute", p.Name)
-				}
-
-				client := rancher.NewClient()
-				// If there's a rootCA, then add to HTTP transport
-				if p.RootCA != "" {
-					rootCAs, _ := x509.SystemCertPool()
-					if rootCAs == nil {
-						rootCAs = x509.NewCertPool()
-					}
-
-					rootCAs.AppendCertsFromPEM([]byte(p.RootCA))
-
-					t := &http.Transport{
-						TLSClientConfig: &tls.Config{
-							RootCAs: rootCAs,
-						},
-					}
-
-					client.WithTransport(t)
-				}
-
-				client.WithURL(p.URL)
-				client.WithUsername(p.Username)
-				client.WithPassword(p.Password)
-				client.WithTimeout(time.Second * DefaultTimeoutSeconds)
-				client.WithShortExpiration(p.ShortExpiration)
-
-				controller.Tokenizers[p.Name] = client
-			case ProviderTypeVaultK8s:
-				if p.Password == "" {
-					return controller, fmt.Errorf("vault-k8s token provider file %s missing required \"password\" attribute", p.Name)
-				}
-
-				if p.URL == "" {
-					return controller, fmt.Errorf("vault-k8s token provider file %s missing required \"url\" attribute", p.Name)
-				}
-
-				client := vaultk8s.NewClient()
-				client.WithPassword(p.Password)
-				client.WithURL(p.URL)
-				controller.Tokenizers[p.Name] = client
-			default:
-				return controller, fmt.Errorf("unsupported token provider type: %s", p.Type)
-			}
+	return google.NewClient()
+}
+
+// Wrap replaces each Tokenizer with the client returned by calling wrap
+// with the provider name and the Tokenizer.
+func (ctl *Controller) Wrap(wrap func(string, provider.Client) provider.Client) {
+	for name, t := range ctl.Tokenizers {
+		ctl.Tokenizers[name] = wrap(name, t)
+	}
+}
+
+// UseStore attaches s to the cache of every provider, so that their tokens
+// are also saved to s and can be reused by another process. Tokens are
+// stored under an HMAC of their provider's configuration keyed by secret,
+// the key s encrypts tokens with, so changing the configuration, such as
+// its credentials, invalidates them without exposing it to readers of s.
+func (ctl *Controller) UseStore(s cache.Store, secret []byte) error {
+	for name, t := range ctl.Tokenizers {
+		c, ok := provider.As[*cache.Cache](t)
+		if !ok {
+			continue
 		}
+
+		b, err := json.Marshal(ctl.Providers[name])
+		if err != nil {
+			return err
+		}
+
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(b)
+		c.WithStore(s, hex.EncodeToString(mac.Sum(nil)[:16]))
 	}
 
-	return controller, nil
+	return nil
+}
+
+// RevokeTokens revokes the tokens issued by every Tokenizer that supports
+// revocation, returning all errors encountered.
+func (ctl *Controller) RevokeTokens(ctx context.Context) error {
+	var errs []error
+
+	for name, t := range ctl.Tokenizers {
+		r, ok := provider.As[Revoker](t)
+		if !ok {
+			continue
+		}
+
+		if err := r.Revoke(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
 }
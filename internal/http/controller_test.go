@@ -1,19 +1,26 @@
 package http_test
 
 import (
+	"context"
+	"errors"
 	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 
 	arcadehttp "github.com/homedepot/arcade/internal/http"
+	"github.com/homedepot/arcade/pkg/provider"
+	"github.com/homedepot/arcade/pkg/provider/providerfakes"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
 
 var _ = Describe("Controller", func() {
 	var (
-		err error
-		dir string
+		err        error
+		dir        string
+		controller arcadehttp.Controller
 	)
 
 	BeforeEach(func() {
@@ -23,7 +30,7 @@ var _ = Describe("Controller", func() {
 
 	Describe("#NewController", func() {
 		JustBeforeEach(func() {
-			_, err = arcadehttp.NewController(dir)
+			controller, err = arcadehttp.NewController(dir)
 		})
 
 		When("the directory does not exist", func() {
@@ -59,6 +66,8 @@ var _ = Describe("Controller", func() {
 
 			BeforeEach(func() {
 				tmpFile, err = os.CreateTemp("test", "cred*.json")
+				_, err = tmpFile.WriteString(`{"name": "test"`)
+				Expect(err).To(BeNil())
 			})
 
 			AfterEach(func() {
@@ -68,7 +77,25 @@ var _ = Describe("Controller", func() {
 
 			It("returns an error", func() {
 				Expect(err).ToNot(BeNil())
-				Expect(err.Error()).To(Equal("unexpected end of JSON input"))
+				Expect(err.Error()).To(Equal(tmpFile.Name() + ": unexpected end of JSON input"))
+			})
+		})
+
+		When("a file is empty", func() {
+			var tmpFile *os.File
+
+			BeforeEach(func() {
+				tmpFile, err = os.CreateTemp("test", "cred*.json")
+			})
+
+			AfterEach(func() {
+				err = os.Remove(tmpFile.Name())
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns an error", func() {
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(Equal(tmpFile.Name() + ": no token providers found"))
 			})
 		})
 
@@ -88,7 +115,7 @@ var _ = Describe("Controller", func() {
 
 			It("returns an error", func() {
 				Expect(err).ToNot(BeNil())
-				Expect(err.Error()).To(HavePrefix("no \"name\" found in token provider config file test/provider"))
+				Expect(err.Error()).To(HavePrefix(tmpFile.Name() + `: token provider 1: no "name" found`))
 			})
 		})
 
@@ -98,6 +125,7 @@ var _ = Describe("Controller", func() {
 			BeforeEach(func() {
 				tmpFile, err = os.CreateTemp("test", "provider*.json")
 				_, err = tmpFile.WriteString(`{
+					"type": "google",
 					"name": "google-test"
 				}`)
 				Expect(err).To(BeNil())
@@ -110,7 +138,7 @@ var _ = Describe("Controller", func() {
 
 			It("returns an error", func() {
 				Expect(err).ToNot(BeNil())
-				Expect(err.Error()).To(HavePrefix("duplicate token provider listed: google-test"))
+				Expect(err.Error()).To(Equal(tmpFile.Name() + `: token provider "google-test": duplicate token provider listed: google-test, also in test/google-test.json`))
 			})
 		})
 
@@ -136,7 +164,7 @@ var _ = Describe("Controller", func() {
 
 			It("returns an error", func() {
 				Expect(err).ToNot(BeNil())
-				Expect(err.Error()).To(HavePrefix(`microsoft token provider file test missing required "clientId" attribute`))
+				Expect(err.Error()).To(Equal(tmpFile.Name() + `: token provider "test": missing required "clientId" attribute`))
 			})
 		})
 
@@ -162,7 +190,7 @@ var _ = Describe("Controller", func() {
 
 			It("returns an error", func() {
 				Expect(err).ToNot(BeNil())
-				Expect(err.Error()).To(HavePrefix(`microsoft token provider file test missing required "clientSecret" attribute`))
+				Expect(err.Error()).To(Equal(tmpFile.Name() + `: token provider "test": missing required "clientSecret" attribute`))
 			})
 		})
 
@@ -188,7 +216,7 @@ var _ = Describe("Controller", func() {
 
 			It("returns an error", func() {
 				Expect(err).ToNot(BeNil())
-				Expect(err.Error()).To(HavePrefix(`microsoft token provider file test missing required "resource" attribute`))
+				Expect(err.Error()).To(Equal(tmpFile.Name() + `: token provider "test": missing required "resource" attribute`))
 			})
 		})
 
@@ -214,7 +242,7 @@ var _ = Describe("Controller", func() {
 
 			It("returns an error", func() {
 				Expect(err).ToNot(BeNil())
-				Expect(err.Error()).To(HavePrefix(`microsoft token provider file test missing required "loginEndpoint" attribute`))
+				Expect(err.Error()).To(Equal(tmpFile.Name() + `: token provider "test": missing required "loginEndpoint" attribute`))
 			})
 		})
 
@@ -239,7 +267,7 @@ var _ = Describe("Controller", func() {
 
 			It("returns an error", func() {
 				Expect(err).ToNot(BeNil())
-				Expect(err.Error()).To(HavePrefix(`rancher token provider file test missing required "username" attribute`))
+				Expect(err.Error()).To(Equal(tmpFile.Name() + `: token provider "test": missing required "username" attribute`))
 			})
 		})
 
@@ -264,7 +292,7 @@ var _ = Describe("Controller", func() {
 
 			It("returns an error", func() {
 				Expect(err).ToNot(BeNil())
-				Expect(err.Error()).To(HavePrefix(`rancher token provider file test missing required "password" attribute`))
+				Expect(err.Error()).To(Equal(tmpFile.Name() + `: token provider "test": missing required "password" attribute`))
 			})
 		})
 
@@ -289,7 +317,7 @@ var _ = Describe("Controller", func() {
 
 			It("returns an error", func() {
 				Expect(err).ToNot(BeNil())
-				Expect(err.Error()).To(HavePrefix(`rancher token provider file test missing required "url" attribute`))
+				Expect(err.Error()).To(Equal(tmpFile.Name() + `: token provider "test": missing required "url" attribute`))
 			})
 		})
 
@@ -298,5 +326,267 @@ var _ = Describe("Controller", func() {
 				Expect(err).To(BeNil())
 			})
 		})
+
+		When("a file lists many providers", func() {
+			BeforeEach(func() {
+				dir, err = os.MkdirTemp("", "arcade-providers")
+				Expect(err).ToNot(HaveOccurred())
+
+				err = os.WriteFile(filepath.Join(dir, "providers.yaml"), []byte(`
+providers:
+  - type: google
+    name: google
+  - type: rancher
+    name: rancher
+    url: https://rancher.example.com
+    username: arcade
+    password: secret
+    allowedCallers:
+      - deploy
+`), 0600)
+				Expect(err).ToNot(HaveOccurred())
+
+				err = os.WriteFile(filepath.Join(dir, "providers.json"), []byte(`{
+					"providers": [{"type": "vault-k8s", "name": "vault-k8s-np", "url": "https://vault.example.com", "password": "secret"}]
+				}`), 0600)
+				Expect(err).ToNot(HaveOccurred())
+
+				err = os.WriteFile(filepath.Join(dir, "microsoft.json"), []byte(`{
+					"type": "microsoft",
+					"name": "microsoft",
+					"clientId": "clientId",
+					"clientSecret": "clientSecret",
+					"resource": "resource",
+					"loginEndpoint": "loginEndpoint"
+				}`), 0600)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(dir)
+			})
+
+			It("loads every provider in YAML and JSON", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(controller.Tokenizers).To(HaveLen(4))
+				Expect(controller.Providers).To(HaveKey("google"))
+				Expect(controller.Providers).To(HaveKey("microsoft"))
+				Expect(controller.Providers).To(HaveKey("vault-k8s-np"))
+				Expect(controller.Providers["rancher"].URL).To(Equal("https://rancher.example.com"))
+				Expect(controller.Providers["rancher"].AllowedCallers).To(Equal([]string{"deploy"}))
+			})
+		})
+
+		When("YAML values look like numbers", func() {
+			BeforeEach(func() {
+				dir, err = os.MkdirTemp("", "arcade-providers")
+				Expect(err).ToNot(HaveOccurred())
+
+				err = os.WriteFile(filepath.Join(dir, "providers.yaml"), []byte(`
+providers:
+  - &rancher
+    type: rancher
+    name: rancher
+    url: https://rancher.example.com
+    username: 0123
+    password: 123456
+    shortExpiration: 60
+  - <<: *rancher
+    name: rancher-other
+    password: 0x1F
+  - type: microsoft
+    name: microsoft
+    clientId: 0e12
+    clientSecret: true
+    resource: 1.50
+    loginEndpoint: https://login.example.com
+    maxTTL: 600
+`), 0600)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(dir)
+			})
+
+			It("reads them as written for string fields", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(controller.Providers["rancher"].URL).To(Equal("https://rancher.example.com"))
+				Expect(controller.Providers["rancher"].Username).To(Equal("0123"))
+				Expect(controller.Providers["rancher"].Password).To(Equal("123456"))
+				Expect(controller.Providers["rancher"].ShortExpiration).To(Equal(60))
+				Expect(controller.Providers["rancher-other"].Username).To(Equal("0123"))
+				Expect(controller.Providers["rancher-other"].Password).To(Equal("0x1F"))
+				Expect(controller.Providers["microsoft"].ClientID).To(Equal("0e12"))
+				Expect(controller.Providers["microsoft"].ClientSecret).To(Equal("true"))
+				Expect(controller.Providers["microsoft"].Resource).To(Equal("1.50"))
+				Expect(controller.Providers["microsoft"].MaxTTL).To(Equal(600))
+			})
+		})
+
+		When("files have values of the wrong type", func() {
+			BeforeEach(func() {
+				dir, err = os.MkdirTemp("", "arcade-providers")
+				Expect(err).ToNot(HaveOccurred())
+
+				err = os.WriteFile(filepath.Join(dir, "a.yaml"), []byte(`
+- type: google
+  name: google
+`), 0600)
+				Expect(err).ToNot(HaveOccurred())
+
+				err = os.WriteFile(filepath.Join(dir, "b.json"), []byte(`{"type": "microsoft", "name": "microsoft", "clientId": 12}`), 0600)
+				Expect(err).ToNot(HaveOccurred())
+
+				err = os.WriteFile(filepath.Join(dir, "c.yaml"), []byte(`
+type: google
+name: google
+maxTTL: one hour
+`), 0600)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(dir)
+			})
+
+			It("reports them without Go types", func() {
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(Equal(strings.Join([]string{
+					filepath.Join(dir, "a.yaml") + `: expected a token provider or a list of them under "providers", not an array`,
+					filepath.Join(dir, "b.json") + `: token provider "microsoft": "clientId" must be a string, not a number`,
+					filepath.Join(dir, "c.yaml") + `: token provider "google": "maxTTL" must be an integer, not a string`,
+				}, "\n")))
+			})
+		})
+
+		When("files have invalid providers", func() {
+			BeforeEach(func() {
+				dir, err = os.MkdirTemp("", "arcade-providers")
+				Expect(err).ToNot(HaveOccurred())
+
+				err = os.WriteFile(filepath.Join(dir, "a.yaml"), []byte(`
+providers:
+  - type: microsoft
+    name: microsoft
+    clientId: clientId
+    clientSecret: clientSecret
+    resource: resource
+    loginEndPoint: https://login.example.com
+  - type: rancher
+    name: rancher
+    url: https://rancher.example.com
+  - type: google
+`), 0600)
+				Expect(err).ToNot(HaveOccurred())
+
+				err = os.WriteFile(filepath.Join(dir, "b.json"), []byte(`{"providers": [{"type": "google", "name": "Rancher"}], "version": 1}`), 0600)
+				Expect(err).ToNot(HaveOccurred())
+
+				err = os.WriteFile(filepath.Join(dir, "c.yaml"), []byte(`
+providers:
+  - type: okta
+    name: rancher
+`), 0600)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(dir)
+			})
+
+			It("reports every error with its file and provider", func() {
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(Equal(strings.Join([]string{
+					filepath.Join(dir, "a.yaml") + `: token provider "microsoft": unknown field "loginEndPoint"`,
+					filepath.Join(dir, "a.yaml") + `: token provider "rancher": missing required "username" attribute`,
+					filepath.Join(dir, "a.yaml") + `: token provider "rancher": missing required "password" attribute`,
+					filepath.Join(dir, "a.yaml") + `: token provider 3: no "name" found`,
+					filepath.Join(dir, "b.json") + `: unknown field "version"`,
+					filepath.Join(dir, "c.yaml") + `: token provider "rancher": unsupported token provider type: okta`,
+					filepath.Join(dir, "c.yaml") + `: token provider "rancher": duplicate token provider listed: rancher, also in ` +
+						filepath.Join(dir, "a.yaml"),
+				}, "\n")))
+			})
+		})
 	})
-})
\ No newline at end of file
+
+	Describe("#RevokeTokens", func() {
+		var (
+			controller     arcadehttp.Controller
+			revoker        *fakeRevoker
+			anotherRevoker *fakeRevoker
+		)
+
+		BeforeEach(func() {
+			revoker = &fakeRevoker{FakeClient: &providerfakes.FakeClient{}}
+			anotherRevoker = &fakeRevoker{FakeClient: &providerfakes.FakeClient{}}
+			controller = arcadehttp.Controller{
+				Tokenizers: map[string]arcadehttp.Tokenizer{
+					"google":    &providerfakes.FakeClient{},
+					"rancher-1": revoker,
+					"rancher-2": anotherRevoker,
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			err = controller.RevokeTokens(context.Background())
+		})
+
+		When("revoking a token fails", func() {
+			BeforeEach(func() {
+				revoker.err = errors.New("error revoking token")
+			})
+
+			It("revokes the remaining tokens and returns the error", func() {
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(Equal("rancher-1: error revoking token"))
+				Expect(anotherRevoker.revoked).To(BeTrue())
+			})
+		})
+
+		When("the tokenizers are wrapped", func() {
+			BeforeEach(func() {
+				controller.Wrap(func(_ string, c provider.Client) provider.Client {
+					return &wrappedClient{Client: c}
+				})
+			})
+
+			It("revokes the tokens of the wrapped revokers", func() {
+				Expect(err).To(BeNil())
+				Expect(controller.Tokenizers["rancher-1"]).To(BeAssignableToTypeOf(&wrappedClient{}))
+				Expect(revoker.revoked).To(BeTrue())
+				Expect(anotherRevoker.revoked).To(BeTrue())
+			})
+		})
+
+		When("it succeeds", func() {
+			It("revokes the tokens of each revoker", func() {
+				Expect(err).To(BeNil())
+				Expect(revoker.revoked).To(BeTrue())
+				Expect(anotherRevoker.revoked).To(BeTrue())
+			})
+		})
+	})
+})
+
+type fakeRevoker struct {
+	*providerfakes.FakeClient
+	revoked bool
+	err     error
+}
+
+func (f *fakeRevoker) Revoke(context.Context) error {
+	f.revoked = true
+
+	return f.err
+}
+
+type wrappedClient struct {
+	provider.Client
+}
+
+func (w *wrappedClient) Unwrap() provider.Client {
+	return w.Client
+}
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/homedepot/arcade/internal/ratelimit"
+)
+
+// Error codes identify the cause of an error response, in addition to its
+// human readable message.
+const (
+	ErrorCodeInvalidRequest  = "invalid_request"
+	ErrorCodeUnauthorized    = "unauthorized"
+	ErrorCodeForbidden       = "forbidden"
+	ErrorCodeUnknownProvider = "unknown_provider"
+	ErrorCodeNotFound        = "not_found"
+	ErrorCodeRateLimited     = "rate_limited"
+	ErrorCodeUpstream        = "upstream_error"
+)
+
+// Error is returned by the Controller's operations when a request fails,
+// carrying the HTTP status and error code it is responded with.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	// RetryAfter is how long a rate limited caller should wait before
+	// retrying.
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// errUnsupportedProvider is returned for requests naming a provider that
+// does not exist.
+func errUnsupportedProvider(providerName string) *Error {
+	return &Error{
+		Status:  http.StatusBadRequest,
+		Code:    ErrorCodeUnknownProvider,
+		Message: fmt.Sprintf("Unsupported token provider: %s", providerName),
+	}
+}
+
+// errForbidden is returned for requests from callers that may not request
+// tokens from the provider.
+func errForbidden(providerName string) *Error {
+	return &Error{
+		Status:  http.StatusForbidden,
+		Code:    ErrorCodeForbidden,
+		Message: fmt.Sprintf("Token provider not allowed: %s", providerName),
+	}
+}
+
+// errInvalidRequest is returned for malformed requests.
+func errInvalidRequest(message string) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: ErrorCodeInvalidRequest, Message: message}
+}
+
+// errUpstream is returned when a provider fails to produce a token.
+func errUpstream(err error) *Error {
+	return &Error{Status: http.StatusInternalServerError, Code: ErrorCodeUpstream, Message: err.Error()}
+}
+
+// respondError responds with status and an error body carrying message and
+// its code.
+func respondError(c *gin.Context, status int, code, message string) {
+	c.JSON(status, gin.H{"error": message, "code": code})
+}
+
+// abort responds with err, which is an *Error or else reported as an
+// upstream error. Providers named in the path of versioned routes are
+// resources, so they are not found rather than a bad request.
+func abort(c *gin.Context, err error) {
+	var e *Error
+	if !errors.As(err, &e) {
+		e = errUpstream(err)
+	}
+
+	status := e.Status
+	if e.Code == ErrorCodeUnknownProvider && c.Param("name") != "" {
+		status = http.StatusNotFound
+	}
+
+	if e.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(ratelimit.RetryAfter(e.RetryAfter)))
+	}
+
+	respondError(c, status, e.Code, e.Message)
+}
+
+// unsupportedProvider responds that the provider does not exist.
+func unsupportedProvider(c *gin.Context, providerName string) {
+	abort(c, errUnsupportedProvider(providerName))
+}
+
+// forbidden responds that the caller may not request tokens from the
+// provider.
+func forbidden(c *gin.Context, providerName string) {
+	abort(c, errForbidden(providerName))
+}
+
+// NotFound responds that no route matches the request.
+func (ctl *Controller) NotFound(c *gin.Context) {
+	respondError(c, http.StatusNotFound, ErrorCodeNotFound, "Not found")
+}
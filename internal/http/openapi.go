@@ -0,0 +1,18 @@
+package http
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// openAPI is the OpenAPI 3 document describing the versioned API.
+//
+//go:embed openapi.json
+var openAPI []byte
+
+// GetOpenAPI serves the OpenAPI document describing the versioned API.
+func (ctl *Controller) GetOpenAPI(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", openAPI)
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/homedepot/arcade/internal/cache"
+	"github.com/homedepot/arcade/pkg/provider"
+)
+
+// ProviderInfo describes a token provider to callers.
+type ProviderInfo struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Parameters are the request parameters accepted by the provider in
+	// addition to its name, such as the cluster of a Vault K8s provider.
+	Parameters []string `json:"parameters"`
+	// Config is the provider's configuration, without credentials.
+	Config Provider       `json:"config"`
+	Status ProviderStatus `json:"status"`
+	// CachedTokens is the number of fresh tokens cached for the provider.
+	CachedTokens int `json:"cachedTokens"`
+}
+
+// GetProviders lists the providers the caller may request tokens from,
+// sorted by name.
+func (ctl *Controller) GetProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": ctl.ListProviders(c.Request.Context())})
+}
+
+// ListProviders describes the providers the caller authenticated in ctx
+// may request tokens from, sorted by name.
+func (ctl *Controller) ListProviders(ctx context.Context) []ProviderInfo {
+	providers := []ProviderInfo{}
+
+	for name := range ctl.Tokenizers {
+		if ctl.allows(ctx, name) {
+			providers = append(providers, ctl.providerInfo(name))
+		}
+	}
+
+	sort.Slice(providers, func(i, j int) bool {
+		return providers[i].Name < providers[j].Name
+	})
+
+	return providers
+}
+
+// GetProvider describes the provider named in the path.
+func (ctl *Controller) GetProvider(c *gin.Context) {
+	name := c.Param("name")
+
+	if _, ok := ctl.Tokenizers[name]; !ok {
+		unsupportedProvider(c, name)
+
+		return
+	}
+
+	if !ctl.allows(c.Request.Context(), name) {
+		forbidden(c, name)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, ctl.providerInfo(name))
+}
+
+// providerInfo describes the named provider.
+func (ctl *Controller) providerInfo(name string) ProviderInfo {
+	p := ctl.Providers[name]
+	info := ProviderInfo{
+		Name:       name,
+		Type:       p.Type,
+		Parameters: p.parameters(),
+		Config:     p.redacted(),
+	}
+
+	info.Status, _ = ctl.providerStatus(name)
+
+	if tc, ok := provider.As[*cache.Cache](ctl.Tokenizers[name]); ok {
+		info.CachedTokens = tc.Len()
+	}
+
+	return info
+}
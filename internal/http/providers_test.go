@@ -0,0 +1,261 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/homedepot/arcade/internal/cache"
+	arcadehttp "github.com/homedepot/arcade/internal/http"
+	"github.com/homedepot/arcade/internal/middleware"
+	"github.com/homedepot/arcade/pkg/provider"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Providers", func() {
+	var providersSvr *httptest.Server
+
+	// request makes a request to the server with the given API key,
+	// returning the response status code and body.
+	request := func(uri, apiKey string) (int, []byte) {
+		r, _ := http.NewRequest(http.MethodGet, providersSvr.URL+uri, nil)
+		r.Header.Set("Api-Key", apiKey)
+
+		res, err := http.DefaultClient.Do(r)
+		Expect(err).ToNot(HaveOccurred())
+
+		defer res.Body.Close()
+
+		b, _ := io.ReadAll(res.Body)
+
+		return res.StatusCode, b
+	}
+
+	BeforeEach(func() {
+		f := provider.FetcherFunc(func(context.Context) (provider.Token, error) {
+			return provider.Token{AccessToken: "some-token", Expiry: time.Now().Add(time.Hour)}, nil
+		})
+
+		gin.SetMode(gin.ReleaseMode)
+
+		ctl := &arcadehttp.Controller{
+			Tokenizers: map[string]arcadehttp.Tokenizer{
+				"microsoft":    cache.New(f, cache.Policy{}),
+				"rancher":      cache.New(f, cache.Policy{}),
+				"vault-k8s-dv": cache.New(f, cache.Policy{}),
+			},
+			Providers: map[string]arcadehttp.Provider{
+				"microsoft": {
+					Type:          arcadehttp.ProviderTypeMicrosoft,
+					Name:          "microsoft",
+					ClientID:      "some-client-id",
+					ClientSecret:  "some-client-secret",
+					Resource:      "https://graph.microsoft.com",
+					LoginEndpoint: "https://login.microsoftonline.com/tenant/oauth2/token",
+				},
+				"rancher": {
+					Type:           arcadehttp.ProviderTypeRancher,
+					Name:           "rancher",
+					Username:       "some-user",
+					Password:       "some-password",
+					URL:            "https://rancher.example.com",
+					AllowedCallers: []string{"ci"},
+				},
+				"vault-k8s-dv": {
+					Type:     arcadehttp.ProviderTypeVaultK8s,
+					Name:     "vault-k8s-dv",
+					Password: "some-password",
+					URL:      "https://vault.example.com",
+				},
+			},
+		}
+		ctl.Wrap(arcadehttp.TrackStatus)
+
+		r := gin.New()
+		api := r.Group("/", middleware.NewAPIKeysAuth(map[string]string{
+			middleware.DefaultAPIKeyName: "default-key",
+			"ci":                         "ci-key",
+		}))
+		api.GET("/tokens", ctl.GetToken)
+		api.GET("/status", ctl.GetStatus)
+		api.GET("/providers", ctl.GetProviders)
+
+		r.GET("/v1/openapi.json", ctl.GetOpenAPI)
+
+		v1 := r.Group("/v1", middleware.NewAPIKeysAuth(map[string]string{
+			middleware.DefaultAPIKeyName: "default-key",
+			"ci":                         "ci-key",
+		}))
+		v1.GET("/providers", ctl.GetProviders)
+		v1.GET("/providers/:name", ctl.GetProvider)
+		v1.GET("/providers/:name/token", ctl.GetToken)
+		r.NoRoute(ctl.NotFound)
+
+		providersSvr = httptest.NewServer(r)
+	})
+
+	AfterEach(func() {
+		providersSvr.Close()
+	})
+
+	Describe("#GetProviders", func() {
+		It("lists the providers without their credentials", func() {
+			status, _ := request("/tokens?provider=microsoft", "default-key")
+			Expect(status).To(Equal(http.StatusOK))
+
+			status, b := request("/providers", "default-key")
+			Expect(status).To(Equal(http.StatusOK))
+
+			var res struct {
+				Providers []arcadehttp.ProviderInfo `json:"providers"`
+			}
+			Expect(json.Unmarshal(b, &res)).To(Succeed())
+			Expect(res.Providers).To(HaveLen(2))
+
+			microsoft := res.Providers[0]
+			Expect(microsoft.Name).To(Equal("microsoft"))
+			Expect(microsoft.Type).To(Equal("microsoft"))
+			Expect(microsoft.Parameters).To(BeEmpty())
+			Expect(microsoft.Config.ClientID).To(Equal("some-client-id"))
+			Expect(microsoft.Config.Resource).To(Equal("https://graph.microsoft.com"))
+			Expect(microsoft.Status.LastSuccess).ToNot(BeNil())
+			Expect(microsoft.CachedTokens).To(Equal(1))
+
+			vault := res.Providers[1]
+			Expect(vault.Name).To(Equal("vault-k8s-dv"))
+			Expect(vault.Parameters).To(Equal([]string{"cluster"}))
+			Expect(vault.Config.URL).To(Equal("https://vault.example.com"))
+
+			Expect(string(b)).ToNot(ContainSubstring("some-client-secret"))
+			Expect(string(b)).ToNot(ContainSubstring("some-password"))
+		})
+
+		When("the caller is allowed to access a restricted provider", func() {
+			It("lists it", func() {
+				_, b := request("/providers", "ci-key")
+				Expect(string(b)).To(ContainSubstring(`"name":"rancher"`))
+				Expect(string(b)).ToNot(ContainSubstring("some-user"))
+				Expect(string(b)).ToNot(ContainSubstring("allowedCallers"))
+			})
+		})
+	})
+
+	Describe("#GetToken", func() {
+		When("the caller is not allowed to access the provider", func() {
+			It("returns forbidden", func() {
+				status, b := request("/tokens?provider=rancher", "default-key")
+				Expect(status).To(Equal(http.StatusForbidden))
+				Expect(b).To(MatchJSON(`{"error":"Token provider not allowed: rancher","code":"forbidden"}`))
+			})
+		})
+
+		When("the caller is allowed to access the provider", func() {
+			It("returns the token", func() {
+				status, _ := request("/tokens?provider=rancher", "ci-key")
+				Expect(status).To(Equal(http.StatusOK))
+			})
+		})
+
+		When("the provider is named in the path", func() {
+			It("returns the token", func() {
+				status, b := request("/v1/providers/microsoft/token", "default-key")
+				Expect(status).To(Equal(http.StatusOK))
+				Expect(string(b)).To(ContainSubstring(`"token":"some-token"`))
+				Expect(string(b)).To(ContainSubstring(`"expiry"`))
+			})
+
+			It("returns not found for unknown providers", func() {
+				status, b := request("/v1/providers/google/token", "default-key")
+				Expect(status).To(Equal(http.StatusNotFound))
+				Expect(b).To(MatchJSON(`{"error":"Unsupported token provider: google","code":"unknown_provider"}`))
+			})
+
+			It("passes the cluster of Vault K8s providers", func() {
+				status, _ := request("/v1/providers/vault-k8s-dv/token?cluster=some-cluster", "default-key")
+				Expect(status).To(Equal(http.StatusOK))
+			})
+
+			It("requires the cluster of Vault K8s providers", func() {
+				status, b := request("/v1/providers/vault-k8s-dv/token", "default-key")
+				Expect(status).To(Equal(http.StatusBadRequest))
+				Expect(b).To(MatchJSON(`{"error":"A cluster is required for token provider: vault-k8s-dv","code":"invalid_request"}`))
+			})
+		})
+
+		When("the API key is wrong", func() {
+			It("returns an error code", func() {
+				status, b := request("/v1/providers/microsoft/token", "wrong-key")
+				Expect(status).To(Equal(http.StatusForbidden))
+				Expect(b).To(MatchJSON(`{"error":"bad api key","code":"unauthorized"}`))
+			})
+		})
+	})
+
+	Describe("#GetProvider", func() {
+		It("describes the provider", func() {
+			status, b := request("/v1/providers/vault-k8s-dv", "default-key")
+			Expect(status).To(Equal(http.StatusOK))
+
+			var info arcadehttp.ProviderInfo
+			Expect(json.Unmarshal(b, &info)).To(Succeed())
+			Expect(info.Name).To(Equal("vault-k8s-dv"))
+			Expect(info.Parameters).To(Equal([]string{"cluster"}))
+			Expect(string(b)).ToNot(ContainSubstring("some-password"))
+		})
+
+		When("the caller is not allowed to access the provider", func() {
+			It("returns forbidden", func() {
+				status, _ := request("/v1/providers/rancher", "default-key")
+				Expect(status).To(Equal(http.StatusForbidden))
+			})
+		})
+
+		When("the provider does not exist", func() {
+			It("returns not found", func() {
+				status, _ := request("/v1/providers/google", "default-key")
+				Expect(status).To(Equal(http.StatusNotFound))
+			})
+		})
+	})
+
+	Describe("#GetOpenAPI", func() {
+		It("serves the OpenAPI document without an API key", func() {
+			status, b := request("/v1/openapi.json", "")
+			Expect(status).To(Equal(http.StatusOK))
+
+			var doc struct {
+				OpenAPI string                    `json:"openapi"`
+				Paths   map[string]map[string]any `json:"paths"`
+			}
+			Expect(json.Unmarshal(b, &doc)).To(Succeed())
+			Expect(doc.OpenAPI).To(HavePrefix("3."))
+			Expect(doc.Paths).To(HaveKey("/providers"))
+			Expect(doc.Paths).To(HaveKey("/providers/{name}"))
+			Expect(doc.Paths["/providers/{name}/token"]).To(HaveKey("get"))
+			Expect(doc.Paths["/providers/{name}/token/watch"]).To(HaveKey("get"))
+		})
+	})
+
+	Describe("#NotFound", func() {
+		It("returns an error code", func() {
+			status, b := request("/v1/tokens", "default-key")
+			Expect(status).To(Equal(http.StatusNotFound))
+			Expect(b).To(MatchJSON(`{"error":"Not found","code":"not_found"}`))
+		})
+	})
+
+	Describe("#GetStatus", func() {
+		It("omits providers the caller may not access", func() {
+			_, b := request("/status", "default-key")
+			Expect(string(b)).ToNot(ContainSubstring("rancher"))
+
+			status, _ := request("/status?provider=rancher", "default-key")
+			Expect(status).To(Equal(http.StatusForbidden))
+		})
+	})
+})
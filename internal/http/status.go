@@ -0,0 +1,255 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/homedepot/arcade/internal/cache"
+	"github.com/homedepot/arcade/internal/logging"
+	"github.com/homedepot/arcade/pkg/provider"
+)
+
+const (
+	// warmUpMinBackoff and warmUpMaxBackoff bound how long WarmUp waits
+	// before retrying providers that failed to produce a token.
+	warmUpMinBackoff = time.Second
+	warmUpMaxBackoff = time.Minute
+)
+
+// ProviderStatus describes the most recent token requests of a provider.
+type ProviderStatus struct {
+	// LastSuccess and LastError describe the most recent requests for a
+	// new token made to the upstream provider, not those served from
+	// cache.
+	LastSuccess *time.Time `json:"lastSuccess,omitempty"`
+	LastError   string     `json:"lastError,omitempty"`
+	LastErrorAt *time.Time `json:"lastErrorAt,omitempty"`
+	// Expiry is when the provider's most recently returned token expires.
+	Expiry *time.Time `json:"expiry,omitempty"`
+}
+
+// TrackStatus wraps a token provider client to record the outcome of its
+// token requests, which are reported by the status and readiness
+// endpoints.
+func TrackStatus(_ string, c provider.Client) provider.Client {
+	return &statusClient{client: c}
+}
+
+type statusClient struct {
+	client provider.Client
+	mux    sync.Mutex
+	status ProviderStatus
+	// produced is whether the client has returned a token, including one
+	// loaded from a cache store rather than fetched.
+	produced bool
+}
+
+func (s *statusClient) Token(ctx context.Context) (string, error) {
+	refreshed := false
+
+	ctx = provider.WithTrace(ctx, &provider.Trace{
+		RefreshDone: func(info provider.RefreshInfo) {
+			refreshed = true
+
+			// Refreshes shared between requests are recorded once.
+			if info.Shared {
+				return
+			}
+
+			s.mux.Lock()
+			defer s.mux.Unlock()
+
+			now := time.Now().In(time.UTC)
+			if info.Err != nil {
+				s.status.LastError = info.Err.Error()
+				s.status.LastErrorAt = &now
+
+				return
+			}
+
+			s.status.LastSuccess = &now
+			s.status.Expiry = timePtr(info.Expiry)
+		},
+	})
+
+	t, err := s.client.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.produced = true
+
+	// Tokens served without a refresh may have been loaded from a cache
+	// store, so their expiry is looked up in the cache.
+	if !refreshed {
+		if c, ok := provider.As[*cache.Cache](s.client); ok {
+			if e, ok := c.Lookup(ctx); ok && e.Token == t {
+				s.status.Expiry = timePtr(e.Expiry)
+			}
+		}
+	}
+
+	return t, nil
+}
+
+// timePtr returns a pointer to t, or nil if t is zero.
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+func (s *statusClient) Unwrap() provider.Client {
+	return s.client
+}
+
+// Status returns a copy of the recorded status.
+func (s *statusClient) Status() ProviderStatus {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.status
+}
+
+// Produced returns whether the client has returned a token.
+func (s *statusClient) Produced() bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.produced
+}
+
+// providerStatus returns the status of the named provider, and whether its
+// status is being tracked.
+func (ctl *Controller) providerStatus(name string) (ProviderStatus, bool) {
+	s, ok := provider.As[*statusClient](ctl.Tokenizers[name])
+	if !ok {
+		return ProviderStatus{}, false
+	}
+
+	return s.Status(), true
+}
+
+// produced returns whether the named provider has returned a token, as
+// recorded by TrackStatus.
+func (ctl *Controller) produced(name string) bool {
+	s, ok := provider.As[*statusClient](ctl.Tokenizers[name])
+
+	return ok && s.Produced()
+}
+
+// GetStatus returns the status of every provider the caller may access,
+// or of a single provider if one is given.
+func (ctl *Controller) GetStatus(c *gin.Context) {
+	statuses := map[string]ProviderStatus{}
+
+	ctx := c.Request.Context()
+
+	if providerName := c.Query("provider"); providerName != "" {
+		if _, ok := ctl.Tokenizers[providerName]; !ok {
+			unsupportedProvider(c, providerName)
+
+			return
+		}
+
+		if !ctl.allows(ctx, providerName) {
+			forbidden(c, providerName)
+
+			return
+		}
+
+		statuses[providerName], _ = ctl.providerStatus(providerName)
+	} else {
+		for name := range ctl.Tokenizers {
+			if ctl.allows(ctx, name) {
+				statuses[name], _ = ctl.providerStatus(name)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"providers": statuses})
+}
+
+// Healthz reports that the process is up.
+func (ctl *Controller) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz reports whether arcade is ready to serve tokens. If
+// RequireTokenForReadiness is set, every provider that can be queried
+// without request parameters must have produced a token at least once, as
+// recorded by TrackStatus. Readyz is unauthenticated, so it never requests
+// tokens itself; WarmUp requests them. The response never contains tokens
+// or provider details.
+func (ctl *Controller) Readyz(c *gin.Context) {
+	if ctl.RequireTokenForReadiness {
+		for _, name := range ctl.readinessProviders() {
+			if !ctl.produced(name) {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready"})
+
+				return
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ready"})
+}
+
+// WarmUp requests a token from every provider Readyz waits for that has
+// not yet produced one, retrying those that fail with backoff until each
+// has produced a token or ctx is done.
+func (ctl *Controller) WarmUp(ctx context.Context) {
+	backoff := warmUpMinBackoff
+
+	for {
+		failed := false
+
+		for _, name := range ctl.readinessProviders() {
+			if ctl.produced(name) {
+				continue
+			}
+
+			if _, err := ctl.Tokenizers[name].Token(ctx); err != nil {
+				slog.WarnContext(ctx, "error warming up token", slog.String(logging.KeyProvider, name), slog.Any("error", err))
+
+				failed = true
+			}
+		}
+
+		if !failed {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(2*backoff, warmUpMaxBackoff)
+	}
+}
+
+// readinessProviders returns the names of the providers Readyz waits for.
+// Vault K8s tokens are per cluster and cannot be requested without one,
+// so Vault K8s providers are skipped.
+func (ctl *Controller) readinessProviders() []string {
+	var names []string
+
+	for name := range ctl.Tokenizers {
+		if ctl.Providers[name].Type != ProviderTypeVaultK8s {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
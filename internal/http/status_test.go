@@ -0,0 +1,281 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/homedepot/arcade/internal/cache"
+	arcadehttp "github.com/homedepot/arcade/internal/http"
+	"github.com/homedepot/arcade/pkg/provider"
+	"github.com/homedepot/arcade/pkg/provider/providerfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Status", func() {
+	var (
+		svr             *httptest.Server
+		res             *http.Response
+		uri             string
+		googleFetches   int
+		googleErr       error
+		googleCache     *cache.Cache
+		fakeVaultClient *providerfakes.FakeClient
+		controller      *arcadehttp.Controller
+		body            map[string]interface{}
+	)
+
+	BeforeEach(func() {
+		googleFetches = 0
+		googleErr = nil
+		googleCache = cache.New(provider.FetcherFunc(func(context.Context) (provider.Token, error) {
+			googleFetches++
+
+			if googleErr != nil {
+				return provider.Token{}, googleErr
+			}
+
+			return provider.Token{AccessToken: "valid-google-token", Expiry: time.Now().Add(time.Hour)}, nil
+		}), cache.Policy{})
+		fakeVaultClient = &providerfakes.FakeClient{}
+		fakeVaultClient.TokenReturns("", errors.New("cluster name not found in context"))
+		gin.SetMode(gin.ReleaseMode)
+		controller = &arcadehttp.Controller{
+			Tokenizers: map[string]arcadehttp.Tokenizer{
+				"google":       googleCache,
+				"vault-k8s-dv": fakeVaultClient,
+			},
+			Providers: map[string]arcadehttp.Provider{
+				"google":       {Name: "google", Type: arcadehttp.ProviderTypeGoogle},
+				"vault-k8s-dv": {Name: "vault-k8s-dv", Type: arcadehttp.ProviderTypeVaultK8s},
+			},
+		}
+		controller.Wrap(arcadehttp.TrackStatus)
+
+		r := gin.New()
+		r.GET("/healthz", controller.Healthz)
+		r.GET("/readyz", controller.Readyz)
+		r.GET("/status", controller.GetStatus)
+		r.GET("/tokens", controller.GetToken)
+
+		svr = httptest.NewServer(r)
+		body = nil
+	})
+
+	AfterEach(func() {
+		svr.Close()
+	})
+
+	JustBeforeEach(func() {
+		var err error
+
+		res, err = http.Get(uri)
+		Expect(err).ToNot(HaveOccurred())
+
+		defer res.Body.Close()
+
+		b, _ := io.ReadAll(res.Body)
+		_ = json.Unmarshal(b, &body)
+	})
+
+	Describe("#Healthz", func() {
+		BeforeEach(func() {
+			uri = svr.URL + "/healthz"
+		})
+
+		It("succeeds", func() {
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+			Expect(body["status"]).To(Equal("ok"))
+		})
+	})
+
+	Describe("#Readyz", func() {
+		BeforeEach(func() {
+			uri = svr.URL + "/readyz"
+		})
+
+		When("tokens are not required for readiness", func() {
+			BeforeEach(func() {
+				googleErr = errors.New("error getting token from google")
+			})
+
+			It("is ready without requesting tokens", func() {
+				Expect(res.StatusCode).To(Equal(http.StatusOK))
+				Expect(body["status"]).To(Equal("ready"))
+				Expect(googleFetches).To(Equal(0))
+			})
+		})
+
+		When("tokens are required for readiness", func() {
+			BeforeEach(func() {
+				controller.RequireTokenForReadiness = true
+			})
+
+			When("no provider has produced a token yet", func() {
+				It("is not ready without requesting tokens", func() {
+					Expect(res.StatusCode).To(Equal(http.StatusServiceUnavailable))
+					Expect(googleFetches).To(Equal(0))
+				})
+			})
+
+			When("a provider fails to produce a token", func() {
+				BeforeEach(func() {
+					googleErr = errors.New("error getting token from google")
+
+					ctx, cancel := context.WithCancel(context.Background())
+					cancel()
+					controller.WarmUp(ctx)
+				})
+
+				It("is not ready and reveals no details", func() {
+					Expect(res.StatusCode).To(Equal(http.StatusServiceUnavailable))
+					Expect(body).To(Equal(map[string]interface{}{"status": "not ready"}))
+					Expect(googleFetches).To(Equal(1))
+				})
+			})
+
+			When("every provider has produced a token", func() {
+				BeforeEach(func() {
+					controller.WarmUp(context.Background())
+				})
+
+				It("is ready and skips providers requiring parameters", func() {
+					Expect(res.StatusCode).To(Equal(http.StatusOK))
+					Expect(googleFetches).To(Equal(1))
+					Expect(fakeVaultClient.TokenCallCount()).To(Equal(0))
+
+					_, err := http.Get(uri)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(googleFetches).To(Equal(1))
+				})
+			})
+
+			When("a provider's token is loaded from the cache store", func() {
+				var dir string
+
+				BeforeEach(func() {
+					var err error
+
+					dir, err = os.MkdirTemp("", "arcade-status")
+					Expect(err).ToNot(HaveOccurred())
+
+					store, err := cache.NewFileStore(filepath.Join(dir, "tokens"), []byte("test-secret"))
+					Expect(err).ToNot(HaveOccurred())
+
+					other := cache.New(provider.FetcherFunc(func(context.Context) (provider.Token, error) {
+						return provider.Token{AccessToken: "stored-google-token", Expiry: time.Now().Add(time.Hour)}, nil
+					}), cache.Policy{})
+					other.WithStore(store, "google")
+					_, err = other.Token(context.Background())
+					Expect(err).ToNot(HaveOccurred())
+
+					googleCache.WithStore(store, "google")
+					controller.WarmUp(context.Background())
+				})
+
+				AfterEach(func() {
+					os.RemoveAll(dir)
+				})
+
+				It("is ready without fetching a token and reports its expiry", func() {
+					Expect(res.StatusCode).To(Equal(http.StatusOK))
+					Expect(googleFetches).To(Equal(0))
+
+					r, err := http.Get(svr.URL + "/status?provider=google")
+					Expect(err).ToNot(HaveOccurred())
+
+					defer r.Body.Close()
+
+					var status struct {
+						Providers map[string]arcadehttp.ProviderStatus `json:"providers"`
+					}
+					Expect(json.NewDecoder(r.Body).Decode(&status)).To(Succeed())
+					Expect(status.Providers["google"].LastSuccess).To(BeNil())
+					Expect(status.Providers["google"].Expiry).ToNot(BeNil())
+					Expect(*status.Providers["google"].Expiry).To(BeTemporally("~", time.Now().Add(time.Hour), time.Second))
+				})
+			})
+		})
+	})
+
+	Describe("#GetStatus", func() {
+		BeforeEach(func() {
+			_, err := http.Get(svr.URL + "/tokens?provider=google")
+			Expect(err).ToNot(HaveOccurred())
+			uri = svr.URL + "/status"
+		})
+
+		When("the provider is not supported", func() {
+			BeforeEach(func() {
+				uri = svr.URL + "/status?provider=fake"
+			})
+
+			It("returns a bad request error", func() {
+				Expect(res.StatusCode).To(Equal(http.StatusBadRequest))
+				Expect(body["error"]).To(Equal("Unsupported token provider: fake"))
+			})
+		})
+
+		When("a provider is given", func() {
+			BeforeEach(func() {
+				uri = svr.URL + "/status?provider=google"
+			})
+
+			It("returns the status of that provider", func() {
+				Expect(res.StatusCode).To(Equal(http.StatusOK))
+				providers := body["providers"].(map[string]interface{})
+				Expect(providers).To(HaveLen(1))
+				Expect(providers["google"]).To(HaveKey("lastSuccess"))
+			})
+		})
+
+		When("it succeeds", func() {
+			It("returns the status of every provider", func() {
+				Expect(res.StatusCode).To(Equal(http.StatusOK))
+				providers := body["providers"].(map[string]interface{})
+				Expect(providers).To(HaveLen(2))
+				Expect(providers["google"]).To(HaveKey("lastSuccess"))
+				Expect(providers["google"]).ToNot(HaveKey("lastError"))
+				Expect(providers["google"]).To(HaveKey("expiry"))
+				Expect(providers["vault-k8s-dv"]).To(BeEmpty())
+			})
+		})
+
+		When("the upstream fails while a token is cached", func() {
+			var lastSuccess interface{}
+
+			BeforeEach(func() {
+				r, err := http.Get(uri)
+				Expect(err).ToNot(HaveOccurred())
+
+				var status map[string]interface{}
+				Expect(json.NewDecoder(r.Body).Decode(&status)).To(Succeed())
+				r.Body.Close()
+				lastSuccess = status["providers"].(map[string]interface{})["google"].(map[string]interface{})["lastSuccess"]
+
+				_, err = http.Get(svr.URL + "/tokens?provider=google")
+				Expect(err).ToNot(HaveOccurred())
+
+				googleErr = errors.New("error getting token from google")
+				_, err = controller.Tokenizers["google"].Token(provider.WithNoCache(context.Background()))
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("records the upstream error but not the tokens served from cache", func() {
+				providers := body["providers"].(map[string]interface{})
+				Expect(googleFetches).To(Equal(2))
+				Expect(providers["google"]).To(HaveKeyWithValue("lastSuccess", lastSuccess))
+				Expect(providers["google"]).To(HaveKeyWithValue("lastError", "error getting token from google"))
+				Expect(providers["google"]).To(HaveKey("lastErrorAt"))
+			})
+		})
+	})
+})
@@ -2,59 +2,378 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/homedepot/arcade/internal/audit"
+	"github.com/homedepot/arcade/internal/cache"
+	"github.com/homedepot/arcade/internal/logging"
+	"github.com/homedepot/arcade/internal/middleware"
 	"github.com/homedepot/arcade/pkg/provider"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
 	lifecycleWithDash    = 3 // "-XX" = dash + 2-char lifecycle code
+
+	RateLimitScopeCaller   = "caller"
+	RateLimitScopeProvider = "provider"
+	RateLimitScopeRefresh  = "refresh"
 )
 
-// GetToken returns a new access token for a given provider.
+// GetToken returns a new access token for a given provider, along with
+// its expiry if known.
 func (ctl *Controller) GetToken(c *gin.Context) {
-	providerName := c.Query("provider")
-	if providerName == "" {
-		providerName = "google"
-	}
-
-	if len(providerName) >= ( len(ProviderTypeVaultK8s) + lifecycleWithDash ) {
-		if providerName[0:len(ProviderTypeVaultK8s)] == ProviderTypeVaultK8s {
-			tokenizer, ok := ctl.Tokenizers[providerName[0:len(ProviderTypeVaultK8s) + lifecycleWithDash]]
-			if !ok {
-				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unsupported token provider: %s", providerName)})
-				return
-			}
-
-			ctx := context.WithValue(c.Request.Context(), provider.ProviderKey, providerName)
-			t, err := tokenizer.Token(ctx)
-			
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-				return
-			}
-
-			c.JSON(http.StatusOK, gin.H{"token": t})
-
-			return
+	t, err := ctl.Token(c.Request.Context(), TokenRequest{
+		Provider:      providerName(c),
+		NoCache:       noCache(c),
+		SourceAddress: c.ClientIP(),
+	})
+	if err != nil {
+		abort(c, err)
+
+		return
+	}
+
+	res := gin.H{"token": t.Token}
+	if !t.Expiry.IsZero() {
+		res["expiry"] = t.Expiry.UTC().Format(time.RFC3339)
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// TokenRequest is a request for a token from a provider.
+type TokenRequest struct {
+	// Provider is the name of the provider, followed by the cluster of
+	// Vault K8s providers. The google provider is used if empty, unless
+	// the default provider is disabled.
+	Provider string
+	// NoCache requests a new token rather than one from cache.
+	NoCache bool
+	// SourceAddress is the address of the caller, recorded by the audit
+	// log.
+	SourceAddress string
+}
+
+// TokenResponse is a token returned by a provider.
+type TokenResponse struct {
+	Token string
+	// Expiry is when the token expires, or zero if unknown.
+	Expiry time.Time
+}
+
+// Token returns a token for the caller authenticated in ctx, enforcing
+// rate limits and the provider's access list, and recording the request
+// in the audit log. Failed requests return an *Error.
+func (ctl *Controller) Token(ctx context.Context, req TokenRequest) (TokenResponse, error) {
+	providerName, err := ctl.providerOrDefault(req.Provider)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	event := audit.Event{
+		Time:          time.Now().In(time.UTC),
+		RequestID:     logging.RequestID(ctx),
+		Caller:        middleware.CallerFromContext(ctx),
+		SourceAddress: req.SourceAddress,
+		Provider:      providerName,
+		Outcome:       audit.OutcomeUnsupportedProvider,
+	}
+	defer func() { ctl.Auditor.Record(event) }()
+
+	ctx, tokenizerName, tokenizer, err := ctl.authorize(ctx, providerName, &event)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	if req.NoCache {
+		ctx = provider.WithNoCache(ctx)
+	}
+
+	t, err := token(ctx, providerName, tokenizer, &event)
+	if errors.Is(err, provider.ErrRefreshLimited) {
+		return TokenResponse{}, ctl.rateLimited(ctx, &event, RateLimitScopeRefresh, tokenizerName, time.Second)
+	}
+
+	if err != nil {
+		return TokenResponse{}, errUpstream(err)
+	}
+
+	res := TokenResponse{Token: t}
+	if tc, ok := provider.As[*cache.Cache](tokenizer); ok {
+		res.Expiry, _ = tc.Expiry(ctx, t)
+	}
+
+	return res, nil
+}
+
+// authorize returns the Tokenizer serving providerName, its name and the
+// context to request its token with, enforcing rate limits and the
+// provider's access list for the caller authenticated in ctx. The cluster
+// and outcome are recorded in event. Failed requests return an *Error.
+func (ctl *Controller) authorize(ctx context.Context, providerName string, event *audit.Event) (context.Context, string, Tokenizer, error) {
+	if ok, retryAfter := ctl.CallerLimiter.Allow(event.Caller); !ok {
+		return ctx, "", nil, ctl.rateLimited(ctx, event, RateLimitScopeCaller, event.Caller, retryAfter)
+	}
+
+	ctx, tokenizerName, cluster := resolve(ctx, providerName)
+	event.Cluster = cluster
+
+	if err := ctl.CheckAccess(event.Caller, providerName); err != nil {
+		var e *Error
+		if errors.As(err, &e) && e.Code == ErrorCodeForbidden {
+			event.Outcome = audit.OutcomeForbidden
 		}
+
+		return ctx, "", nil, err
+	}
+
+	if ok, retryAfter := ctl.ProviderLimiter.Allow(tokenizerName); !ok {
+		return ctx, "", nil, ctl.rateLimited(ctx, event, RateLimitScopeProvider, tokenizerName, retryAfter)
+	}
+
+	return ctx, tokenizerName, ctl.Tokenizers[tokenizerName], nil
+}
+
+// CheckAccess returns an *Error if caller may not request tokens from the
+// named provider, as it does not exist, requires a cluster or does not
+// allow caller. Rate limits are not checked.
+func (ctl *Controller) CheckAccess(caller, providerName string) error {
+	if err := ctl.checkProvider(providerName); err != nil {
+		return err
+	}
+
+	_, tokenizerName, _ := resolve(context.Background(), providerName)
+
+	if !ctl.Providers[tokenizerName].allows(caller) {
+		return errForbidden(providerName)
+	}
+
+	return nil
+}
+
+// checkProvider returns an *Error if tokens cannot be requested from the
+// named provider, as it does not exist or requires a cluster.
+func (ctl *Controller) checkProvider(providerName string) error {
+	_, tokenizerName, cluster := resolve(context.Background(), providerName)
+
+	if _, ok := ctl.Tokenizers[tokenizerName]; !ok {
+		return errUnsupportedProvider(providerName)
+	}
+
+	if ctl.Providers[tokenizerName].Type == ProviderTypeVaultK8s && cluster == "" {
+		return errInvalidRequest(fmt.Sprintf("A cluster is required for token provider: %s", providerName))
+	}
+
+	return nil
+}
+
+// ReportInvalidToken handles a caller reporting that a token of a given
+// provider was rejected, identified by its fingerprint. If that token is
+// still cached it is removed and a new one is fetched, so that subsequent
+// requests do not return it. Reports are subject to the same rate limits
+// and access lists as token requests, and are recorded in the audit log.
+func (ctl *Controller) ReportInvalidToken(c *gin.Context) {
+	providerName, err := ctl.providerOrDefault(providerName(c))
+	if err != nil {
+		abort(c, err)
+
+		return
+	}
+
+	var report struct {
+		Fingerprint string `json:"fingerprint"`
 	}
 
-	tokenizer, ok := ctl.Tokenizers[providerName]
-	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unsupported token provider: %s", providerName)})
+	if err := c.ShouldBindJSON(&report); err != nil || report.Fingerprint == "" {
+		abort(c, errInvalidRequest("A token fingerprint is required"))
 
 		return
 	}
 
-	t, err := tokenizer.Token(context.Background())
+	ctx := c.Request.Context()
+
+	event := audit.Event{
+		Time:             time.Now().In(time.UTC),
+		RequestID:        logging.RequestID(ctx),
+		Caller:           middleware.CallerFromContext(ctx),
+		SourceAddress:    c.ClientIP(),
+		Action:           audit.ActionReportInvalid,
+		Provider:         providerName,
+		Outcome:          audit.OutcomeUnsupportedProvider,
+		TokenFingerprint: report.Fingerprint,
+	}
+	defer func() { ctl.Auditor.Record(event) }()
+
+	ctx, tokenizerName, tokenizer, err := ctl.authorize(ctx, providerName, &event)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		abort(c, err)
 
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": t})
+	logging.AddAttrs(ctx, slog.String(logging.KeyProvider, providerName))
+
+	event.Outcome = audit.OutcomeSuccess
+
+	tc, ok := provider.As[*cache.Cache](tokenizer)
+	if !ok || !tc.Reject(ctx, func(t string) bool { return audit.Fingerprint(t) == report.Fingerprint }) {
+		slog.InfoContext(ctx, "reported token is not cached")
+		c.Status(http.StatusNoContent)
+
+		return
+	}
+
+	slog.WarnContext(ctx, "cached token reported invalid", slog.String("fingerprint", report.Fingerprint))
+
+	_, err = tokenizer.Token(ctx)
+	if errors.Is(err, provider.ErrRefreshLimited) {
+		abort(c, ctl.rateLimited(ctx, &event, RateLimitScopeRefresh, tokenizerName, time.Second))
+
+		return
+	}
+
+	if err != nil {
+		slog.ErrorContext(ctx, "error refreshing token", slog.Any("error", err))
+
+		event.Outcome = audit.OutcomeError
+
+		abort(c, errUpstream(err))
+
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// providerName returns the name of the provider a request is for: the name
+// in the path of versioned routes, followed by the cluster parameter of
+// Vault K8s providers, or else the provider query parameter.
+func providerName(c *gin.Context) string {
+	name := c.Param("name")
+	if name == "" {
+		return c.Query("provider")
+	}
+
+	if cluster := c.Query("cluster"); cluster != "" {
+		return name + "-" + cluster
+	}
+
+	return name
+}
+
+// providerOrDefault returns name, or google if it is empty unless the
+// default provider is disabled.
+func (ctl *Controller) providerOrDefault(name string) (string, error) {
+	if name != "" {
+		return name, nil
+	}
+
+	if ctl.DisableDefaultProvider {
+		return "", errInvalidRequest("A token provider is required")
+	}
+
+	return ProviderTypeGoogle, nil
+}
+
+// resolve returns the name of the Tokenizer serving providerName, the
+// context to request its token with and, for Vault K8s providers, the
+// cluster. Vault K8s providers are named after a cluster, as in
+// vault-k8s-XX-cluster, and share the tokenizer named vault-k8s-XX.
+func resolve(ctx context.Context, providerName string) (context.Context, string, string) {
+	if len(providerName) < ( len(ProviderTypeVaultK8s) + lifecycleWithDash ) ||
+		providerName[0:len(ProviderTypeVaultK8s)] != ProviderTypeVaultK8s {
+		return ctx, providerName, ""
+	}
+
+	tokenizerName := providerName[0:len(ProviderTypeVaultK8s) + lifecycleWithDash]
+
+	var cluster string
+	if len(providerName) > len(tokenizerName)+1 {
+		cluster = providerName[len(tokenizerName)+1:]
+	}
+
+	return context.WithValue(ctx, provider.ProviderKey, providerName), tokenizerName, cluster
+}
+
+// allows reports whether the caller authenticated in ctx may request tokens
+// from the named provider.
+func (ctl *Controller) allows(ctx context.Context, name string) bool {
+	return ctl.Providers[name].allows(middleware.CallerFromContext(ctx))
+}
+
+// noCache reports whether the request asks not to be served from cache
+// with a Cache-Control: no-cache header.
+func noCache(c *gin.Context) bool {
+	for _, directive := range strings.Split(c.GetHeader("Cache-Control"), ",") {
+		if strings.EqualFold(strings.TrimSpace(directive), "no-cache") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rateLimited returns the error for a request rejected by the rate limit
+// of the given scope and key, asking the caller to retry after retryAfter.
+func (ctl *Controller) rateLimited(ctx context.Context, event *audit.Event, scope, key string, retryAfter time.Duration) error {
+	event.Outcome = audit.OutcomeRateLimited
+
+	if ctl.OnRateLimited != nil {
+		ctl.OnRateLimited(scope, key)
+	}
+
+	slog.WarnContext(ctx, "request rate limited", slog.String("scope", scope))
+
+	return &Error{
+		Status:     http.StatusTooManyRequests,
+		Code:       ErrorCodeRateLimited,
+		Message:    "Too many requests",
+		RetryAfter: retryAfter,
+	}
+}
+
+// token requests a token from tokenizer, adding the provider name and
+// whether the token came from cache to the log attributes of ctx and to
+// the audit event. The provider name is also added to the current span.
+func token(ctx context.Context, providerName string, tokenizer Tokenizer, event *audit.Event) (string, error) {
+	logging.AddAttrs(ctx, slog.String(logging.KeyProvider, providerName))
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("arcade.provider", providerName))
+
+	refreshed := false
+	ctx = provider.WithTrace(ctx, &provider.Trace{
+		RefreshStart: func() {
+			refreshed = true
+		},
+	})
+
+	t, err := tokenizer.Token(ctx)
+
+	switch {
+	case refreshed:
+		logging.AddAttrs(ctx, slog.String(logging.KeyCache, "miss"))
+	case err == nil:
+		logging.AddAttrs(ctx, slog.String(logging.KeyCache, "hit"))
+	}
+
+	if err != nil {
+		slog.ErrorContext(ctx, "error getting token", slog.Any("error", err))
+
+		event.Outcome = audit.OutcomeError
+
+		return t, err
+	}
+
+	event.Outcome = audit.OutcomeSuccess
+	event.CacheHit = !refreshed
+	event.TokenFingerprint = audit.Fingerprint(t)
+
+	return t, nil
 }
@@ -2,6 +2,7 @@ package http_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -9,7 +10,10 @@ import (
 	"net/http/httptest"
 
 	"github.com/gin-gonic/gin"
+	"github.com/homedepot/arcade/internal/audit"
 	arcadehttp "github.com/homedepot/arcade/internal/http"
+	"github.com/homedepot/arcade/internal/ratelimit"
+	"github.com/homedepot/arcade/pkg/provider"
 	"github.com/homedepot/arcade/pkg/provider/providerfakes"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -116,6 +120,19 @@ var _ = Describe("Token", func() {
 				_ = json.Unmarshal(b, &tokens)
 				Expect(tokens.Token).To(Equal("valid-google-token"))
 			})
+
+			When("the default provider is disabled", func() {
+				BeforeEach(func() {
+					controller.DisableDefaultProvider = true
+				})
+
+				It("returns a bad request error", func() {
+					Expect(res.StatusCode).To(Equal(http.StatusBadRequest))
+					b, _ := io.ReadAll(res.Body)
+					Expect(b).To(MatchJSON(`{"error":"A token provider is required","code":"invalid_request"}`))
+					Expect(fakeGoogleClient.TokenCallCount()).To(BeZero())
+				})
+			})
 		})
 
 		When("it succeeds", func() {
@@ -209,4 +226,157 @@ var _ = Describe("Token", func() {
 			})
 		})
 	})
+	Describe("#RateLimit", func() {
+		var limited []string
+
+		BeforeEach(func() {
+			limited = nil
+			controller.OnRateLimited = func(scope, key string) {
+				limited = append(limited, scope+"/"+key)
+			}
+			uri = svr.URL + "/tokens?provider=rancher"
+		})
+
+		When("the provider exceeds its rate limit", func() {
+			BeforeEach(func() {
+				controller.ProviderLimiter = ratelimit.New(0.001, 1)
+				_, err := http.Get(uri)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns too many requests with a retry after header", func() {
+				Expect(res.StatusCode).To(Equal(http.StatusTooManyRequests))
+				Expect(res.Header.Get("Retry-After")).ToNot(BeEmpty())
+				Expect(fakeRancherClient.TokenCallCount()).To(Equal(1))
+				Expect(limited).To(Equal([]string{"provider/rancher"}))
+			})
+		})
+
+		When("the provider has too many concurrent refreshes", func() {
+			BeforeEach(func() {
+				fakeRancherClient.TokenReturns("", provider.ErrRefreshLimited)
+			})
+
+			It("returns too many requests with a retry after header", func() {
+				Expect(res.StatusCode).To(Equal(http.StatusTooManyRequests))
+				Expect(res.Header.Get("Retry-After")).To(Equal("1"))
+				Expect(limited).To(Equal([]string{"refresh/rancher"}))
+			})
+		})
+
+		When("requests are within the rate limits", func() {
+			BeforeEach(func() {
+				controller.CallerLimiter = ratelimit.New(100, 10)
+				controller.ProviderLimiter = ratelimit.New(100, 10)
+			})
+
+			It("succeeds", func() {
+				Expect(res.StatusCode).To(Equal(http.StatusOK))
+				Expect(limited).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("#Audit", func() {
+		var (
+			auditLog *bytes.Buffer
+			raw      string
+			events   []audit.Event
+		)
+
+		BeforeEach(func() {
+			auditLog = &bytes.Buffer{}
+			events = nil
+			controller.Auditor = audit.New(audit.NewWriterSink(auditLog), 0)
+			uri = svr.URL + "/tokens?provider=rancher"
+		})
+
+		JustBeforeEach(func() {
+			err := controller.Auditor.Close(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+
+			raw = auditLog.String()
+			dec := json.NewDecoder(auditLog)
+			for dec.More() {
+				var e audit.Event
+				Expect(dec.Decode(&e)).To(Succeed())
+				events = append(events, e)
+			}
+		})
+
+		When("the provider is not supported", func() {
+			BeforeEach(func() {
+				uri = svr.URL + "/tokens?provider=fake"
+			})
+
+			It("records the request", func() {
+				Expect(events).To(HaveLen(1))
+				Expect(events[0].Provider).To(Equal("fake"))
+				Expect(events[0].Outcome).To(Equal(audit.OutcomeUnsupportedProvider))
+				Expect(events[0].TokenFingerprint).To(BeEmpty())
+			})
+		})
+
+		When("getting a token fails", func() {
+			BeforeEach(func() {
+				fakeRancherClient.TokenReturns("", errors.New("error getting token from rancher"))
+			})
+
+			It("records the failure", func() {
+				Expect(events).To(HaveLen(1))
+				Expect(events[0].Outcome).To(Equal(audit.OutcomeError))
+				Expect(events[0].TokenFingerprint).To(BeEmpty())
+			})
+		})
+
+		When("it succeeds", func() {
+			It("records a fingerprint of the token but never the token", func() {
+				Expect(events).To(HaveLen(1))
+				Expect(events[0].Provider).To(Equal("rancher"))
+				Expect(events[0].Outcome).To(Equal(audit.OutcomeSuccess))
+				Expect(events[0].CacheHit).To(BeTrue())
+				Expect(events[0].SourceAddress).ToNot(BeEmpty())
+				Expect(events[0].TokenFingerprint).To(Equal(audit.Fingerprint("valid-rancher-token")))
+				Expect(raw).ToNot(ContainSubstring("valid-rancher-token"))
+			})
+		})
+	})
+})
+
+var _ = Describe("CheckAccess", func() {
+	var ctl *arcadehttp.Controller
+
+	BeforeEach(func() {
+		ctl = &arcadehttp.Controller{
+			Tokenizers: map[string]arcadehttp.Tokenizer{
+				"google":       &providerfakes.FakeClient{},
+				"vault-k8s-np": &providerfakes.FakeClient{},
+			},
+			Providers: map[string]arcadehttp.Provider{
+				"google":       {Name: "google", Type: arcadehttp.ProviderTypeGoogle, AllowedCallers: []string{"file-sink"}},
+				"vault-k8s-np": {Name: "vault-k8s-np", Type: arcadehttp.ProviderTypeVaultK8s},
+			},
+		}
+	})
+
+	It("allows callers the provider allows", func() {
+		Expect(ctl.CheckAccess("file-sink", "google")).To(Succeed())
+		Expect(ctl.CheckAccess("secret-sync", "vault-k8s-np-cluster1")).To(Succeed())
+	})
+
+	It("rejects unknown providers", func() {
+		err := ctl.CheckAccess("file-sink", "fake")
+		Expect(err).To(MatchError("Unsupported token provider: fake"))
+		Expect(err.(*arcadehttp.Error).Code).To(Equal(arcadehttp.ErrorCodeUnknownProvider))
+	})
+
+	It("rejects Vault K8s providers without a cluster", func() {
+		Expect(ctl.CheckAccess("file-sink", "vault-k8s-np")).To(MatchError("A cluster is required for token provider: vault-k8s-np"))
+	})
+
+	It("rejects callers the provider does not allow", func() {
+		err := ctl.CheckAccess("secret-sync", "google")
+		Expect(err).To(MatchError("Token provider not allowed: google"))
+		Expect(err.(*arcadehttp.Error).Code).To(Equal(arcadehttp.ErrorCodeForbidden))
+	})
 })
@@ -0,0 +1,235 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/homedepot/arcade/internal/audit"
+	"github.com/homedepot/arcade/internal/cache"
+	"github.com/homedepot/arcade/pkg/provider"
+)
+
+const (
+	// DefaultHeartbeatInterval is how often watches of tokens send a
+	// heartbeat unless configured otherwise.
+	DefaultHeartbeatInterval = 15 * time.Second
+	// watchRetry is how long clients are asked to wait before
+	// reconnecting to a watch.
+	watchRetry = 5 * time.Second
+	// watchPollInterval is how often a watched token is requested again
+	// when its provider does not cache it.
+	watchPollInterval = time.Minute
+	// watchMinInterval is the least time between two requests for a
+	// watched token.
+	watchMinInterval = time.Second
+)
+
+// WatchToken calls send with the token for req, and then with every new
+// token the provider's cache is refreshed with, until ctx is done or a
+// request fails. Tokens are refreshed once due, as for any request, or
+// sooner if another request refreshes them. Each token requested is
+// recorded in the audit log.
+func (ctl *Controller) WatchToken(ctx context.Context, req TokenRequest, send func(TokenResponse) error) error {
+	providerName, err := ctl.providerOrDefault(req.Provider)
+	if err != nil {
+		return err
+	}
+
+	req.Provider = providerName
+
+	cacheCtx, tokenizerName, _ := resolve(ctx, providerName)
+	tc, _ := provider.As[*cache.Cache](ctl.Tokenizers[tokenizerName])
+
+	var last string
+
+	for {
+		var changed <-chan struct{}
+		if tc != nil {
+			changed = tc.Changed(cacheCtx)
+		}
+
+		if e, ok := cached(cacheCtx, tc); !ok || e.Token != last {
+			t, err := ctl.Token(ctx, req)
+			if err != nil {
+				return err
+			}
+
+			if t.Token != last {
+				if err := send(t); err != nil {
+					return err
+				}
+
+				last = t.Token
+			}
+		}
+
+		wait := watchPollInterval
+		if e, ok := cached(cacheCtx, tc); ok && e.Token == last {
+			wait = time.Until(e.FreshUntil)
+		}
+
+		timer := time.NewTimer(max(wait, watchMinInterval))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return ctx.Err()
+		case <-changed:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// cached returns the fresh token cached by tc for the request parameters
+// in ctx, if any.
+func cached(ctx context.Context, tc *cache.Cache) (cache.Entry, bool) {
+	if tc == nil {
+		return cache.Entry{}, false
+	}
+
+	e, ok := tc.Lookup(ctx)
+	if !ok || !time.Now().Before(e.FreshUntil) {
+		return cache.Entry{}, false
+	}
+
+	return e, true
+}
+
+// WatchTokens streams the token of a given provider as Server-Sent Events:
+// the current token first, and then every new token once it is refreshed,
+// each with its expiry if known. Comments are sent as heartbeats to keep
+// the connection open. Events are identified by the fingerprint of their
+// token, so that a client reconnecting with the Last-Event-ID header is
+// only sent the current token if it has changed.
+func (ctl *Controller) WatchTokens(c *gin.Context) {
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	if ctl.Done != nil {
+		go func() {
+			select {
+			case <-ctl.Done:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	providerName, err := ctl.providerOrDefault(providerName(c))
+	if err != nil {
+		abort(c, err)
+
+		return
+	}
+
+	tokens := make(chan TokenResponse)
+	errc := make(chan error, 1)
+
+	go func() {
+		errc <- ctl.WatchToken(ctx, TokenRequest{Provider: providerName, SourceAddress: c.ClientIP()},
+			func(t TokenResponse) error {
+				select {
+				case tokens <- t:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})
+	}()
+
+	// The first token is awaited so that failing requests are responded
+	// with their status.
+	var t TokenResponse
+
+	select {
+	case t = <-tokens:
+	case err := <-errc:
+		if ctx.Err() == nil {
+			abort(c, err)
+		}
+
+		return
+	}
+
+	heartbeat := ctl.HeartbeatInterval
+	if heartbeat <= 0 {
+		heartbeat = DefaultHeartbeatInterval
+	}
+
+	rc := http.NewResponseController(c.Writer)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", watchRetry.Milliseconds())
+
+	if c.GetHeader("Last-Event-ID") != audit.Fingerprint(t.Token) {
+		writeTokenEvent(c.Writer, t)
+	}
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		// The server's write timeout would otherwise end the stream, so
+		// the deadline is extended with each write, where supported.
+		_ = rc.SetWriteDeadline(time.Now().Add(2 * heartbeat))
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		select {
+		case t := <-tokens:
+			writeTokenEvent(c.Writer, t)
+		case <-ticker.C:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+		case err := <-errc:
+			if ctx.Err() == nil {
+				writeErrorEvent(c.Writer, err)
+				_ = rc.Flush()
+			}
+
+			return
+		}
+	}
+}
+
+// writeTokenEvent writes a token event, identified by the fingerprint of
+// its token.
+func writeTokenEvent(w io.Writer, t TokenResponse) {
+	data := struct {
+		Token  string `json:"token"`
+		Expiry string `json:"expiry,omitempty"`
+	}{Token: t.Token}
+
+	if !t.Expiry.IsZero() {
+		data.Expiry = t.Expiry.UTC().Format(time.RFC3339)
+	}
+
+	b, _ := json.Marshal(data)
+
+	fmt.Fprintf(w, "id: %s\nevent: token\ndata: %s\n\n", audit.Fingerprint(t.Token), b)
+}
+
+// writeErrorEvent writes an error event with the error body of err.
+func writeErrorEvent(w io.Writer, err error) {
+	var e *Error
+	if !errors.As(err, &e) {
+		e = errUpstream(err)
+	}
+
+	b, _ := json.Marshal(gin.H{"error": e.Message, "code": e.Code})
+
+	fmt.Fprintf(w, "event: error\ndata: %s\n\n", b)
+}
@@ -0,0 +1,211 @@
+package http_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/homedepot/arcade/internal/audit"
+	"github.com/homedepot/arcade/internal/cache"
+	arcadehttp "github.com/homedepot/arcade/internal/http"
+	"github.com/homedepot/arcade/pkg/provider"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Watch", func() {
+	var (
+		watchSvr *httptest.Server
+		done     chan struct{}
+		res      *http.Response
+		lines    chan string
+		header   http.Header
+		uri      string
+	)
+
+	BeforeEach(func() {
+		var (
+			mux     sync.Mutex
+			fetches int
+		)
+
+		f := provider.FetcherFunc(func(context.Context) (provider.Token, error) {
+			mux.Lock()
+			defer mux.Unlock()
+
+			fetches++
+
+			return provider.Token{
+				AccessToken: fmt.Sprintf("token-%d", fetches),
+				Expiry:      time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+			}, nil
+		})
+
+		gin.SetMode(gin.ReleaseMode)
+
+		done = make(chan struct{})
+		ctl := &arcadehttp.Controller{
+			Tokenizers: map[string]arcadehttp.Tokenizer{
+				"google": cache.New(f, cache.Policy{}),
+			},
+			HeartbeatInterval: 50 * time.Millisecond,
+			Done:              done,
+		}
+
+		r := gin.New()
+		r.GET("/tokens", ctl.GetToken)
+		r.GET("/tokens/watch", ctl.WatchTokens)
+		r.GET("/v1/providers/:name/token/watch", ctl.WatchTokens)
+
+		watchSvr = httptest.NewServer(r)
+		uri = watchSvr.URL + "/tokens/watch?provider=google"
+		header = http.Header{}
+	})
+
+	AfterEach(func() {
+		if res != nil {
+			res.Body.Close()
+		}
+
+		watchSvr.Close()
+	})
+
+	JustBeforeEach(func() {
+		req, _ := http.NewRequest(http.MethodGet, uri, nil)
+		req.Header = header
+
+		var err error
+
+		res, err = http.DefaultClient.Do(req)
+		Expect(err).ToNot(HaveOccurred())
+
+		ch := make(chan string, 100)
+		lines = ch
+		body := res.Body
+
+		go func() {
+			defer close(ch)
+
+			scanner := bufio.NewScanner(body)
+			for scanner.Scan() {
+				ch <- scanner.Text()
+			}
+		}()
+	})
+
+	// event returns the next event, skipping heartbeats.
+	event := func() string {
+		var e []string
+
+		for {
+			var line string
+			Eventually(lines).Should(Receive(&line))
+
+			if strings.HasPrefix(line, ":") || strings.HasPrefix(line, "retry:") {
+				continue
+			}
+
+			if line == "" {
+				if len(e) > 0 {
+					return strings.Join(e, "\n")
+				}
+
+				continue
+			}
+
+			e = append(e, line)
+		}
+	}
+
+	refresh := func() {
+		r, err := http.Get(watchSvr.URL + "/tokens?provider=google")
+		Expect(err).ToNot(HaveOccurred())
+		r.Body.Close()
+
+		req, _ := http.NewRequest(http.MethodGet, watchSvr.URL+"/tokens?provider=google", nil)
+		req.Header.Set("Cache-Control", "no-cache")
+		r, err = http.DefaultClient.Do(req)
+		Expect(err).ToNot(HaveOccurred())
+		r.Body.Close()
+	}
+
+	It("streams the current token and then every refreshed token", func() {
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+		Expect(res.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+
+		Expect(event()).To(Equal(fmt.Sprintf("id: %s\nevent: token\ndata: %s",
+			audit.Fingerprint("token-1"), `{"token":"token-1","expiry":"2030-01-02T03:04:05Z"}`)))
+
+		refresh()
+
+		Expect(event()).To(ContainSubstring(`"token":"token-2"`))
+	})
+
+	It("asks clients to wait before reconnecting and sends heartbeats", func() {
+		Eventually(lines).Should(Receive(HavePrefix("retry: ")))
+		Eventually(lines).Should(Receive(Equal(": heartbeat")))
+	})
+
+	When("the client reconnects with the current token's ID", func() {
+		BeforeEach(func() {
+			header.Set("Last-Event-ID", audit.Fingerprint("token-1"))
+		})
+
+		It("does not send the token again", func() {
+			refresh()
+
+			Expect(event()).To(ContainSubstring(`"token":"token-2"`))
+		})
+	})
+
+	When("the provider is not supported", func() {
+		BeforeEach(func() {
+			uri = watchSvr.URL + "/tokens/watch?provider=fake"
+		})
+
+		It("returns a bad request error", func() {
+			Expect(res.StatusCode).To(Equal(http.StatusBadRequest))
+
+			b, _ := io.ReadAll(res.Body)
+			Expect(b).To(MatchJSON(`{"error":"Unsupported token provider: fake","code":"unknown_provider"}`))
+		})
+	})
+
+	When("the provider is named in the path", func() {
+		BeforeEach(func() {
+			uri = watchSvr.URL + "/v1/providers/google/token/watch"
+		})
+
+		It("streams its token", func() {
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+			Expect(event()).To(ContainSubstring(`"token":"token-1"`))
+		})
+
+		When("the provider is not supported", func() {
+			BeforeEach(func() {
+				uri = watchSvr.URL + "/v1/providers/fake/token/watch"
+			})
+
+			It("returns a not found error", func() {
+				Expect(res.StatusCode).To(Equal(http.StatusNotFound))
+			})
+		})
+	})
+
+	When("the server shuts down", func() {
+		It("ends the stream", func() {
+			event()
+
+			close(done)
+
+			Eventually(lines).Should(BeClosed())
+		})
+	})
+})
@@ -0,0 +1,102 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+
+	KeyRequestID = "request_id"
+	KeyCaller    = "caller"
+	KeyProvider  = "provider"
+	KeyCache     = "cache"
+	KeyTraceID   = "trace_id"
+	KeySpanID    = "span_id"
+)
+
+// New returns a logger writing records of at least the given level to w
+// in the given format, redacting anything resembling a credential.
+func New(w io.Writer, format, level string) (*slog.Logger, error) {
+	var l slog.Level
+
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", level)
+	}
+
+	opts := &slog.HandlerOptions{Level: l}
+
+	var h slog.Handler
+
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		h = slog.NewJSONHandler(w, opts)
+	case FormatText:
+		h = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q", format)
+	}
+
+	return slog.New(NewHandler(h)), nil
+}
+
+type fields struct {
+	mux   sync.Mutex
+	attrs []slog.Attr
+}
+
+type fieldsKey struct{}
+
+// NewContext returns a new context based on ctx that holds a set of
+// attributes added to every record logged with it, initially containing
+// the attributes of any set already held by ctx.
+func NewContext(ctx context.Context) context.Context {
+	f := &fields{}
+	f.attrs = append(f.attrs, Attrs(ctx)...)
+
+	return context.WithValue(ctx, fieldsKey{}, f)
+}
+
+// AddAttrs adds attributes to the set held by ctx. It does nothing if ctx
+// was not created by NewContext.
+func AddAttrs(ctx context.Context, attrs ...slog.Attr) {
+	f, ok := ctx.Value(fieldsKey{}).(*fields)
+	if !ok {
+		return
+	}
+
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	f.attrs = append(f.attrs, attrs...)
+}
+
+// Attrs returns a copy of the attributes held by ctx.
+func Attrs(ctx context.Context) []slog.Attr {
+	f, ok := ctx.Value(fieldsKey{}).(*fields)
+	if !ok {
+		return nil
+	}
+
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	return append([]slog.Attr(nil), f.attrs...)
+}
+
+// RequestID returns the request ID held by ctx, if any.
+func RequestID(ctx context.Context) string {
+	for _, a := range Attrs(ctx) {
+		if a.Key == KeyRequestID {
+			return a.Value.String()
+		}
+	}
+
+	return ""
+}
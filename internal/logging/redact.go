@@ -0,0 +1,153 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const redacted = "[REDACTED]"
+
+var (
+	// sensitiveKeys are substrings of attribute keys whose values are
+	// always redacted.
+	sensitiveKeys = []string{
+		"token",
+		"password",
+		"secret",
+		"authorization",
+		"apikey",
+		"api-key",
+		"api_key",
+		"cookie",
+	}
+	// sensitiveValues match strings resembling credentials.
+	sensitiveValues = []*regexp.Regexp{
+		// JSON Web Tokens.
+		regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+		// Google OAuth2 access tokens.
+		regexp.MustCompile(`ya29\.[A-Za-z0-9_.-]+`),
+		// Rancher tokens.
+		regexp.MustCompile(`(?:kubeconfig-)?(?:u|token)-[a-z0-9]+:[a-z0-9]{20,}`),
+		// Vault tokens.
+		regexp.MustCompile(`hv[sbr]\.[A-Za-z0-9_-]{20,}`),
+	}
+	// sensitivePairs match credentials following a label, such as
+	// "Bearer <token>" or "password=<password>", redacting only the
+	// credential. Tokens are only matched when directly assigned, to
+	// leave messages such as "error getting token: 404 Not Found" intact.
+	sensitivePairs = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)((?:bearer|basic)\s+|(?:password|passwd|secret|api[-_]?key)["']?\s*[:=]\s*["']?)[^\s"'&,;]+`),
+		regexp.MustCompile(`(?i)(token["']?(?:\s*=\s*|:)["']?)[^\s"'&,;]+`),
+	}
+)
+
+// Redact replaces anything in s resembling a token, password or client
+// secret.
+func Redact(s string) string {
+	for _, re := range sensitiveValues {
+		s = re.ReplaceAllString(s, redacted)
+	}
+
+	for _, re := range sensitivePairs {
+		s = re.ReplaceAllString(s, "${1}"+redacted)
+	}
+
+	return s
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+
+	for _, k := range sensitiveKeys {
+		if strings.Contains(key, k) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func redactAttr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+
+	if isSensitiveKey(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, Redact(a.Value.String()))
+	case slog.KindGroup:
+		attrs := a.Value.Group()
+		redactedAttrs := make([]any, 0, len(attrs))
+
+		for _, ga := range attrs {
+			redactedAttrs = append(redactedAttrs, redactAttr(ga))
+		}
+
+		return slog.Group(a.Key, redactedAttrs...)
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String(a.Key, Redact(err.Error()))
+		}
+	}
+
+	return a
+}
+
+// Handler is a slog.Handler that adds the attributes held by a record's
+// context, along with its trace and span IDs, and redacts credentials
+// before passing records on.
+type Handler struct {
+	next slog.Handler
+}
+
+// NewHandler returns a Handler passing records to next.
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{next: next}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	nr := slog.NewRecord(r.Time, r.Level, Redact(r.Message), r.PC)
+
+	for _, a := range Attrs(ctx) {
+		nr.AddAttrs(redactAttr(a))
+	}
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		nr.AddAttrs(
+			slog.String(KeyTraceID, sc.TraceID().String()),
+			slog.String(KeySpanID, sc.SpanID().String()),
+		)
+	}
+
+	r.Attrs(func(a slog.Attr) bool {
+		nr.AddAttrs(redactAttr(a))
+
+		return true
+	})
+
+	return h.next.Handle(ctx, nr)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redactedAttrs := make([]slog.Attr, 0, len(attrs))
+
+	for _, a := range attrs {
+		redactedAttrs = append(redactedAttrs, redactAttr(a))
+	}
+
+	return &Handler{next: h.next.WithAttrs(redactedAttrs)}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name)}
+}
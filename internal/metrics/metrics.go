@@ -0,0 +1,256 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/homedepot/arcade/internal/cache"
+	"github.com/homedepot/arcade/pkg/provider"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	namespace = "arcade"
+
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+
+	ErrorClassTimeout  = "timeout"
+	ErrorClassCanceled = "canceled"
+	ErrorClassNetwork  = "network"
+	ErrorClassUpstream = "upstream"
+)
+
+// Metrics holds the Prometheus collectors describing token issuance.
+type Metrics struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	cacheHits       *prometheus.CounterVec
+	cacheMisses     *prometheus.CounterVec
+	refreshes       *prometheus.CounterVec
+	refreshFailures *prometheus.CounterVec
+	refreshDuration *prometheus.HistogramVec
+	rateLimited     *prometheus.CounterVec
+	expiry          *expiryCollector
+}
+
+// New returns Metrics registered with a new registry, which also includes
+// the standard Go runtime and process collectors.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "token_requests_total",
+			Help:      "Total number of token requests by provider and outcome.",
+		}, []string{"provider", "outcome"}),
+		cacheHits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "token_cache_hits_total",
+			Help:      "Total number of token requests served from cache.",
+		}, []string{"provider"}),
+		cacheMisses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "token_cache_misses_total",
+			Help:      "Total number of token requests that required an upstream refresh.",
+		}, []string{"provider"}),
+		refreshes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "upstream_refreshes_total",
+			Help:      "Total number of requests for a new token made to upstream providers.",
+		}, []string{"provider"}),
+		refreshFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "upstream_refresh_failures_total",
+			Help:      "Total number of failed requests to upstream providers by error class.",
+		}, []string{"provider", "class"}),
+		refreshDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "upstream_refresh_duration_seconds",
+			Help:      "Latency of requests for a new token made to upstream providers.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"provider"}),
+		rateLimited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rate_limited_requests_total",
+			Help:      "Total number of token requests rejected by a rate limit, by scope and key.",
+		}, []string{"scope", "key"}),
+		expiry: &expiryCollector{
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, "", "token_expiry_seconds"),
+				"Seconds until the cached token of a provider, or of a cluster of a Vault K8s provider, expires.",
+				[]string{"provider", "cluster"}, nil,
+			),
+			caches: map[string]*cache.Cache{},
+		},
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.cacheHits,
+		m.cacheMisses,
+		m.refreshes,
+		m.refreshFailures,
+		m.refreshDuration,
+		m.rateLimited,
+		m.expiry,
+	)
+
+	return m
+}
+
+// Handler returns an http.Handler serving the metrics in the Prometheus
+// exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Instrument wraps a token provider client, recording metrics for every
+// token it returns under the given provider name. The expiry of the tokens
+// is reported if the client caches them.
+func (m *Metrics) Instrument(name string, c provider.Client) provider.Client {
+	if tc, ok := provider.As[*cache.Cache](c); ok {
+		m.expiry.add(name, tc)
+	}
+
+	return &instrumentedClient{
+		client:  c,
+		metrics: m,
+		name:    name,
+	}
+}
+
+type instrumentedClient struct {
+	client  provider.Client
+	metrics *Metrics
+	name    string
+}
+
+// Token returns a token from the wrapped client. Requests during which the
+// client reports an upstream refresh are counted as cache misses.
+func (i *instrumentedClient) Token(ctx context.Context) (string, error) {
+	refreshed := false
+
+	ctx = provider.WithTrace(ctx, &provider.Trace{
+		RefreshStart: func() {
+			refreshed = true
+		},
+		RefreshDone: func(info provider.RefreshInfo) {
+			// Refreshes shared between requests are counted once, though
+			// each request counts as a cache miss.
+			if info.Shared {
+				return
+			}
+
+			i.metrics.refreshes.WithLabelValues(i.name).Inc()
+			i.metrics.refreshDuration.WithLabelValues(i.name).Observe(info.Duration.Seconds())
+
+			if info.Err != nil {
+				i.metrics.refreshFailures.WithLabelValues(i.name, ErrorClass(info.Err)).Inc()
+			}
+		},
+	})
+
+	t, err := i.client.Token(ctx)
+
+	switch {
+	case refreshed:
+		i.metrics.cacheMisses.WithLabelValues(i.name).Inc()
+	case err == nil:
+		i.metrics.cacheHits.WithLabelValues(i.name).Inc()
+	}
+
+	if err != nil {
+		i.metrics.requests.WithLabelValues(i.name, OutcomeError).Inc()
+
+		return "", err
+	}
+
+	i.metrics.requests.WithLabelValues(i.name, OutcomeSuccess).Inc()
+
+	return t, nil
+}
+
+// Unwrap returns the wrapped client.
+func (i *instrumentedClient) Unwrap() provider.Client {
+	return i.client
+}
+
+// RateLimited counts a token request rejected by the rate limit of the
+// given scope, such as caller or provider, and key.
+func (m *Metrics) RateLimited(scope, key string) {
+	m.rateLimited.WithLabelValues(scope, key).Inc()
+}
+
+// ErrorClass returns a coarse classification of an error returned by an
+// upstream provider, suitable for use as a metric label.
+func ErrorClass(err error) string {
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return ErrorClassTimeout
+		}
+
+		return ErrorClassNetwork
+	}
+
+	return ErrorClassUpstream
+}
+
+// cluster returns the cluster a token of the named provider is cached
+// for, given the value of provider.ProviderKey it was requested with, as
+// set for Vault K8s providers, whose tokens are per cluster.
+func cluster(key, name string) string {
+	cluster, ok := strings.CutPrefix(key, name+"-")
+	if !ok {
+		return ""
+	}
+
+	return cluster
+}
+
+// expiryCollector reports the seconds until each token cached by the
+// instrumented providers expires, read from their caches at collection
+// time so that tokens removed from a cache are no longer reported.
+type expiryCollector struct {
+	desc   *prometheus.Desc
+	mux    sync.Mutex
+	caches map[string]*cache.Cache
+}
+
+func (e *expiryCollector) add(name string, c *cache.Cache) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	e.caches[name] = c
+}
+
+func (e *expiryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- e.desc
+}
+
+func (e *expiryCollector) Collect(ch chan<- prometheus.Metric) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	for name, c := range e.caches {
+		for key, expiry := range c.Expiries() {
+			ch <- prometheus.MustNewConstMetric(e.desc, prometheus.GaugeValue, time.Until(expiry).Seconds(), name, cluster(key, name))
+		}
+	}
+}
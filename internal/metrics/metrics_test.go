@@ -0,0 +1,182 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http/httptest"
+	"time"
+
+	"github.com/homedepot/arcade/internal/cache"
+	"github.com/homedepot/arcade/internal/metrics"
+	"github.com/homedepot/arcade/pkg/provider"
+	"github.com/homedepot/arcade/pkg/provider/providerfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Metrics", func() {
+	var (
+		m          *metrics.Metrics
+		fakeClient *providerfakes.FakeClient
+		client     provider.Client
+		token      string
+		err        error
+		body       string
+	)
+
+	refreshWith := func(expiry time.Time, refreshErr error) func(context.Context) (string, error) {
+		return func(ctx context.Context) (string, error) {
+			err := provider.Refresh(ctx, func(context.Context) (time.Time, error) {
+				return expiry, refreshErr
+			})
+			if err != nil {
+				return "", err
+			}
+
+			return "new-token", nil
+		}
+	}
+
+	BeforeEach(func() {
+		m = metrics.New()
+		fakeClient = &providerfakes.FakeClient{}
+		fakeClient.TokenReturns("cached-token", nil)
+		client = m.Instrument("test-provider", fakeClient)
+	})
+
+	JustBeforeEach(func() {
+		token, err = client.Token(context.Background())
+
+		rec := httptest.NewRecorder()
+		m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		b, _ := io.ReadAll(rec.Body)
+		body = string(b)
+	})
+
+	Describe("#Instrument", func() {
+		When("the token is served from cache", func() {
+			It("records a cache hit", func() {
+				Expect(err).To(BeNil())
+				Expect(token).To(Equal("cached-token"))
+				Expect(body).To(ContainSubstring(`arcade_token_requests_total{outcome="success",provider="test-provider"} 1`))
+				Expect(body).To(ContainSubstring(`arcade_token_cache_hits_total{provider="test-provider"} 1`))
+				Expect(body).ToNot(ContainSubstring(`arcade_token_cache_misses_total{`))
+				Expect(body).ToNot(ContainSubstring(`arcade_upstream_refreshes_total{`))
+			})
+		})
+
+		When("the client refreshes the token", func() {
+			BeforeEach(func() {
+				fakeClient.TokenStub = refreshWith(time.Now().Add(time.Hour), nil)
+			})
+
+			It("records a cache miss and the upstream refresh", func() {
+				Expect(err).To(BeNil())
+				Expect(token).To(Equal("new-token"))
+				Expect(body).To(ContainSubstring(`arcade_token_cache_misses_total{provider="test-provider"} 1`))
+				Expect(body).To(ContainSubstring(`arcade_upstream_refreshes_total{provider="test-provider"} 1`))
+				Expect(body).To(ContainSubstring(`arcade_upstream_refresh_duration_seconds_count{provider="test-provider"} 1`))
+			})
+		})
+
+		When("the client caches tokens", func() {
+			var c *cache.Cache
+
+			BeforeEach(func() {
+				expiries := map[string]time.Duration{
+					"vault-k8s-dv-cluster1": time.Hour,
+					"vault-k8s-dv-cluster2": 2 * time.Hour,
+				}
+
+				c = cache.New(provider.FetcherFunc(func(ctx context.Context) (provider.Token, error) {
+					key, _ := ctx.Value(provider.ProviderKey).(string)
+
+					return provider.Token{AccessToken: key, Expiry: time.Now().Add(expiries[key])}, nil
+				}), cache.Policy{})
+				client = m.Instrument("vault-k8s-dv", c)
+
+				for key := range expiries {
+					_, err := client.Token(context.WithValue(context.Background(), provider.ProviderKey, key))
+					Expect(err).ToNot(HaveOccurred())
+				}
+			})
+
+			It("records the expiry of the token of each cluster", func() {
+				Expect(body).To(MatchRegexp(`arcade_token_expiry_seconds{cluster="cluster1",provider="vault-k8s-dv"} 3[56]\d\d`))
+				Expect(body).To(MatchRegexp(`arcade_token_expiry_seconds{cluster="cluster2",provider="vault-k8s-dv"} 7[12]\d\d`))
+			})
+
+			When("a token is invalidated", func() {
+				BeforeEach(func() {
+					c.Invalidate(context.WithValue(context.Background(), provider.ProviderKey, "vault-k8s-dv-cluster1"))
+				})
+
+				It("no longer records its expiry", func() {
+					Expect(body).ToNot(ContainSubstring(`arcade_token_expiry_seconds{cluster="cluster1"`))
+					Expect(body).To(MatchRegexp(`arcade_token_expiry_seconds{cluster="cluster2",provider="vault-k8s-dv"} 7[12]\d\d`))
+				})
+			})
+
+			When("the cache is cleared", func() {
+				BeforeEach(func() {
+					c.Clear(context.Background())
+				})
+
+				It("no longer records any expiry", func() {
+					Expect(body).ToNot(ContainSubstring(`arcade_token_expiry_seconds{`))
+				})
+			})
+		})
+
+		When("the upstream refresh fails", func() {
+			BeforeEach(func() {
+				fakeClient.TokenStub = refreshWith(time.Time{}, context.DeadlineExceeded)
+			})
+
+			It("records the failure by error class", func() {
+				Expect(err).To(MatchError(context.DeadlineExceeded))
+				Expect(body).To(ContainSubstring(`arcade_token_requests_total{outcome="error",provider="test-provider"} 1`))
+				Expect(body).To(ContainSubstring(`arcade_upstream_refresh_failures_total{class="timeout",provider="test-provider"} 1`))
+				Expect(body).ToNot(ContainSubstring(`arcade_token_expiry_seconds{`))
+			})
+		})
+
+		When("the client fails without refreshing", func() {
+			BeforeEach(func() {
+				fakeClient.TokenReturns("", errors.New("invalid cluster name format"))
+			})
+
+			It("records neither a cache hit nor a miss", func() {
+				Expect(err).ToNot(BeNil())
+				Expect(body).To(ContainSubstring(`arcade_token_requests_total{outcome="error",provider="test-provider"} 1`))
+				Expect(body).ToNot(ContainSubstring(`arcade_token_cache_hits_total{`))
+				Expect(body).ToNot(ContainSubstring(`arcade_token_cache_misses_total{`))
+			})
+		})
+
+		It("unwraps to the original client", func() {
+			Expect(client.(provider.Wrapper).Unwrap()).To(BeIdenticalTo(fakeClient))
+		})
+	})
+
+	Describe("#RateLimited", func() {
+		BeforeEach(func() {
+			m.RateLimited("caller", "default")
+		})
+
+		It("counts the rejected request", func() {
+			Expect(body).To(ContainSubstring(`arcade_rate_limited_requests_total{key="default",scope="caller"} 1`))
+		})
+	})
+
+	Describe("#ErrorClass", func() {
+		It("classifies errors", func() {
+			Expect(metrics.ErrorClass(context.DeadlineExceeded)).To(Equal(metrics.ErrorClassTimeout))
+			Expect(metrics.ErrorClass(context.Canceled)).To(Equal(metrics.ErrorClassCanceled))
+			Expect(metrics.ErrorClass(&net.OpError{Op: "dial", Err: errors.New("connection refused")})).To(Equal(metrics.ErrorClassNetwork))
+			Expect(metrics.ErrorClass(errors.New("error getting token: 404 Not Found"))).To(Equal(metrics.ErrorClassUpstream))
+		})
+	})
+})
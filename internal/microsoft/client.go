@@ -9,9 +9,11 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
-	"log"
+	"log/slog"
+
+	"github.com/homedepot/arcade/pkg/provider"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Client
@@ -19,21 +21,18 @@ import (
 // Client makes a request for a client token.
 type Client struct {
 	c             *http.Client
-	cachedToken   string
 	clientID      string
 	clientSecret  string
-	expiration    time.Time
 	loginEndpoint string
-	mux           sync.Mutex
 	resource      string
 	timeout       time.Duration
 }
 
-// NewClient returns an implementation of Client using a default http client.
+// NewClient returns an implementation of Client using a default http client
+// instrumented for tracing.
 func NewClient() *Client {
 	return &Client{
-		c:          http.DefaultClient,
-		expiration: time.Time{}, // Reset expiration for a new client instance.
+		c: &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)},
 	}
 }
 
@@ -57,16 +56,8 @@ type errorResponse struct {
 	ErrorURI         string `json:"error_uri"`
 }
 
-// Token returns a cached token if it has not expired, otherwise it
-// retrieves a new access token and sets the cached token.
-func (c *Client) Token(ctx context.Context) (string, error) {
-	c.mux.Lock()
-	defer c.mux.Unlock()
-	// If the cached token has not expired just return it.
-	if time.Now().In(time.UTC).Before(c.expiration) {
-		return c.cachedToken, nil
-	}
-
+// Fetch retrieves a new access token using the client credentials grant.
+func (c *Client) Fetch(ctx context.Context) (provider.Token, error) {
 	data := url.Values{}
 	data.Set("grant_type", "client_credentials")
 	data.Set("client_id", c.clientID)
@@ -81,61 +72,60 @@ func (c *Client) Token(ctx context.Context) (string, error) {
 		c.loginEndpoint,
 		strings.NewReader(data.Encode()))
 	if err != nil {
-		return "", fmt.Errorf("microsoft: error making request: %w", err)
+		return provider.Token{}, fmt.Errorf("microsoft: error making request: %w", err)
 	}
 
 	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
 	res, err := c.c.Do(r)
 	if err != nil {
-		return "", fmt.Errorf("microsoft: error doing request for new token: %w", err)
+		return provider.Token{}, fmt.Errorf("microsoft: error doing request for new token: %w", err)
 	}
 
 	defer func(){
                  err := res.Body.Close()
                  if err != nil {
-			log.Printf("arcade: microsoft-client: error closing response body: %s\n", err.Error())
+			slog.WarnContext(ctx, "microsoft: error closing response body", slog.Any("error", err))
                  }
         }()
 
 	if res.StatusCode < 200 || res.StatusCode > 399 {
 		body, err := io.ReadAll(res.Body)
 		if err != nil {
-			return "", fmt.Errorf("microsoft: error getting token: %s", res.Status)
+			return provider.Token{}, fmt.Errorf("microsoft: error getting token: %s", res.Status)
 		}
 
 		var e errorResponse
 
 		err = json.Unmarshal(body, &e)
 		if err != nil {
-			return "", fmt.Errorf("microsoft: error getting token: %s", res.Status)
+			return provider.Token{}, fmt.Errorf("microsoft: error getting token: %s", res.Status)
 		}
 
-		return "", fmt.Errorf("microsoft: error getting token: %s", e.ErrorDescription)
+		return provider.Token{}, fmt.Errorf("microsoft: error getting token: %s", e.ErrorDescription)
 	}
 
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
-		return "", fmt.Errorf("microsoft: error reading body: %w", err)
+		return provider.Token{}, fmt.Errorf("microsoft: error reading body: %w", err)
 	}
 
 	var t token
 
 	err = json.Unmarshal(body, &t)
 	if err != nil {
-		return "", fmt.Errorf("microsoft: error unmarshaling body: %w", err)
+		return provider.Token{}, fmt.Errorf("microsoft: error unmarshaling body: %w", err)
 	}
 
-	c.cachedToken = t.AccessToken
-
 	expiresIn, err := strconv.Atoi(t.ExpiresIn)
 	if err != nil {
-		return "", fmt.Errorf("microsoft: error converting expiresIn field for token: %s", err)
+		return provider.Token{}, fmt.Errorf("microsoft: error converting expiresIn field for token: %s", err)
 	}
-	// Set expiration to 90% of expires in time.
-	c.expiration = time.Now().In(time.UTC).Add(time.Second * time.Duration((expiresIn/10)*9))
 
-	return c.cachedToken, nil
+	return provider.Token{
+		AccessToken: t.AccessToken,
+		Expiry:      time.Now().In(time.UTC).Add(time.Second * time.Duration(expiresIn)),
+	}, nil
 }
 
 // WithClientID sets the client ID.
@@ -9,16 +9,18 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/ghttp"
 
+	"github.com/homedepot/arcade/internal/cache"
 	. "github.com/homedepot/arcade/internal/microsoft"
 )
 
 var _ = Describe("Client", func() {
 	var (
-		server *ghttp.Server
-		client *Client
-		err    error
-		token  string
-		ctx    context.Context
+		server    *ghttp.Server
+		client    *Client
+		tokenizer *cache.Cache
+		err       error
+		token     string
+		ctx       context.Context
 	)
 
 	BeforeEach(func() {
@@ -30,6 +32,7 @@ var _ = Describe("Client", func() {
 		client.WithClientSecret("fake-client-secret")
 		client.WithResource("fake-resource")
 		client.WithTimeout(time.Second)
+		tokenizer = cache.New(client, cache.Policy{RefreshRatio: 0.9})
 	})
 
 	AfterEach(func() {
@@ -38,7 +41,7 @@ var _ = Describe("Client", func() {
 
 	Describe("#Token", func() {
 		JustBeforeEach(func() {
-			token, err = client.Token(ctx)
+			token, err = tokenizer.Token(ctx)
 		})
 
 		When("the uri is invalid", func() {
@@ -138,12 +141,13 @@ var _ = Describe("Client", func() {
 			})
 
 			JustBeforeEach(func() {
-				token, _ = client.Token(ctx)
+				token, _ = tokenizer.Token(ctx)
 			})
 
 			It("returns the cached token", func() {
 				Expect(err).To(BeNil())
 				Expect(token).To(Equal("fake.bearer.token.cached"))
+				Expect(server.ReceivedRequests()).To(HaveLen(1))
 			})
 		})
 
@@ -223,9 +227,9 @@ var _ = Describe("Client", func() {
 			Expect(token).To(Equal("fake.bearer.token"))
 
 			// Validate another client.
-			token, err = anotherclient.Token(context.Background())
+			t, err := anotherclient.Fetch(context.Background())
 			Expect(err).To(BeNil())
-			Expect(token).To(Equal("fake.bearer.token"))
+			Expect(t.AccessToken).To(Equal("fake.bearer.token"))
 		})
 	})
 })
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewLogger returns a handler that logs every request once it has been
+// handled, along with the attributes held by the request context.
+// Successful requests to any of the quiet paths, such as health checks,
+// are logged at debug level.
+func NewLogger(logger *slog.Logger, quietPaths ...string) gin.HandlerFunc {
+	quiet := map[string]bool{}
+	for _, p := range quietPaths {
+		quiet[p] = true
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		case quiet[c.Request.URL.Path]:
+			level = slog.LevelDebug
+		}
+
+		logger.LogAttrs(c.Request.Context(), level, "request",
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+		)
+	}
+}
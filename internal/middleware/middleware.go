@@ -1,19 +1,87 @@
 package middleware
 
 import (
+	"context"
+	"crypto/subtle"
+	"log/slog"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/homedepot/arcade/internal/logging"
 )
 
+const (
+	// DefaultAPIKeyName identifies callers authenticated with the API key.
+	DefaultAPIKeyName = "default"
+	// AdminAPIKeyName identifies callers authenticated with the admin API
+	// key.
+	AdminAPIKeyName = "admin"
+)
+
+type callerKey struct{}
+
+// ContextWithCaller returns a new context based on ctx that identifies the
+// authenticated caller by name.
+func ContextWithCaller(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, callerKey{}, name)
+}
+
+// CallerFromContext returns the name of the authenticated caller held by
+// ctx, or an empty string if there is none.
+func CallerFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(callerKey{}).(string)
+
+	return name
+}
+
 func NewAPIKeyAuth(apiKey string) gin.HandlerFunc {
+	return newAPIKeyAuth(map[string]string{DefaultAPIKeyName: apiKey})
+}
+
+// NewAPIKeysAuth authorizes requests with any of the given API keys, keyed
+// by the name identifying their callers.
+func NewAPIKeysAuth(apiKeys map[string]string) gin.HandlerFunc {
+	return newAPIKeyAuth(apiKeys)
+}
+
+// NewAdminAPIKeyAuth authorizes administrative operations, which require
+// a separate API key.
+func NewAdminAPIKeyAuth(apiKey string) gin.HandlerFunc {
+	return newAPIKeyAuth(map[string]string{AdminAPIKeyName: apiKey})
+}
+
+// newAPIKeyAuth rejects requests without one of the given API keys,
+// identifying those with one as the caller it is named after.
+func newAPIKeyAuth(apiKeys map[string]string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if c.Request.Header.Get("Api-Key") != apiKey {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "bad api key"})
+		name, ok := CallerForKey(apiKeys, c.Request.Header.Get("Api-Key"))
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "bad api key", "code": "unauthorized"})
 
 			return
 		}
 
+		ctx := ContextWithCaller(c.Request.Context(), name)
+		logging.AddAttrs(ctx, slog.String(logging.KeyCaller, name))
+		c.Request = c.Request.WithContext(ctx)
+
 		c.Next()
 	}
 }
+
+// CallerForKey returns the name of the API key matching key. Every key is
+// compared in constant time so as not to reveal how much of a key matched.
+func CallerForKey(apiKeys map[string]string, key string) (string, bool) {
+	var (
+		caller string
+		found  bool
+	)
+
+	for name, apiKey := range apiKeys {
+		if apiKey != "" && subtle.ConstantTimeCompare([]byte(apiKey), []byte(key)) == 1 {
+			caller, found = name, true
+		}
+	}
+
+	return caller, found
+}
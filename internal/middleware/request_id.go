@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/homedepot/arcade/internal/logging"
+)
+
+const HeaderRequestID = "X-Request-Id"
+
+// validRequestID limits the request IDs accepted from clients to values
+// that are safe to log.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// NewRequestID returns a handler that propagates the request ID from the
+// X-Request-Id header, generating one if it is missing or invalid, into
+// the request context's log attributes and the response headers.
+func NewRequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := EnsureRequestID(c.Request.Header.Get(HeaderRequestID))
+
+		ctx := logging.NewContext(c.Request.Context())
+		logging.AddAttrs(ctx, slog.String(logging.KeyRequestID, id))
+		c.Request = c.Request.WithContext(ctx)
+		c.Header(HeaderRequestID, id)
+
+		c.Next()
+	}
+}
+
+// EnsureRequestID returns id if it is a valid request ID, or else a new
+// one.
+func EnsureRequestID(id string) string {
+	if !validRequestID.MatchString(id) {
+		return newRequestID()
+	}
+
+	return id
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
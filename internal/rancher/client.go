@@ -4,12 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"sync"
 	"time"
+
+	"github.com/homedepot/arcade/pkg/provider"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 type NewTokenRequest struct {
@@ -24,105 +29,149 @@ var (
 
 func NewClient() *Client {
 	return &Client{
-		c: &http.Client{},
+		c: &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)},
 	}
 }
 
-func (c *Client) tokenExpired() bool {
-	tokenExpired := c.shortExpiration > 0 && int(time.Since(c.cachedToken.Created.In(time.UTC)).Seconds()) > c.shortExpiration
+type Client struct {
+	c *http.Client
+	// issued is the most recently created token, which is revoked by
+	// Revoke.
+	issued   KubeconfigToken
+	mux      sync.Mutex
+	password string
+	timeout  time.Duration
+	url      string
+	username string
+}
 
-	expiredAtString := c.cachedToken.ExpiresAt
-	if expiredAtString == "" {
-		return time.Now().In(time.UTC).After(time.UnixMilli(c.cachedToken.CreatedTS+int64(c.cachedToken.TTL))) || c.cachedToken.Token == "" || tokenExpired
+// Fetch logs in to Rancher to create a new token.
+func (c *Client) Fetch(ctx context.Context) (provider.Token, error) {
+	k := KubeconfigToken{}
+
+	data := NewTokenRequest{
+		ResponseType: "json",
+		Username:     c.username,
+		Password:     c.password,
 	}
 
-	// parse the expiration time in RFC3339 format
-	expiredAt, _ := time.Parse(time.RFC3339, expiredAtString)
+	b, err := json.Marshal(data)
+	if err != nil {
+		return provider.Token{}, err
+	}
+	// Configure request to time out.
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	// Create the request.
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewBuffer(b))
+	if err != nil {
+		slog.ErrorContext(ctx, "rancher: error creating request", slog.String("url", c.url), slog.Any("error", err))
+		return provider.Token{}, err
+	}
 
-	// calculate whether the token is expired based on the parsed time
-	isExpired := time.Now().In(time.UTC).After(expiredAt) || tokenExpired
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Content-Type", "application/json")
 
-	// return the result with an additional check for empty token
-	return isExpired || c.cachedToken.Token == ""
-}
+	res, err := c.c.Do(req)
+	if err != nil {
+		slog.ErrorContext(ctx, "rancher: error doing request", slog.String("url", c.url), slog.Any("error", err))
+		return provider.Token{}, err
+	}
 
-type Client struct {
-	c               *http.Client
-	cachedToken     KubeconfigToken
-	mux             sync.Mutex
-	password        string
-	shortExpiration int // seconds for the expiration
-	timeout         time.Duration
-	url             string
-	username        string
+	defer func() {
+		err := res.Body.Close()
+		if err != nil {
+			slog.WarnContext(ctx, "rancher: error closing response body", slog.Any("error", err))
+		}
+	}()
+
+	if res.StatusCode != http.StatusCreated {
+		buf := make([]byte, 100)
+
+		n, err := io.ReadFull(res.Body, buf)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			slog.ErrorContext(ctx, "rancher: error reading response body",
+				slog.String("url", c.url), slog.Int("status", res.StatusCode), slog.Any("error", err))
+		} else {
+			// The body is redacted by the logger, but may still echo
+			// request details, so it is only logged at debug level.
+			slog.ErrorContext(ctx, "rancher: unexpected response", slog.String("url", c.url), slog.Int("status", res.StatusCode))
+			slog.DebugContext(ctx, "rancher: unexpected response body", slog.String("body", string(buf[:n])))
+			_, _ = io.Copy(io.Discard, res.Body)
+		}
+
+		return provider.Token{}, fmt.Errorf(errNotFoundFormat, res.Status)
+	}
+
+	err = json.NewDecoder(res.Body).Decode(&k)
+	if err != nil {
+		return provider.Token{}, err
+	}
+
+	c.mux.Lock()
+	c.issued = k
+	c.mux.Unlock()
+
+	return provider.Token{AccessToken: k.Token, Expiry: k.Expiry()}, nil
 }
 
-func (c *Client) Token(ctx context.Context) (string, error) {
+// Revoke deletes the most recently created token in Rancher.
+func (c *Client) Revoke(ctx context.Context) error {
 	c.mux.Lock()
 	defer c.mux.Unlock()
 
-	if c.tokenExpired() {
-		k := KubeconfigToken{}
+	if c.issued.Token == "" {
+		return nil
+	}
 
-		data := NewTokenRequest{
-			ResponseType: "json",
-			Username:     c.username,
-			Password:     c.password,
+	self := c.issued.Links.Self
+	if self == "" {
+		// Without a name the request would target the token collection.
+		if c.issued.Name == "" {
+			return errors.New("error revoking token: token has no name or self link")
 		}
 
-		b, err := json.Marshal(data)
-		if err != nil {
-			return "", err
-		}
-		// Configure request to time out.
-		ctx, cancel := context.WithTimeout(ctx, c.timeout)
-		defer cancel()
-		// Create the request.
-		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewBuffer(b))
+		u, err := url.Parse(c.url)
 		if err != nil {
-			log.Printf("NewRequestWithContext(%s), err=%s\n", c.url, err)
-			return "", err
+			return err
 		}
 
-		req.Header.Add("Accept", "application/json")
-		req.Header.Add("Content-Type", "application/json")
+		self = u.Scheme + "://" + u.Host + "/v3/tokens/" + c.issued.Name
+	}
+	// Configure request to time out.
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
 
-		res, err := c.c.Do(req)
-		if err != nil {
-			log.Printf("Do(%s), err=%s\n", c.url, err)
-			return "", err
-		}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, self, nil)
+	if err != nil {
+		return err
+	}
 
-	        defer func(){
-                        err := res.Body.Close()
-                        if err != nil {
-				log.Printf("arcade: rancher-client: error closing response body: %s\n", err.Error())
-                        }
-                }()
-
-		if res.StatusCode != http.StatusCreated {
-			buf := make([]byte, 100)
-
-			_, err := io.ReadFull(res.Body, buf)
-			if err != nil && err != io.ErrUnexpectedEOF {
-				log.Printf("Do(%s), StatusCode=%d, failed to read body: %s\n", c.url, res.StatusCode, err)
-			} else {
-				log.Printf("Do(%s), StatusCode=%d, body: %s\n", c.url, res.StatusCode, buf)
-				_, _ = io.Copy(io.Discard, res.Body)
-			}
-
-			return "", fmt.Errorf(errNotFoundFormat, res.Status)
-		}
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Authorization", "Bearer "+c.issued.Token)
+
+	res, err := c.c.Do(req)
+	if err != nil {
+		return err
+	}
 
-		err = json.NewDecoder(res.Body).Decode(&k)
+	defer func() {
+		err := res.Body.Close()
 		if err != nil {
-			return "", err
+			slog.WarnContext(ctx, "rancher: error closing response body", slog.Any("error", err))
 		}
+	}()
 
-		c.cachedToken = k
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	// The token is gone either way if Rancher no longer knows about it.
+	if res.StatusCode >= 300 && res.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("error revoking token: %s", res.Status)
 	}
 
-	return c.cachedToken.Token, nil
+	c.issued = KubeconfigToken{}
+
+	return nil
 }
 
 // WithPassword sets the password.
@@ -130,9 +179,9 @@ func (c *Client) WithPassword(password string) {
 	c.password = password
 }
 
-// WithTransport sets the http transport.
+// WithTransport sets the http transport, instrumented for tracing.
 func (c *Client) WithTransport(transport *http.Transport) {
-	c.c.Transport = transport
+	c.c.Transport = otelhttp.NewTransport(transport)
 }
 
 // WithTimeout sets the timeout on the http request to retrieve the token.
@@ -149,8 +198,3 @@ func (c *Client) WithURL(url string) {
 func (c *Client) WithUsername(username string) {
 	c.username = username
 }
-
-// WithShortExpiration sets the expiration time used for requesting a fresh token.
-func (c *Client) WithShortExpiration(shortExpiration int) {
-	c.shortExpiration = shortExpiration
-}
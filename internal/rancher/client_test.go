@@ -3,9 +3,11 @@ package rancher_test
 import (
 	"context"
 	"crypto/tls"
+	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/homedepot/arcade/internal/cache"
 	. "github.com/homedepot/arcade/internal/rancher"
 
 	. "github.com/onsi/ginkgo"
@@ -15,12 +17,13 @@ import (
 
 var _ = Describe("Client", func() {
 	var (
-		server   *ghttp.Server
-		client   *Client
-		username string
-		password string
-		t        string
-		err      error
+		server    *ghttp.Server
+		client    *Client
+		tokenizer *cache.Cache
+		username  string
+		password  string
+		t         string
+		err       error
 	)
 
 	BeforeEach(func() {
@@ -32,6 +35,7 @@ var _ = Describe("Client", func() {
 		client.WithUsername(username)
 		client.WithPassword(password)
 		client.WithTimeout(time.Second)
+		tokenizer = cache.New(client, cache.Policy{})
 	})
 
 	Describe("#NewToken", func() {
@@ -40,7 +44,7 @@ var _ = Describe("Client", func() {
 		})
 
 		JustBeforeEach(func() {
-			t, err = client.Token(context.Background())
+			t, err = tokenizer.Token(context.Background())
 		})
 
 		When("the uri is invalid", func() {
@@ -208,7 +212,7 @@ var _ = Describe("Client", func() {
 				Expect(err).To(BeNil())
 				Expect(t).To(Equal("fake.token.cached"))
 				// Second call returns cached token
-				t2, _ := client.Token(context.Background())
+				t2, _ := tokenizer.Token(context.Background())
 				Expect(t2).To(Equal("fake.token.cached"))
 				Expect(server.ReceivedRequests()).To(HaveLen(1))
 			})
@@ -265,7 +269,8 @@ var _ = Describe("Client", func() {
 				Expect(t).To(Equal("kubeconfig-u-i76rfanbw5:ltqlpxqz5hh52sxfxfbxxkk6xw7pzkh7d922cww6m9x6fjskskxwl9"))
 
 				// Validate another client.
-				t, err = anotherclient.Token(context.Background())
+				another, err := anotherclient.Fetch(context.Background())
+				t = another.AccessToken
 				Expect(err).To(BeNil())
 				Expect(t).To(Equal("another.token"))
 				Expect(server.ReceivedRequests()).To(HaveLen(2))
@@ -274,7 +279,7 @@ var _ = Describe("Client", func() {
 
 		When("there is a cached token, shortExpiration set and it has passed", func() {
 			BeforeEach(func() {
-				client.WithShortExpiration(1)
+				tokenizer = cache.New(client, cache.Policy{MaxTTL: time.Second})
 				json := `{"responseType": "json","username": "test-user","password": "test-pass"}`
 				// create the "cache" in the client
 				server.AppendHandlers(ghttp.CombineHandlers(
@@ -298,7 +303,7 @@ var _ = Describe("Client", func() {
 				time.Sleep(2 * time.Second)
 
 				// Second call returns the newly fetched token
-				t2, err2 := client.Token(context.Background())
+				t2, err2 := tokenizer.Token(context.Background())
 				Expect(err2).To(BeNil())
 				Expect(t2).To(Equal("another.token"))
 				Expect(server.ReceivedRequests()).To(HaveLen(2))
@@ -307,7 +312,7 @@ var _ = Describe("Client", func() {
 
 		When("there is a shortExpiration set and it has not passed and there is a cached token", func() {
 			BeforeEach(func() {
-				client.WithShortExpiration(9223372040)
+				tokenizer = cache.New(client, cache.Policy{MaxTTL: 24 * time.Hour})
 				json := `{"responseType": "json","username": "test-user","password": "test-pass"}`
 				server.AppendHandlers(ghttp.CombineHandlers(
 					ghttp.VerifyRequest("POST", "/"),
@@ -325,7 +330,7 @@ var _ = Describe("Client", func() {
 				time.Sleep(3 * time.Second)
 
 				// Second call returns the same response
-				t2, err2 := client.Token(context.Background())
+				t2, err2 := tokenizer.Token(context.Background())
 				Expect(err2).To(BeNil())
 				Expect(t2).To(Equal("fake.token.cached"))
 				Expect(server.ReceivedRequests()).To(HaveLen(1))
@@ -334,7 +339,7 @@ var _ = Describe("Client", func() {
 
 		When("there is a shortExpiration set and it has not passed and there is no cached token", func() {
 			BeforeEach(func() {
-				client.WithShortExpiration(1)
+				tokenizer = cache.New(client, cache.Policy{MaxTTL: time.Second})
 				json := `{"responseType": "json","username": "test-user","password": "test-pass"}`
 				server.AppendHandlers(ghttp.CombineHandlers(
 					ghttp.VerifyRequest("POST", "/"),
@@ -351,4 +356,86 @@ var _ = Describe("Client", func() {
 			})
 		})
 	})
+
+	Describe("#Revoke", func() {
+		AfterEach(func() {
+			server.Close()
+		})
+
+		JustBeforeEach(func() {
+			err = tokenizer.Revoke(context.Background())
+		})
+
+		When("there is no cached token", func() {
+			It("does nothing", func() {
+				Expect(err).To(BeNil())
+				Expect(server.ReceivedRequests()).To(HaveLen(0))
+			})
+		})
+
+		When("there is a cached token", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusCreated, fmt.Sprintf(`{
+						"name": "kubeconfig-u-test",
+						"token": "kubeconfig-u-test:secret",
+						"expiresAt": "9999-12-31T00:00:00Z",
+						"links": {"self": "%s/v3/tokens/kubeconfig-u-test"}
+					}`, server.URL())),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest(http.MethodDelete, "/v3/tokens/kubeconfig-u-test"),
+						ghttp.VerifyHeaderKV("Authorization", "Bearer kubeconfig-u-test:secret"),
+						ghttp.RespondWith(http.StatusNoContent, nil),
+					),
+					ghttp.RespondWith(http.StatusCreated, payloadKubeconfigTokenAnother),
+				)
+				_, err = tokenizer.Token(context.Background())
+				Expect(err).To(BeNil())
+			})
+
+			It("deletes the token and clears the cache", func() {
+				Expect(err).To(BeNil())
+				t, err = tokenizer.Token(context.Background())
+				Expect(err).To(BeNil())
+				Expect(t).To(Equal("another.token"))
+				Expect(server.ReceivedRequests()).To(HaveLen(3))
+			})
+		})
+
+		When("the token has no self link", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusCreated, `{"name": "kubeconfig-u-test", "token": "kubeconfig-u-test:secret"}`),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest(http.MethodDelete, "/v3/tokens/kubeconfig-u-test"),
+						ghttp.RespondWith(http.StatusInternalServerError, nil),
+					),
+				)
+				_, err = tokenizer.Token(context.Background())
+				Expect(err).To(BeNil())
+			})
+
+			It("deletes the token by name", func() {
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(Equal("error revoking token: 500 Internal Server Error"))
+				Expect(server.ReceivedRequests()).To(HaveLen(2))
+			})
+		})
+
+		When("the token has no self link or name", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusCreated, payloadKubeconfigTokenCached),
+				)
+				_, err = tokenizer.Token(context.Background())
+				Expect(err).To(BeNil())
+			})
+
+			It("returns an error without making a request", func() {
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(Equal("error revoking token: token has no name or self link"))
+				Expect(server.ReceivedRequests()).To(HaveLen(1))
+			})
+		})
+	})
 })
@@ -34,3 +34,15 @@ type KubeconfigToken struct {
 	UserPrincipal string `json:"userPrincipal"`
 	UUID          string `json:"uuid"`
 }
+
+// Expiry returns when the token expires, as reported by Rancher.
+func (k KubeconfigToken) Expiry() time.Time {
+	if k.ExpiresAt == "" {
+		return time.UnixMilli(k.CreatedTS + int64(k.TTL))
+	}
+
+	// parse the expiration time in RFC3339 format
+	expiresAt, _ := time.Parse(time.RFC3339, k.ExpiresAt)
+
+	return expiresAt
+}
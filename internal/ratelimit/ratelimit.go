@@ -0,0 +1,114 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/homedepot/arcade/pkg/provider"
+	"golang.org/x/time/rate"
+)
+
+// Limiter limits the rate of events per key using a token bucket for each
+// key. A nil Limiter allows every event.
+type Limiter struct {
+	limit    rate.Limit
+	burst    int
+	mux      sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// New returns a Limiter allowing perSecond events per second for each key,
+// with bursts of up to burst events. It returns nil, allowing every event,
+// if perSecond is not positive.
+func New(perSecond float64, burst int) *Limiter {
+	if perSecond <= 0 {
+		return nil
+	}
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &Limiter{
+		limit:    rate.Limit(perSecond),
+		burst:    burst,
+		limiters: map[string]*rate.Limiter{},
+	}
+}
+
+// Allow reports whether an event for key may happen now. If not, it also
+// returns how long to wait before trying again.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+
+	l.mux.Lock()
+
+	lim, ok := l.limiters[key]
+	if !ok {
+		lim = rate.NewLimiter(l.limit, l.burst)
+		l.limiters[key] = lim
+	}
+
+	l.mux.Unlock()
+
+	r := lim.Reserve()
+	if d := r.Delay(); d > 0 {
+		r.Cancel()
+
+		return false, d
+	}
+
+	return true, 0
+}
+
+// RetryAfter returns d as a whole number of seconds, rounded up, for use
+// in a Retry-After header.
+func RetryAfter(d time.Duration) int {
+	return int(math.Max(1, math.Ceil(d.Seconds())))
+}
+
+// LimitRefreshes returns a wrapper for token provider clients allowing each
+// at most max concurrent requests to its upstream provider. Requests beyond
+// that fail with provider.ErrRefreshLimited. Tokens served from cache are
+// never limited.
+func LimitRefreshes(max int) func(string, provider.Client) provider.Client {
+	return func(_ string, c provider.Client) provider.Client {
+		return &refreshLimitedClient{
+			client: c,
+			sem:    make(semaphore, max),
+		}
+	}
+}
+
+type refreshLimitedClient struct {
+	client provider.Client
+	sem    semaphore
+}
+
+func (r *refreshLimitedClient) Token(ctx context.Context) (string, error) {
+	return r.client.Token(provider.WithRefreshLimiter(ctx, r.sem))
+}
+
+// Unwrap returns the wrapped client.
+func (r *refreshLimitedClient) Unwrap() provider.Client {
+	return r.client
+}
+
+type semaphore chan struct{}
+
+func (s semaphore) TryAcquire() bool {
+	select {
+	case s <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s semaphore) Release() {
+	<-s
+}
@@ -0,0 +1,102 @@
+package ratelimit_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/homedepot/arcade/internal/ratelimit"
+	"github.com/homedepot/arcade/pkg/provider"
+	"github.com/homedepot/arcade/pkg/provider/providerfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Ratelimit", func() {
+	Describe("#Limiter", func() {
+		When("no rate is configured", func() {
+			It("allows every event", func() {
+				l := ratelimit.New(0, 1)
+				Expect(l).To(BeNil())
+
+				for i := 0; i < 100; i++ {
+					ok, _ := l.Allow("default")
+					Expect(ok).To(BeTrue())
+				}
+			})
+		})
+
+		When("a key exceeds its burst", func() {
+			It("rejects the event with a delay, limiting each key separately", func() {
+				l := ratelimit.New(1, 2)
+
+				ok, _ := l.Allow("default")
+				Expect(ok).To(BeTrue())
+				ok, _ = l.Allow("default")
+				Expect(ok).To(BeTrue())
+
+				ok, retryAfter := l.Allow("default")
+				Expect(ok).To(BeFalse())
+				Expect(retryAfter).To(BeNumerically(">", 0))
+				Expect(retryAfter).To(BeNumerically("<=", time.Second))
+
+				ok, _ = l.Allow("other")
+				Expect(ok).To(BeTrue())
+			})
+		})
+	})
+
+	Describe("#RetryAfter", func() {
+		It("rounds up to whole seconds", func() {
+			Expect(ratelimit.RetryAfter(0)).To(Equal(1))
+			Expect(ratelimit.RetryAfter(100 * time.Millisecond)).To(Equal(1))
+			Expect(ratelimit.RetryAfter(1500 * time.Millisecond)).To(Equal(2))
+		})
+	})
+
+	Describe("#LimitRefreshes", func() {
+		var (
+			fakeClient *providerfakes.FakeClient
+			client     provider.Client
+			started    chan struct{}
+			release    chan struct{}
+		)
+
+		BeforeEach(func() {
+			started = make(chan struct{})
+			release = make(chan struct{})
+			fakeClient = &providerfakes.FakeClient{}
+			fakeClient.TokenStub = func(ctx context.Context) (string, error) {
+				err := provider.Refresh(ctx, func(context.Context) (time.Time, error) {
+					started <- struct{}{}
+					<-release
+
+					return time.Time{}, nil
+				})
+
+				return "new-token", err
+			}
+			client = ratelimit.LimitRefreshes(1)("test-provider", fakeClient)
+		})
+
+		It("rejects refreshes beyond the limit", func() {
+			errc := make(chan error, 1)
+
+			go func() {
+				_, err := client.Token(context.Background())
+				errc <- err
+			}()
+
+			<-started
+
+			_, err := client.Token(context.Background())
+			Expect(err).To(MatchError(provider.ErrRefreshLimited))
+
+			close(release)
+			Expect(<-errc).To(Succeed())
+		})
+
+		It("unwraps to the original client", func() {
+			Expect(client.(provider.Wrapper).Unwrap()).To(BeIdenticalTo(fakeClient))
+		})
+	})
+})
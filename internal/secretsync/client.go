@@ -0,0 +1,144 @@
+package secretsync
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// fieldManager is the manager of the fields arcade applies to Secrets.
+	fieldManager = "arcade"
+	// serviceAccountDir holds the credentials of the pod's service account.
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+)
+
+//go:generate counterfeiter . Client
+
+// Client applies Secrets to a Kubernetes cluster.
+type Client interface {
+	// ApplySecret creates s, or updates the fields of s set by arcade if
+	// it exists.
+	ApplySecret(ctx context.Context, s *KubernetesSecret) error
+}
+
+// KubernetesSecret is the subset of a Kubernetes Secret set by arcade.
+type KubernetesSecret struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   ObjectMeta        `json:"metadata"`
+	Type       string            `json:"type"`
+	Data       map[string][]byte `json:"data"`
+}
+
+// ObjectMeta is the subset of the metadata of Kubernetes objects set by
+// arcade.
+type ObjectMeta struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// RESTClient is a Client calling the Kubernetes API directly, applying
+// Secrets with server-side apply so that fields set by others are kept.
+type RESTClient struct {
+	server    string
+	client    *http.Client
+	tokenFile string
+}
+
+// NewRESTClient returns a RESTClient of the API server at server, making
+// requests with c and authenticating with the bearer token in tokenFile,
+// which is read for each request so that rotated tokens are used.
+func NewRESTClient(server string, c *http.Client, tokenFile string) *RESTClient {
+	return &RESTClient{server: strings.TrimSuffix(server, "/"), client: c, tokenFile: tokenFile}
+}
+
+// NewInClusterClient returns a RESTClient of the cluster arcade runs in,
+// authenticating as its service account.
+func NewInClusterClient() (*RESTClient, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("not running in a Kubernetes cluster")
+	}
+
+	ca, err := os.ReadFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, fmt.Errorf("error reading cluster CA: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("no certificates found in cluster CA")
+	}
+
+	c := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+		},
+	}
+
+	return NewRESTClient("https://"+net.JoinHostPort(host, port), c, serviceAccountDir+"/token"), nil
+}
+
+func (c *RESTClient) ApplySecret(ctx context.Context, s *KubernetesSecret) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	u := fmt.Sprintf("%s/api/v1/namespaces/%s/secrets/%s?fieldManager=%s&force=true",
+		c.server, url.PathEscape(s.Metadata.Namespace), url.PathEscape(s.Metadata.Name), fieldManager)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, u, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+
+	token, err := os.ReadFile(c.tokenFile)
+	if err != nil {
+		return fmt.Errorf("error reading service account token: %w", err)
+	}
+
+	// Server-side apply patches are YAML, of which JSON is a subset.
+	req.Header.Set("Content-Type", "application/apply-patch+yaml")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		var status struct {
+			Message string `json:"message"`
+		}
+
+		if err := json.Unmarshal(body, &status); err != nil || status.Message == "" {
+			status.Message = strings.TrimSpace(string(body))
+		}
+
+		return fmt.Errorf("error applying secret %s/%s: %s: %s", s.Metadata.Namespace, s.Metadata.Name, res.Status, status.Message)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package secretsync_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"github.com/homedepot/arcade/internal/secretsync"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RESTClient", func() {
+	var (
+		svr       *httptest.Server
+		dir       string
+		req       *http.Request
+		body      string
+		status    int
+		resBody   string
+		secret    *secretsync.KubernetesSecret
+		err       error
+		tokenFile string
+	)
+
+	BeforeEach(func() {
+		status = http.StatusOK
+		resBody = `{}`
+
+		svr = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := io.ReadAll(r.Body)
+			req, body = r, string(b)
+
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(resBody))
+		}))
+
+		dir, _ = os.MkdirTemp("", "arcade-secret-sync")
+		tokenFile = filepath.Join(dir, "token")
+		Expect(os.WriteFile(tokenFile, []byte("service-account-token\n"), 0600)).To(Succeed())
+
+		secret = &secretsync.KubernetesSecret{
+			APIVersion: "v1",
+			Kind:       "Secret",
+			Metadata:   secretsync.ObjectMeta{Name: "azure-token", Namespace: "default"},
+			Type:       "Opaque",
+			Data:       map[string][]byte{"token": []byte("token-1")},
+		}
+	})
+
+	AfterEach(func() {
+		svr.Close()
+		os.RemoveAll(dir)
+	})
+
+	JustBeforeEach(func() {
+		err = secretsync.NewRESTClient(svr.URL, svr.Client(), tokenFile).ApplySecret(context.Background(), secret)
+	})
+
+	It("applies the secret with server-side apply", func() {
+		Expect(err).ToNot(HaveOccurred())
+		Expect(req.Method).To(Equal(http.MethodPatch))
+		Expect(req.URL.Path).To(Equal("/api/v1/namespaces/default/secrets/azure-token"))
+		Expect(req.URL.Query().Get("fieldManager")).To(Equal("arcade"))
+		Expect(req.URL.Query().Get("force")).To(Equal("true"))
+		Expect(req.Header.Get("Content-Type")).To(Equal("application/apply-patch+yaml"))
+		Expect(req.Header.Get("Authorization")).To(Equal("Bearer service-account-token"))
+		Expect(body).To(MatchJSON(`{
+			"apiVersion": "v1",
+			"kind": "Secret",
+			"metadata": {"name": "azure-token", "namespace": "default"},
+			"type": "Opaque",
+			"data": {"token": "dG9rZW4tMQ=="}
+		}`))
+	})
+
+	When("the API server rejects the request", func() {
+		BeforeEach(func() {
+			status = http.StatusForbidden
+			resBody = `{"kind": "Status", "message": "secrets \"azure-token\" is forbidden"}`
+		})
+
+		It("returns an error with the API server's message", func() {
+			Expect(err).To(MatchError(`error applying secret default/azure-token: 403 Forbidden: secrets "azure-token" is forbidden`))
+		})
+	})
+
+	When("the service account token cannot be read", func() {
+		BeforeEach(func() {
+			tokenFile = filepath.Join(dir, "missing")
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("error reading service account token"))
+		})
+	})
+})
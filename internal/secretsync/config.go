@@ -0,0 +1,142 @@
+package secretsync
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/homedepot/arcade/internal/credentials"
+	"github.com/homedepot/arcade/internal/tokenwatch"
+)
+
+// Types of the Secrets tokens are synced to.
+const (
+	// TypeOpaque is an Opaque Secret holding the token under Key.
+	TypeOpaque = "opaque"
+	// TypeDockerConfigJSON is an image pull Secret authenticating with a
+	// registry.
+	TypeDockerConfigJSON = "dockerconfigjson"
+	// TypeArgoCDCluster is an Argo CD cluster Secret authenticating with
+	// a cluster.
+	TypeArgoCDCluster = "argocd-cluster"
+
+	// DefaultKey is the key Opaque Secrets hold the token under.
+	DefaultKey = "token"
+)
+
+// Config lists the Secrets tokens are synced to.
+type Config struct {
+	Secrets []Secret `json:"secrets"`
+}
+
+// Secret is a Kubernetes Secret the token of a provider is synced to,
+// updated each time the token is refreshed.
+type Secret struct {
+	// Provider is the name of the provider whose token is synced.
+	Provider string `json:"provider"`
+	// Cluster is the cluster of Vault K8s providers.
+	Cluster   string `json:"cluster,omitempty"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Type is one of opaque, dockerconfigjson or argocd-cluster, opaque by
+	// default.
+	Type string `json:"type,omitempty"`
+	// Key is the key Opaque Secrets hold the token under.
+	Key string `json:"key,omitempty"`
+	// Registry is the registry dockerconfigjson Secrets authenticate with,
+	// as Username. Username defaults to the username expected by the
+	// registries of Google and Microsoft.
+	Registry string `json:"registry,omitempty"`
+	Username string `json:"username,omitempty"`
+	// Server and CertificateAuthorityData are the address and base64
+	// encoded CA certificates of the cluster of argocd-cluster Secrets,
+	// which Argo CD names ClusterName, by default the provider.
+	Server                   string `json:"server,omitempty"`
+	CertificateAuthorityData string `json:"certificateAuthorityData,omitempty"`
+	ClusterName              string `json:"clusterName,omitempty"`
+	// Labels and Annotations are added to those arcade sets.
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// LoadConfig reads the Secrets tokens are synced to from the JSON file at
+// path.
+func LoadConfig(path string) ([]Secret, error) {
+	var cfg Config
+	if err := tokenwatch.LoadConfig(path, &cfg); err != nil {
+		return nil, fmt.Errorf("error loading secret sync configuration %s: %w", path, err)
+	}
+
+	names := map[string]bool{}
+
+	for i := range cfg.Secrets {
+		s := &cfg.Secrets[i]
+
+		if err := s.init(); err != nil {
+			return nil, fmt.Errorf("invalid secret sync configuration %s: secret %d: %w", path, i+1, err)
+		}
+
+		if names[s.String()] {
+			return nil, fmt.Errorf("invalid secret sync configuration %s: secret %d: %s is synced more than once", path, i+1, s)
+		}
+
+		names[s.String()] = true
+	}
+
+	return cfg.Secrets, nil
+}
+
+// init validates s and sets its defaults.
+func (s *Secret) init() error {
+	if s.Provider == "" {
+		return errors.New("a provider is required")
+	}
+
+	if s.Namespace == "" || s.Name == "" {
+		return errors.New("a namespace and name are required")
+	}
+
+	switch s.Type {
+	case "":
+		s.Type = TypeOpaque
+
+		fallthrough
+	case TypeOpaque:
+		if s.Key == "" {
+			s.Key = DefaultKey
+		}
+	case TypeDockerConfigJSON:
+		if s.Registry == "" {
+			return errors.New("a registry is required for dockerconfigjson secrets")
+		}
+
+		if s.Username == "" {
+			s.Username = credentials.DefaultRegistryUsername(s.Registry)
+		}
+
+		if s.Username == "" {
+			return fmt.Errorf("no username configured for registry %s", s.Registry)
+		}
+	case TypeArgoCDCluster:
+		if s.Server == "" {
+			return errors.New("a server is required for argocd-cluster secrets")
+		}
+
+		if s.ClusterName == "" {
+			s.ClusterName = s.provider()
+		}
+	default:
+		return fmt.Errorf("unknown type %q", s.Type)
+	}
+
+	return nil
+}
+
+// String returns the namespace and name of s.
+func (s Secret) String() string {
+	return s.Namespace + "/" + s.Name
+}
+
+// provider returns the name the provider of s is requested by.
+func (s Secret) provider() string {
+	return tokenwatch.Provider(s.Provider, s.Cluster)
+}
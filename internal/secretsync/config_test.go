@@ -0,0 +1,118 @@
+package secretsync_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/homedepot/arcade/internal/secretsync"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LoadConfig", func() {
+	var (
+		dir     string
+		cfg     string
+		secrets []secretsync.Secret
+		err     error
+	)
+
+	BeforeEach(func() {
+		dir, _ = os.MkdirTemp("", "arcade-secret-sync")
+		cfg = `{"secrets": [
+			{"provider": "microsoft", "namespace": "default", "name": "azure-token"},
+			{"provider": "google", "namespace": "default", "name": "gcr", "type": "dockerconfigjson", "registry": "gcr.io"},
+			{"provider": "vault-k8s-np", "cluster": "cluster1", "namespace": "argocd", "name": "cluster1",
+				"type": "argocd-cluster", "server": "https://cluster1.example.com"}
+		]}`
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	JustBeforeEach(func() {
+		path := filepath.Join(dir, "secret-sync.json")
+		_ = os.WriteFile(path, []byte(cfg), 0600)
+
+		secrets, err = secretsync.LoadConfig(path)
+	})
+
+	It("loads the secrets with their defaults", func() {
+		Expect(err).ToNot(HaveOccurred())
+		Expect(secrets).To(HaveLen(3))
+		Expect(secrets[0].Type).To(Equal(secretsync.TypeOpaque))
+		Expect(secrets[0].Key).To(Equal(secretsync.DefaultKey))
+		Expect(secrets[1].Username).To(Equal("oauth2accesstoken"))
+		Expect(secrets[2].ClusterName).To(Equal("vault-k8s-np-cluster1"))
+	})
+
+	When("the configuration has an unknown field", func() {
+		BeforeEach(func() {
+			cfg = `{"secrets": [{"provider": "google", "namespace": "default", "name": "token", "namespce": "x"}]}`
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`unknown field "namespce"`))
+		})
+	})
+
+	When("a secret has no namespace", func() {
+		BeforeEach(func() {
+			cfg = `{"secrets": [{"provider": "google", "name": "token"}]}`
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HaveSuffix("secret 1: a namespace and name are required"))
+		})
+	})
+
+	When("a secret is synced more than once", func() {
+		BeforeEach(func() {
+			cfg = `{"secrets": [
+				{"provider": "google", "namespace": "default", "name": "token"},
+				{"provider": "microsoft", "namespace": "default", "name": "token"}
+			]}`
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HaveSuffix("secret 2: default/token is synced more than once"))
+		})
+	})
+
+	When("the type is unknown", func() {
+		BeforeEach(func() {
+			cfg = `{"secrets": [{"provider": "google", "namespace": "default", "name": "token", "type": "tls"}]}`
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HaveSuffix(`unknown type "tls"`))
+		})
+	})
+
+	When("an argocd-cluster secret has no server", func() {
+		BeforeEach(func() {
+			cfg = `{"secrets": [{"provider": "rancher", "namespace": "argocd", "name": "c1", "type": "argocd-cluster"}]}`
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HaveSuffix("a server is required for argocd-cluster secrets"))
+		})
+	})
+
+	When("no username is known for a registry", func() {
+		BeforeEach(func() {
+			cfg = `{"secrets": [{"provider": "google", "namespace": "default", "name": "pull", "type": "dockerconfigjson", "registry": "registry.example.com"}]}`
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HaveSuffix("no username configured for registry registry.example.com"))
+		})
+	})
+})
@@ -0,0 +1,202 @@
+// Package secretsync syncs the tokens of providers to Kubernetes Secrets,
+// for workloads that consume credentials as Secrets.
+package secretsync
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/homedepot/arcade/internal/audit"
+	"github.com/homedepot/arcade/internal/credentials"
+	arcadehttp "github.com/homedepot/arcade/internal/http"
+	"github.com/homedepot/arcade/internal/tokenwatch"
+)
+
+const (
+	// Caller is the name the syncer requests tokens as, which providers
+	// with an access list must allow.
+	Caller = "secret-sync"
+
+	// LabelManagedBy and LabelProvider label Secrets as synced by arcade
+	// with the token of a provider.
+	LabelManagedBy = "app.kubernetes.io/managed-by"
+	LabelProvider  = "arcade.homedepot.com/provider"
+	// AnnotationExpiry records when the token expires, if known, and
+	// AnnotationFingerprint the token's fingerprint in the audit log.
+	AnnotationExpiry      = "arcade.homedepot.com/expiry"
+	AnnotationFingerprint = "arcade.homedepot.com/token-fingerprint"
+)
+
+// Syncer applies tokens to Secrets, every time their token is refreshed.
+type Syncer struct {
+	watcher tokenwatch.Watcher
+	client  Client
+	secrets map[string][]Secret
+}
+
+// New returns a Syncer applying the tokens watched with w to secrets with
+// c.
+func New(w tokenwatch.Watcher, c Client, secrets []Secret) *Syncer {
+	s := &Syncer{watcher: w, client: c, secrets: map[string][]Secret{}}
+
+	for _, secret := range secrets {
+		s.secrets[secret.provider()] = append(s.secrets[secret.provider()], secret)
+	}
+
+	return s
+}
+
+// Validate returns an error if the secret sync requests tokens from providers
+// that do not exist or do not allow it.
+func (s *Syncer) Validate() error {
+	return tokenwatch.Validate(s.watcher, Caller, slices.Collect(maps.Keys(s.secrets)))
+}
+
+// Run syncs tokens to their Secrets until ctx is done. Providers whose
+// token cannot be requested, and Secrets that cannot be applied, are
+// retried with backoff.
+func (s *Syncer) Run(ctx context.Context) {
+	tokenwatch.Run(ctx, Caller, slices.Collect(maps.Keys(s.secrets)), s.sync)
+}
+
+// sync applies the tokens of a provider to its Secrets until ctx is done.
+// Only the latest token is applied, so that Secrets failing to be applied
+// are retried with the latest token.
+func (s *Syncer) sync(ctx context.Context, name string) {
+	latest := make(chan arcadehttp.TokenResponse, 1)
+	watched := make(chan struct{})
+
+	go func() {
+		defer close(watched)
+
+		tokenwatch.Watch(ctx, s.watcher, name, func(t arcadehttp.TokenResponse) {
+			select {
+			case <-latest:
+			default:
+			}
+
+			latest <- t
+		})
+	}()
+
+	defer func() { <-watched }()
+
+	var (
+		t       arcadehttp.TokenResponse
+		pending []Secret
+		retry   <-chan time.Time
+	)
+
+	backoff := tokenwatch.MinBackoff
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t = <-latest:
+			pending = s.secrets[name]
+			backoff = tokenwatch.MinBackoff
+		case <-retry:
+			backoff = min(2*backoff, tokenwatch.MaxBackoff)
+		}
+
+		pending = s.apply(ctx, name, t, pending)
+
+		retry = nil
+		if len(pending) > 0 {
+			retry = time.After(backoff)
+		}
+	}
+}
+
+// apply applies t to secrets, returning those that failed to be applied.
+func (s *Syncer) apply(ctx context.Context, name string, t arcadehttp.TokenResponse, secrets []Secret) []Secret {
+	var failed []Secret
+
+	for _, secret := range secrets {
+		ks, err := secret.kubernetesSecret(name, t)
+		if err == nil {
+			err = s.client.ApplySecret(ctx, ks)
+		}
+
+		if err != nil {
+			slog.ErrorContext(ctx, "error syncing token to secret", slog.String("secret", secret.String()), slog.Any("error", err))
+
+			failed = append(failed, secret)
+
+			continue
+		}
+
+		slog.InfoContext(ctx, "synced token to secret", slog.String("secret", secret.String()))
+	}
+
+	return failed
+}
+
+// kubernetesSecret returns the Kubernetes Secret holding t, the token of
+// the named provider.
+func (s Secret) kubernetesSecret(name string, t arcadehttp.TokenResponse) (*KubernetesSecret, error) {
+	ks := &KubernetesSecret{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata: ObjectMeta{
+			Name:        s.Name,
+			Namespace:   s.Namespace,
+			Labels:      map[string]string{},
+			Annotations: map[string]string{},
+		},
+		Type: "Opaque",
+		Data: map[string][]byte{},
+	}
+
+	for k, v := range s.Labels {
+		ks.Metadata.Labels[k] = v
+	}
+
+	for k, v := range s.Annotations {
+		ks.Metadata.Annotations[k] = v
+	}
+
+	ks.Metadata.Labels[LabelManagedBy] = fieldManager
+	ks.Metadata.Labels[LabelProvider] = name
+	ks.Metadata.Annotations[AnnotationFingerprint] = audit.Fingerprint(t.Token)
+
+	if !t.Expiry.IsZero() {
+		ks.Metadata.Annotations[AnnotationExpiry] = t.Expiry.UTC().Format(time.RFC3339)
+	}
+
+	switch s.Type {
+	case TypeDockerConfigJSON:
+		b, err := credentials.DockerConfigJSON(s.Registry, s.Username, t.Token)
+		if err != nil {
+			return nil, err
+		}
+
+		ks.Type = "kubernetes.io/dockerconfigjson"
+		ks.Data[".dockerconfigjson"] = b
+	case TypeArgoCDCluster:
+		config := map[string]any{"bearerToken": t.Token}
+		if s.CertificateAuthorityData != "" {
+			config["tlsClientConfig"] = map[string]any{"caData": s.CertificateAuthorityData}
+		}
+
+		b, err := json.Marshal(config)
+		if err != nil {
+			return nil, err
+		}
+
+		// Argo CD finds cluster Secrets by this label.
+		ks.Metadata.Labels["argocd.argoproj.io/secret-type"] = "cluster"
+		ks.Data["name"] = []byte(s.ClusterName)
+		ks.Data["server"] = []byte(s.Server)
+		ks.Data["config"] = b
+	default:
+		ks.Data[s.Key] = []byte(t.Token)
+	}
+
+	return ks, nil
+}
@@ -0,0 +1,13 @@
+package secretsync_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestSecretSync(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Secret Sync Suite")
+}
@@ -0,0 +1,186 @@
+package secretsync_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/homedepot/arcade/internal/audit"
+	arcadehttp "github.com/homedepot/arcade/internal/http"
+	"github.com/homedepot/arcade/internal/secretsync"
+	"github.com/homedepot/arcade/internal/secretsync/secretsyncfakes"
+	"github.com/homedepot/arcade/internal/tokenwatch/tokenwatchfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Syncer", func() {
+	var (
+		secrets []secretsync.Secret
+		watcher *tokenwatchfakes.FakeWatcher
+		client  *secretsyncfakes.FakeClient
+		cancel  context.CancelFunc
+		done    chan struct{}
+		expiry  time.Time
+	)
+
+	BeforeEach(func() {
+		watcher = tokenwatchfakes.NewFakeWatcher()
+		client = &secretsyncfakes.FakeClient{}
+		expiry = time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+		secrets = []secretsync.Secret{{
+			Provider:    "microsoft",
+			Namespace:   "default",
+			Name:        "azure-token",
+			Type:        secretsync.TypeOpaque,
+			Key:         "token",
+			Labels:      map[string]string{"team": "platform"},
+			Annotations: map[string]string{"owner": "platform@example.com"},
+		}}
+	})
+
+	AfterEach(func() {
+		cancel()
+		Eventually(done).Should(BeClosed())
+	})
+
+	JustBeforeEach(func() {
+		var ctx context.Context
+
+		ctx, cancel = context.WithCancel(context.Background())
+		done = make(chan struct{})
+
+		s := secretsync.New(watcher, client, secrets)
+
+		go func() {
+			defer close(done)
+
+			s.Run(ctx)
+		}()
+	})
+
+	It("applies every token to the secret as the secret sync", func() {
+		watcher.Tokens <- arcadehttp.TokenResponse{Token: "token-1", Expiry: expiry}
+		Eventually(client.ApplySecretCallCount).Should(Equal(1))
+
+		_, s := client.ApplySecretArgsForCall(0)
+		Expect(*s).To(Equal(secretsync.KubernetesSecret{
+			APIVersion: "v1",
+			Kind:       "Secret",
+			Metadata: secretsync.ObjectMeta{
+				Name:      "azure-token",
+				Namespace: "default",
+				Labels: map[string]string{
+					"team":                    "platform",
+					secretsync.LabelManagedBy: "arcade",
+					secretsync.LabelProvider:  "microsoft",
+				},
+				Annotations: map[string]string{
+					"owner":                          "platform@example.com",
+					secretsync.AnnotationExpiry:      "2030-01-02T03:04:05Z",
+					secretsync.AnnotationFingerprint: audit.Fingerprint("token-1"),
+				},
+			},
+			Type: "Opaque",
+			Data: map[string][]byte{"token": []byte("token-1")},
+		}))
+
+		watcher.Tokens <- arcadehttp.TokenResponse{Token: "token-2"}
+		Eventually(client.ApplySecretCallCount).Should(Equal(2))
+
+		_, s = client.ApplySecretArgsForCall(1)
+		Expect(s.Data["token"]).To(Equal([]byte("token-2")))
+		Expect(s.Metadata.Annotations).ToNot(HaveKey(secretsync.AnnotationExpiry))
+
+		Expect(watcher.Requests()).To(ConsistOf(arcadehttp.TokenRequest{Provider: "microsoft"}))
+		Expect(watcher.Callers()).To(ConsistOf(secretsync.Caller))
+	})
+
+	When("a provider cannot be accessed", func() {
+		BeforeEach(func() {
+			watcher.AccessErrs = map[string]error{"microsoft": errors.New("Token provider not allowed: microsoft")}
+		})
+
+		It("fails validation", func() {
+			Expect(secretsync.New(watcher, client, secrets).Validate()).To(MatchError("Token provider not allowed: microsoft"))
+		})
+	})
+
+	When("the secret is an image pull secret", func() {
+		BeforeEach(func() {
+			secrets = []secretsync.Secret{{
+				Provider:  "google",
+				Namespace: "default",
+				Name:      "gcr",
+				Type:      secretsync.TypeDockerConfigJSON,
+				Registry:  "gcr.io",
+				Username:  "oauth2accesstoken",
+			}}
+		})
+
+		It("applies a dockerconfigjson secret", func() {
+			watcher.Tokens <- arcadehttp.TokenResponse{Token: "token-1"}
+			Eventually(client.ApplySecretCallCount).Should(Equal(1))
+
+			_, s := client.ApplySecretArgsForCall(0)
+			Expect(s.Type).To(Equal("kubernetes.io/dockerconfigjson"))
+			Expect(s.Data[".dockerconfigjson"]).To(MatchJSON(`{"auths": {"gcr.io": {
+				"username": "oauth2accesstoken",
+				"password": "token-1",
+				"auth": "b2F1dGgyYWNjZXNzdG9rZW46dG9rZW4tMQ=="
+			}}}`))
+		})
+	})
+
+	When("the secret is an Argo CD cluster", func() {
+		BeforeEach(func() {
+			secrets = []secretsync.Secret{{
+				Provider:                 "vault-k8s-np",
+				Cluster:                  "cluster1",
+				Namespace:                "argocd",
+				Name:                     "cluster1",
+				Type:                     secretsync.TypeArgoCDCluster,
+				Server:                   "https://cluster1.example.com",
+				CertificateAuthorityData: "Y2E=",
+				ClusterName:              "cluster1",
+			}}
+		})
+
+		It("applies an Argo CD cluster secret", func() {
+			Eventually(watcher.Requests).Should(ConsistOf(arcadehttp.TokenRequest{Provider: "vault-k8s-np-cluster1"}))
+
+			watcher.Tokens <- arcadehttp.TokenResponse{Token: "token-1"}
+			Eventually(client.ApplySecretCallCount).Should(Equal(1))
+
+			_, s := client.ApplySecretArgsForCall(0)
+			Expect(s.Metadata.Labels).To(HaveKeyWithValue("argocd.argoproj.io/secret-type", "cluster"))
+			Expect(s.Metadata.Labels).To(HaveKeyWithValue(secretsync.LabelProvider, "vault-k8s-np-cluster1"))
+			Expect(string(s.Data["name"])).To(Equal("cluster1"))
+			Expect(string(s.Data["server"])).To(Equal("https://cluster1.example.com"))
+
+			var config map[string]any
+			Expect(json.Unmarshal(s.Data["config"], &config)).To(Succeed())
+			Expect(config).To(Equal(map[string]any{
+				"bearerToken":     "token-1",
+				"tlsClientConfig": map[string]any{"caData": "Y2E="},
+			}))
+		})
+	})
+
+	When("the secret cannot be applied", func() {
+		BeforeEach(func() {
+			client.ApplySecretReturnsOnCall(0, errors.New("error applying secret"))
+		})
+
+		It("retries with the latest token", func() {
+			watcher.Tokens <- arcadehttp.TokenResponse{Token: "token-1"}
+			Eventually(client.ApplySecretCallCount, 2*time.Second).Should(Equal(2))
+
+			_, s := client.ApplySecretArgsForCall(1)
+			Expect(s.Data["token"]).To(Equal([]byte("token-1")))
+
+			Consistently(client.ApplySecretCallCount, 500*time.Millisecond).Should(Equal(2))
+		})
+	})
+})
@@ -0,0 +1,114 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package secretsyncfakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/homedepot/arcade/internal/secretsync"
+)
+
+type FakeClient struct {
+	ApplySecretStub        func(context.Context, *secretsync.KubernetesSecret) error
+	applySecretMutex       sync.RWMutex
+	applySecretArgsForCall []struct {
+		arg1 context.Context
+		arg2 *secretsync.KubernetesSecret
+	}
+	applySecretReturns struct {
+		result1 error
+	}
+	applySecretReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeClient) ApplySecret(arg1 context.Context, arg2 *secretsync.KubernetesSecret) error {
+	fake.applySecretMutex.Lock()
+	ret, specificReturn := fake.applySecretReturnsOnCall[len(fake.applySecretArgsForCall)]
+	fake.applySecretArgsForCall = append(fake.applySecretArgsForCall, struct {
+		arg1 context.Context
+		arg2 *secretsync.KubernetesSecret
+	}{arg1, arg2})
+	stub := fake.ApplySecretStub
+	fakeReturns := fake.applySecretReturns
+	fake.recordInvocation("ApplySecret", []interface{}{arg1, arg2})
+	fake.applySecretMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClient) ApplySecretCallCount() int {
+	fake.applySecretMutex.RLock()
+	defer fake.applySecretMutex.RUnlock()
+	return len(fake.applySecretArgsForCall)
+}
+
+func (fake *FakeClient) ApplySecretCalls(stub func(context.Context, *secretsync.KubernetesSecret) error) {
+	fake.applySecretMutex.Lock()
+	defer fake.applySecretMutex.Unlock()
+	fake.ApplySecretStub = stub
+}
+
+func (fake *FakeClient) ApplySecretArgsForCall(i int) (context.Context, *secretsync.KubernetesSecret) {
+	fake.applySecretMutex.RLock()
+	defer fake.applySecretMutex.RUnlock()
+	argsForCall := fake.applySecretArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeClient) ApplySecretReturns(result1 error) {
+	fake.applySecretMutex.Lock()
+	defer fake.applySecretMutex.Unlock()
+	fake.ApplySecretStub = nil
+	fake.applySecretReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) ApplySecretReturnsOnCall(i int, result1 error) {
+	fake.applySecretMutex.Lock()
+	defer fake.applySecretMutex.Unlock()
+	fake.ApplySecretStub = nil
+	if fake.applySecretReturnsOnCall == nil {
+		fake.applySecretReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.applySecretReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.applySecretMutex.RLock()
+	defer fake.applySecretMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ secretsync.Client = new(FakeClient)
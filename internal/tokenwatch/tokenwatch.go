@@ -0,0 +1,146 @@
+// Package tokenwatch watches the tokens of providers in the background,
+// for sinks delivering them outside of the API, such as to files or
+// Kubernetes Secrets.
+package tokenwatch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	arcadehttp "github.com/homedepot/arcade/internal/http"
+	"github.com/homedepot/arcade/internal/logging"
+	"github.com/homedepot/arcade/internal/middleware"
+)
+
+const (
+	// MinBackoff and MaxBackoff bound how long sinks wait before retrying
+	// after a failure.
+	MinBackoff = time.Second
+	MaxBackoff = time.Minute
+)
+
+// Watcher watches the tokens of providers, as the Controller does.
+type Watcher interface {
+	WatchToken(context.Context, arcadehttp.TokenRequest, func(arcadehttp.TokenResponse) error) error
+	// CheckAccess returns an error if caller may not request tokens from
+	// the named provider.
+	CheckAccess(caller, provider string) error
+}
+
+// Validate returns an error for each of the named providers caller may not
+// request tokens from, so that sinks misconfigured with unknown or
+// forbidden providers fail to start.
+func Validate(w Watcher, caller string, names []string) error {
+	var errs []error
+
+	for _, name := range slices.Sorted(slices.Values(names)) {
+		if err := w.CheckAccess(caller, name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Run calls watch for each of the named providers concurrently, until
+// every call returns. Each call is passed a context requesting tokens as
+// caller, which logs the caller and the provider.
+func Run(ctx context.Context, caller string, names []string, watch func(ctx context.Context, name string)) {
+	var wg sync.WaitGroup
+
+	for _, name := range names {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			ctx := middleware.ContextWithCaller(logging.NewContext(ctx), caller)
+			logging.AddAttrs(ctx, slog.String(logging.KeyCaller, caller), slog.String(logging.KeyProvider, name))
+
+			watch(ctx, name)
+		}()
+	}
+
+	wg.Wait()
+}
+
+// Watch calls send with each token of the named provider until ctx is
+// done. Watches that fail are retried with backoff, unless the request
+// itself is rejected, such as for an unknown provider or a caller it does
+// not allow, as retrying would fail the same way.
+func Watch(ctx context.Context, w Watcher, name string, send func(arcadehttp.TokenResponse)) {
+	backoff := MinBackoff
+
+	for {
+		err := w.WatchToken(ctx, arcadehttp.TokenRequest{Provider: name}, func(t arcadehttp.TokenResponse) error {
+			backoff = MinBackoff
+
+			send(t)
+
+			return nil
+		})
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if permanent(err) {
+			slog.ErrorContext(ctx, "stopped watching token", slog.Any("error", err))
+
+			return
+		}
+
+		slog.ErrorContext(ctx, "error watching token", slog.Any("error", err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(2*backoff, MaxBackoff)
+	}
+}
+
+// permanent reports whether err rejects the request itself, rather than
+// being caused by a rate limit or the upstream provider.
+func permanent(err error) bool {
+	var e *arcadehttp.Error
+	if !errors.As(err, &e) {
+		return false
+	}
+
+	return e.Status < http.StatusInternalServerError && e.Status != http.StatusTooManyRequests
+}
+
+// Provider returns the name a provider is requested by, followed by the
+// cluster of Vault K8s providers.
+func Provider(name, cluster string) string {
+	if cluster == "" {
+		return name
+	}
+
+	return name + "-" + cluster
+}
+
+// LoadConfig decodes the JSON configuration file at path into v,
+// rejecting unknown fields so that misspelt settings are not ignored.
+func LoadConfig(path string, v any) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+
+	return d.Decode(v)
+}
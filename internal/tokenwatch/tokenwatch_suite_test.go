@@ -0,0 +1,13 @@
+package tokenwatch_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestTokenWatch(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Token Watch Suite")
+}
@@ -0,0 +1,164 @@
+package tokenwatch_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	arcadehttp "github.com/homedepot/arcade/internal/http"
+	"github.com/homedepot/arcade/internal/middleware"
+	"github.com/homedepot/arcade/internal/tokenwatch"
+	"github.com/homedepot/arcade/internal/tokenwatch/tokenwatchfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tokenwatch", func() {
+	Describe("#Run", func() {
+		It("runs each provider with a context requesting tokens as the caller", func() {
+			var (
+				mux     sync.Mutex
+				callers = map[string]string{}
+			)
+
+			tokenwatch.Run(context.Background(), "file-sink", []string{"google", "rancher"}, func(ctx context.Context, name string) {
+				mux.Lock()
+				defer mux.Unlock()
+
+				callers[name] = middleware.CallerFromContext(ctx)
+			})
+
+			Expect(callers).To(Equal(map[string]string{"google": "file-sink", "rancher": "file-sink"}))
+		})
+	})
+
+	Describe("#Watch", func() {
+		var (
+			watcher *tokenwatchfakes.FakeWatcher
+			tokens  chan arcadehttp.TokenResponse
+			cancel  context.CancelFunc
+			done    chan struct{}
+		)
+
+		BeforeEach(func() {
+			watcher = tokenwatchfakes.NewFakeWatcher()
+			tokens = make(chan arcadehttp.TokenResponse, 10)
+		})
+
+		AfterEach(func() {
+			cancel()
+			Eventually(done).Should(BeClosed())
+		})
+
+		JustBeforeEach(func() {
+			var ctx context.Context
+
+			ctx, cancel = context.WithCancel(context.Background())
+			done = make(chan struct{})
+
+			go func() {
+				defer close(done)
+
+				tokenwatch.Watch(ctx, watcher, "vault-k8s-np-cluster1", func(t arcadehttp.TokenResponse) {
+					tokens <- t
+				})
+			}()
+		})
+
+		It("sends every token of the provider", func() {
+			watcher.Tokens <- arcadehttp.TokenResponse{Token: "token-1"}
+			watcher.Tokens <- arcadehttp.TokenResponse{Token: "token-2"}
+
+			Eventually(tokens).Should(Receive(Equal(arcadehttp.TokenResponse{Token: "token-1"})))
+			Eventually(tokens).Should(Receive(Equal(arcadehttp.TokenResponse{Token: "token-2"})))
+			Expect(watcher.Requests()).To(ConsistOf(arcadehttp.TokenRequest{Provider: "vault-k8s-np-cluster1"}))
+		})
+
+		When("the provider rejects the request", func() {
+			BeforeEach(func() {
+				watcher.Err = &arcadehttp.Error{Status: http.StatusForbidden, Code: arcadehttp.ErrorCodeForbidden}
+			})
+
+			It("stops watching", func() {
+				Eventually(done).Should(BeClosed())
+				Expect(watcher.Requests()).To(HaveLen(1))
+			})
+		})
+
+		When("the request is rate limited", func() {
+			BeforeEach(func() {
+				watcher.Err = &arcadehttp.Error{Status: http.StatusTooManyRequests, Code: arcadehttp.ErrorCodeRateLimited}
+			})
+
+			It("retries", func() {
+				Eventually(watcher.Requests, 2*time.Second).Should(HaveLen(2))
+			})
+		})
+
+		When("the token cannot be watched", func() {
+			BeforeEach(func() {
+				watcher.Err = errors.New("error getting token")
+			})
+
+			It("retries with backoff", func() {
+				Eventually(watcher.Requests, 2*time.Second).Should(HaveLen(2))
+				Consistently(watcher.Requests, 500*time.Millisecond).Should(HaveLen(2))
+			})
+		})
+	})
+
+	Describe("#Validate", func() {
+		It("returns an error for each provider the caller may not access", func() {
+			watcher := tokenwatchfakes.NewFakeWatcher()
+			watcher.AccessErrs = map[string]error{
+				"fake":    errors.New("Unsupported token provider: fake"),
+				"rancher": errors.New("Token provider not allowed: rancher"),
+			}
+
+			err := tokenwatch.Validate(watcher, "file-sink", []string{"rancher", "google", "fake"})
+			Expect(err).To(MatchError("Unsupported token provider: fake\nToken provider not allowed: rancher"))
+			Expect(tokenwatch.Validate(watcher, "file-sink", []string{"google"})).To(Succeed())
+		})
+	})
+
+	Describe("#Provider", func() {
+		It("appends the cluster, if any", func() {
+			Expect(tokenwatch.Provider("google", "")).To(Equal("google"))
+			Expect(tokenwatch.Provider("vault-k8s-np", "cluster1")).To(Equal("vault-k8s-np-cluster1"))
+		})
+	})
+
+	Describe("#LoadConfig", func() {
+		var (
+			dir  string
+			path string
+			cfg  struct {
+				Files []string `json:"files"`
+			}
+		)
+
+		BeforeEach(func() {
+			dir, _ = os.MkdirTemp("", "arcade-token-watch")
+			path = filepath.Join(dir, "config.json")
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("decodes the file", func() {
+			Expect(os.WriteFile(path, []byte(`{"files": ["token"]}`), 0600)).To(Succeed())
+			Expect(tokenwatch.LoadConfig(path, &cfg)).To(Succeed())
+			Expect(cfg.Files).To(Equal([]string{"token"}))
+		})
+
+		It("rejects unknown fields", func() {
+			Expect(os.WriteFile(path, []byte(`{"fils": ["token"]}`), 0600)).To(Succeed())
+			Expect(tokenwatch.LoadConfig(path, &cfg)).To(MatchError(ContainSubstring(`unknown field "fils"`)))
+		})
+	})
+})
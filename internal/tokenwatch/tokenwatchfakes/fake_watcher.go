@@ -0,0 +1,74 @@
+// Package tokenwatchfakes provides a fake tokenwatch.Watcher for tests.
+package tokenwatchfakes
+
+import (
+	"context"
+	"sync"
+
+	arcadehttp "github.com/homedepot/arcade/internal/http"
+	"github.com/homedepot/arcade/internal/middleware"
+	"github.com/homedepot/arcade/internal/tokenwatch"
+)
+
+// FakeWatcher sends the tokens written to Tokens to every watch, or fails
+// every watch with Err if set. Callers may access every provider but
+// those in AccessErrs.
+type FakeWatcher struct {
+	Tokens     chan arcadehttp.TokenResponse
+	Err        error
+	AccessErrs map[string]error
+
+	mux     sync.Mutex
+	callers []string
+	reqs    []arcadehttp.TokenRequest
+}
+
+var _ tokenwatch.Watcher = &FakeWatcher{}
+
+// NewFakeWatcher returns a FakeWatcher whose tokens are sent unbuffered.
+func NewFakeWatcher() *FakeWatcher {
+	return &FakeWatcher{Tokens: make(chan arcadehttp.TokenResponse)}
+}
+
+func (w *FakeWatcher) WatchToken(ctx context.Context, req arcadehttp.TokenRequest, send func(arcadehttp.TokenResponse) error) error {
+	w.mux.Lock()
+	w.callers = append(w.callers, middleware.CallerFromContext(ctx))
+	w.reqs = append(w.reqs, req)
+	err := w.Err
+	w.mux.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case t := <-w.Tokens:
+			if err := send(t); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (w *FakeWatcher) CheckAccess(_, provider string) error {
+	return w.AccessErrs[provider]
+}
+
+// Requests returns the requests of every watch so far.
+func (w *FakeWatcher) Requests() []arcadehttp.TokenRequest {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	return append([]arcadehttp.TokenRequest(nil), w.reqs...)
+}
+
+// Callers returns the caller of every watch so far.
+func (w *FakeWatcher) Callers() []string {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	return append([]string(nil), w.callers...)
+}
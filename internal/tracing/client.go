@@ -0,0 +1,59 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"github.com/homedepot/arcade/internal/logging"
+	"github.com/homedepot/arcade/pkg/provider"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Instrument wraps a token provider client, recording each token request
+// as a span noting whether the token came from cache. Upstream refreshes
+// appear as child spans.
+func Instrument(name string, c provider.Client) provider.Client {
+	return &tracedClient{
+		client: c,
+		name:   name,
+	}
+}
+
+type tracedClient struct {
+	client provider.Client
+	name   string
+}
+
+func (t *tracedClient) Token(ctx context.Context) (string, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "arcade.token",
+		trace.WithAttributes(attribute.String("arcade.provider", t.name)))
+	defer span.End()
+
+	refreshed := false
+	ctx = provider.WithTrace(ctx, &provider.Trace{
+		RefreshStart: func() {
+			refreshed = true
+		},
+	})
+
+	token, err := t.client.Token(ctx)
+	if err != nil {
+		// Errors may quote upstream responses.
+		msg := logging.Redact(err.Error())
+		span.RecordError(errors.New(msg))
+		span.SetStatus(codes.Error, msg)
+
+		return "", err
+	}
+
+	span.SetAttributes(attribute.Bool("arcade.cache.hit", !refreshed))
+
+	return token, nil
+}
+
+func (t *tracedClient) Unwrap() provider.Client {
+	return t.client
+}
@@ -0,0 +1,116 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	tracerName  = "github.com/homedepot/arcade/internal/tracing"
+	serviceName = "arcade"
+)
+
+// Setup configures the global propagator to use W3C trace context and
+// baggage, and configures the global tracer provider to export spans with
+// OTLP if an exporter is configured with the standard OTEL_* environment
+// variables. Tracing is a no-op otherwise. The returned function flushes
+// and stops the exporter.
+func Setup(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !exporterConfigured() {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := newExporter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error creating trace exporter: %w", err)
+	}
+
+	// Attributes from OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES take
+	// precedence over the defaults.
+	res, err := resource.Merge(
+		resource.NewSchemaless(semconv.ServiceName(serviceName)),
+		resource.Environment(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating trace resource: %w", err)
+	}
+
+	// The sampler is configured with OTEL_TRACES_SAMPLER.
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
+
+// exporterConfigured reports whether the environment configures an OTLP
+// trace exporter.
+func exporterConfigured() bool {
+	switch strings.ToLower(os.Getenv("OTEL_TRACES_EXPORTER")) {
+	case "otlp":
+		return true
+	case "none":
+		return false
+	}
+
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" ||
+		os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+func newExporter(ctx context.Context) (*otlptrace.Exporter, error) {
+	protocol := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL")
+	if protocol == "" {
+		protocol = os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	}
+
+	switch protocol {
+	case "grpc":
+		return otlptracegrpc.New(ctx)
+	case "", "http/protobuf":
+		return otlptracehttp.New(ctx)
+	}
+
+	return nil, fmt.Errorf("unsupported OTLP protocol %q", protocol)
+}
+
+// NewHandler wraps h to start a server span for each request, continuing
+// any trace propagated in the request headers. Requests to the untraced
+// paths, such as health checks, are not traced. Untraced paths are
+// path.Match patterns, such as /v1/providers/*/token/watch.
+func NewHandler(h http.Handler, untracedPaths ...string) http.Handler {
+	return otelhttp.NewHandler(h, serviceName,
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			for _, p := range untracedPaths {
+				if ok, _ := path.Match(p, r.URL.Path); ok {
+					return false
+				}
+			}
+
+			return true
+		}),
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method + " " + r.URL.Path
+		}),
+	)
+}
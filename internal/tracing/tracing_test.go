@@ -0,0 +1,168 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	"github.com/homedepot/arcade/internal/tracing"
+	"github.com/homedepot/arcade/pkg/provider"
+	"github.com/homedepot/arcade/pkg/provider/providerfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var _ = Describe("Tracing", func() {
+	var (
+		recorder *tracetest.SpanRecorder
+		tp       *sdktrace.TracerProvider
+	)
+
+	BeforeEach(func() {
+		recorder = tracetest.NewSpanRecorder()
+		tp = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		otel.SetTracerProvider(tp)
+	})
+
+	AfterEach(func() {
+		_ = tp.Shutdown(context.Background())
+	})
+
+	attributes := func(s sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+		attrs := map[attribute.Key]attribute.Value{}
+		for _, kv := range s.Attributes() {
+			attrs[kv.Key] = kv.Value
+		}
+
+		return attrs
+	}
+
+	Describe("#Setup", func() {
+		When("no exporter is configured", func() {
+			It("succeeds with a no-op shutdown", func() {
+				shutdown, err := tracing.Setup(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(shutdown(context.Background())).To(Succeed())
+			})
+		})
+
+		When("the protocol is not supported", func() {
+			BeforeEach(func() {
+				os.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+				os.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "smoke-signals")
+			})
+
+			AfterEach(func() {
+				os.Unsetenv("OTEL_TRACES_EXPORTER")
+				os.Unsetenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+			})
+
+			It("returns an error", func() {
+				_, err := tracing.Setup(context.Background())
+				Expect(err).To(MatchError(`error creating trace exporter: unsupported OTLP protocol "smoke-signals"`))
+			})
+		})
+	})
+
+	Describe("#Instrument", func() {
+		var (
+			fakeClient *providerfakes.FakeClient
+			client     provider.Client
+			err        error
+		)
+
+		BeforeEach(func() {
+			fakeClient = &providerfakes.FakeClient{}
+			fakeClient.TokenReturns("cached-token", nil)
+			client = tracing.Instrument("test-provider", fakeClient)
+		})
+
+		JustBeforeEach(func() {
+			_, err = client.Token(context.Background())
+		})
+
+		When("the token is served from cache", func() {
+			It("records a token span", func() {
+				Expect(err).ToNot(HaveOccurred())
+				spans := recorder.Ended()
+				Expect(spans).To(HaveLen(1))
+				Expect(spans[0].Name()).To(Equal("arcade.token"))
+				attrs := attributes(spans[0])
+				Expect(attrs["arcade.provider"].AsString()).To(Equal("test-provider"))
+				Expect(attrs["arcade.cache.hit"].AsBool()).To(BeTrue())
+			})
+		})
+
+		When("the client refreshes the token", func() {
+			BeforeEach(func() {
+				fakeClient.TokenStub = func(ctx context.Context) (string, error) {
+					err := provider.Refresh(ctx, func(context.Context) (time.Time, error) {
+						return time.Now().Add(time.Hour), nil
+					})
+
+					return "new-token", err
+				}
+			})
+
+			It("records the refresh as a child span", func() {
+				Expect(err).ToNot(HaveOccurred())
+				spans := recorder.Ended()
+				Expect(spans).To(HaveLen(2))
+				Expect(spans[0].Name()).To(Equal("arcade.refresh"))
+				Expect(spans[0].Parent().SpanID()).To(Equal(spans[1].SpanContext().SpanID()))
+				Expect(attributes(spans[0])).To(HaveKey(attribute.Key("arcade.token.expiry")))
+				Expect(attributes(spans[1])["arcade.cache.hit"].AsBool()).To(BeFalse())
+			})
+		})
+
+		When("the client fails", func() {
+			BeforeEach(func() {
+				fakeClient.TokenReturns("", errors.New("login failed for password=hunter2"))
+			})
+
+			It("records the redacted error", func() {
+				Expect(err).To(HaveOccurred())
+				spans := recorder.Ended()
+				Expect(spans).To(HaveLen(1))
+				Expect(spans[0].Status().Code).To(Equal(codes.Error))
+				Expect(spans[0].Status().Description).To(Equal("login failed for password=[REDACTED]"))
+			})
+		})
+	})
+
+	Describe("#NewHandler", func() {
+		var handler http.Handler
+
+		BeforeEach(func() {
+			_, err := tracing.Setup(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			handler = tracing.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}), "/healthz", "/v1/providers/*/token/watch")
+		})
+
+		It("continues the trace propagated in the request", func() {
+			req := httptest.NewRequest(http.MethodGet, "/tokens", nil)
+			req.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			spans := recorder.Ended()
+			Expect(spans).To(HaveLen(1))
+			Expect(spans[0].Name()).To(Equal("GET /tokens"))
+			Expect(spans[0].SpanContext().TraceID().String()).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+		})
+
+		It("does not trace untraced paths", func() {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/providers/google/token/watch", nil))
+			Expect(recorder.Ended()).To(BeEmpty())
+		})
+	})
+})
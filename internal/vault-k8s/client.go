@@ -8,10 +8,10 @@ import (
 	"net/url"
 	"os"
 	"strings"
-	"sync"
 
 	"github.com/hashicorp/vault/api"
 	"github.com/homedepot/arcade/pkg/provider"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 const (
@@ -22,54 +22,35 @@ const (
 
 func NewClient() *Client {
 	return &Client{
-		c: &http.Client{},
+		c: &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)},
 	}
 }
 
 type Client struct {
-	c           *http.Client
-	mux         sync.Mutex
-	password    string
-	url         string
-	vaultClient *api.Client
+	c        *http.Client
+	password string
+	url      string
 }
 
-func (c *Client) Token(ctx context.Context) (string, error) {
-	c.mux.Lock()
-	defer c.mux.Unlock()
-
-	// Configure Vault client
-	config := &api.Config{
-		Address:    c.url,
-		HttpClient: c.c,
-	}
-
-	client, err := api.NewClient(config)
-	if err != nil {
-		return "", fmt.Errorf("error creating vault client: %w", err)
-	}
-
-	c.vaultClient = client
-
-	c.vaultClient.SetToken(c.password)
-
+// Fetch reads the token of the cluster named in ctx from Vault.
+func (c *Client) Fetch(ctx context.Context) (provider.Token, error) {
 	var cluster_name string
 
 	var ok bool
 
 	if val := ctx.Value(provider.ProviderKey); val != nil {
 		if cluster_name, ok = val.(string); !ok {
-			return "", fmt.Errorf("invalid cluster name in context")
+			return provider.Token{}, fmt.Errorf("invalid cluster name in context")
 		}
 	} else {
-		return "", fmt.Errorf("cluster name not found in context")
+		return provider.Token{}, fmt.Errorf("cluster name not found in context")
 	}
 
 	// If the clustername starts with "vault-k8s-" followed by a two character lifecycle code then remove that prefix to obtain the cluster name
 	if len(cluster_name) >= ( len(ProviderTypeVaultK8s) + lifecycleWithDash ) {
 		cluster_name = cluster_name[len(ProviderTypeVaultK8s)+prefixToStrip:]
 	} else {
-		return "", fmt.Errorf("invalid cluster name format")
+		return provider.Token{}, fmt.Errorf("invalid cluster name format")
 	}
 
 	vault_pattern := os.Getenv("VAULT_K8S_PATH_PATTERN")
@@ -79,13 +60,37 @@ func (c *Client) Token(ctx context.Context) (string, error) {
 
 	vault_path := strings.ReplaceAll(vault_pattern, "[CLUSTER]", cluster_name)
 
+	token, err := c.read(ctx, vault_path)
+	if err != nil {
+		return provider.Token{}, err
+	}
+	// Kubeconfig tokens stored in Vault carry no expiration.
+	return provider.Token{AccessToken: token}, nil
+}
+
+// read reads the kubeconfig stored at the given path in Vault and returns
+// the token of its first user.
+func (c *Client) read(ctx context.Context, vault_path string) (string, error) {
+	// Configure Vault client
+	config := &api.Config{
+		Address:    c.url,
+		HttpClient: c.c,
+	}
+
+	client, err := api.NewClient(config)
+	if err != nil {
+		return "", fmt.Errorf("error creating vault client: %w", err)
+	}
+
+	client.SetToken(c.password)
+
 	vault_uri, err := url.Parse(vault_path)
 	if err != nil {
 		return "", fmt.Errorf("error parsing vault url: %w", err)
 	}
 
 	// Read kubeconfig from Vault
-	secret, err := c.vaultClient.Logical().Read(vault_uri.String())
+	secret, err := client.Logical().ReadWithContext(ctx, vault_uri.String())
 	if err != nil {
 		return "", fmt.Errorf("error reading kubeconfig from vault: %w", err)
 	}
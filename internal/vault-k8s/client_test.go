@@ -36,11 +36,15 @@ var _ = Describe("Client", func() {
 		server.Close()
 	})
 
-	Describe("#Token", func() {
+	Describe("#Fetch", func() {
 		JustBeforeEach(func() {
 			t := testing.T{}
 			t.Setenv("VAULT_K8S_PATH_PATTERN", "secret/data/[CLUSTER]/vault-k8s-user")
-			token, err = client.Token(ctx)
+
+			var fetched provider.Token
+
+			fetched, err = client.Fetch(ctx)
+			token = fetched.AccessToken
 		})
 
 		When("it succeeds", func() {
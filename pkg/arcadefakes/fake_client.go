@@ -2,12 +2,110 @@
 package arcadefakes
 
 import (
+	"context"
 	"sync"
+	"time"
 
 	arcade "github.com/homedepot/arcade/pkg"
 )
 
 type FakeClient struct {
+	InvalidateTokenStub        func(string) error
+	invalidateTokenMutex       sync.RWMutex
+	invalidateTokenArgsForCall []struct {
+		arg1 string
+	}
+	invalidateTokenReturns struct {
+		result1 error
+	}
+	invalidateTokenReturnsOnCall map[int]struct {
+		result1 error
+	}
+	InvalidateTokenContextStub        func(context.Context, string) error
+	invalidateTokenContextMutex       sync.RWMutex
+	invalidateTokenContextArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	invalidateTokenContextReturns struct {
+		result1 error
+	}
+	invalidateTokenContextReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ProvidersStub        func() ([]arcade.Provider, error)
+	providersMutex       sync.RWMutex
+	providersArgsForCall []struct {
+	}
+	providersReturns struct {
+		result1 []arcade.Provider
+		result2 error
+	}
+	providersReturnsOnCall map[int]struct {
+		result1 []arcade.Provider
+		result2 error
+	}
+	ProvidersContextStub        func(context.Context) ([]arcade.Provider, error)
+	providersContextMutex       sync.RWMutex
+	providersContextArgsForCall []struct {
+		arg1 context.Context
+	}
+	providersContextReturns struct {
+		result1 []arcade.Provider
+		result2 error
+	}
+	providersContextReturnsOnCall map[int]struct {
+		result1 []arcade.Provider
+		result2 error
+	}
+	RefreshTokenStub        func(string) error
+	refreshTokenMutex       sync.RWMutex
+	refreshTokenArgsForCall []struct {
+		arg1 string
+	}
+	refreshTokenReturns struct {
+		result1 error
+	}
+	refreshTokenReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RefreshTokenContextStub        func(context.Context, string) error
+	refreshTokenContextMutex       sync.RWMutex
+	refreshTokenContextArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	refreshTokenContextReturns struct {
+		result1 error
+	}
+	refreshTokenContextReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ReportInvalidTokenStub        func(string, string) error
+	reportInvalidTokenMutex       sync.RWMutex
+	reportInvalidTokenArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	reportInvalidTokenReturns struct {
+		result1 error
+	}
+	reportInvalidTokenReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ReportInvalidTokenContextStub        func(context.Context, string, string) error
+	reportInvalidTokenContextMutex       sync.RWMutex
+	reportInvalidTokenContextArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}
+	reportInvalidTokenContextReturns struct {
+		result1 error
+	}
+	reportInvalidTokenContextReturnsOnCall map[int]struct {
+		result1 error
+	}
 	TokenStub        func(string) (string, error)
 	tokenMutex       sync.RWMutex
 	tokenArgsForCall []struct {
@@ -21,10 +119,598 @@ type FakeClient struct {
 		result1 string
 		result2 error
 	}
+	TokenContextStub        func(context.Context, string) (string, error)
+	tokenContextMutex       sync.RWMutex
+	tokenContextArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	tokenContextReturns struct {
+		result1 string
+		result2 error
+	}
+	tokenContextReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	TokenNoCacheStub        func(string) (string, error)
+	tokenNoCacheMutex       sync.RWMutex
+	tokenNoCacheArgsForCall []struct {
+		arg1 string
+	}
+	tokenNoCacheReturns struct {
+		result1 string
+		result2 error
+	}
+	tokenNoCacheReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	TokenNoCacheContextStub        func(context.Context, string) (string, error)
+	tokenNoCacheContextMutex       sync.RWMutex
+	tokenNoCacheContextArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	tokenNoCacheContextReturns struct {
+		result1 string
+		result2 error
+	}
+	tokenNoCacheContextReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	TokenWithExpiryStub        func(string) (string, time.Time, error)
+	tokenWithExpiryMutex       sync.RWMutex
+	tokenWithExpiryArgsForCall []struct {
+		arg1 string
+	}
+	tokenWithExpiryReturns struct {
+		result1 string
+		result2 time.Time
+		result3 error
+	}
+	tokenWithExpiryReturnsOnCall map[int]struct {
+		result1 string
+		result2 time.Time
+		result3 error
+	}
+	TokenWithExpiryContextStub        func(context.Context, string) (string, time.Time, error)
+	tokenWithExpiryContextMutex       sync.RWMutex
+	tokenWithExpiryContextArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	tokenWithExpiryContextReturns struct {
+		result1 string
+		result2 time.Time
+		result3 error
+	}
+	tokenWithExpiryContextReturnsOnCall map[int]struct {
+		result1 string
+		result2 time.Time
+		result3 error
+	}
+	WatchTokenStub        func(string, func(string, time.Time) error) error
+	watchTokenMutex       sync.RWMutex
+	watchTokenArgsForCall []struct {
+		arg1 string
+		arg2 func(string, time.Time) error
+	}
+	watchTokenReturns struct {
+		result1 error
+	}
+	watchTokenReturnsOnCall map[int]struct {
+		result1 error
+	}
+	WatchTokenContextStub        func(context.Context, string, func(string, time.Time) error) error
+	watchTokenContextMutex       sync.RWMutex
+	watchTokenContextArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 func(string, time.Time) error
+	}
+	watchTokenContextReturns struct {
+		result1 error
+	}
+	watchTokenContextReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeClient) InvalidateToken(arg1 string) error {
+	fake.invalidateTokenMutex.Lock()
+	ret, specificReturn := fake.invalidateTokenReturnsOnCall[len(fake.invalidateTokenArgsForCall)]
+	fake.invalidateTokenArgsForCall = append(fake.invalidateTokenArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.InvalidateTokenStub
+	fakeReturns := fake.invalidateTokenReturns
+	fake.recordInvocation("InvalidateToken", []interface{}{arg1})
+	fake.invalidateTokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClient) InvalidateTokenCallCount() int {
+	fake.invalidateTokenMutex.RLock()
+	defer fake.invalidateTokenMutex.RUnlock()
+	return len(fake.invalidateTokenArgsForCall)
+}
+
+func (fake *FakeClient) InvalidateTokenCalls(stub func(string) error) {
+	fake.invalidateTokenMutex.Lock()
+	defer fake.invalidateTokenMutex.Unlock()
+	fake.InvalidateTokenStub = stub
+}
+
+func (fake *FakeClient) InvalidateTokenArgsForCall(i int) string {
+	fake.invalidateTokenMutex.RLock()
+	defer fake.invalidateTokenMutex.RUnlock()
+	argsForCall := fake.invalidateTokenArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeClient) InvalidateTokenReturns(result1 error) {
+	fake.invalidateTokenMutex.Lock()
+	defer fake.invalidateTokenMutex.Unlock()
+	fake.InvalidateTokenStub = nil
+	fake.invalidateTokenReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) InvalidateTokenReturnsOnCall(i int, result1 error) {
+	fake.invalidateTokenMutex.Lock()
+	defer fake.invalidateTokenMutex.Unlock()
+	fake.InvalidateTokenStub = nil
+	if fake.invalidateTokenReturnsOnCall == nil {
+		fake.invalidateTokenReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.invalidateTokenReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) InvalidateTokenContext(arg1 context.Context, arg2 string) error {
+	fake.invalidateTokenContextMutex.Lock()
+	ret, specificReturn := fake.invalidateTokenContextReturnsOnCall[len(fake.invalidateTokenContextArgsForCall)]
+	fake.invalidateTokenContextArgsForCall = append(fake.invalidateTokenContextArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.InvalidateTokenContextStub
+	fakeReturns := fake.invalidateTokenContextReturns
+	fake.recordInvocation("InvalidateTokenContext", []interface{}{arg1, arg2})
+	fake.invalidateTokenContextMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClient) InvalidateTokenContextCallCount() int {
+	fake.invalidateTokenContextMutex.RLock()
+	defer fake.invalidateTokenContextMutex.RUnlock()
+	return len(fake.invalidateTokenContextArgsForCall)
+}
+
+func (fake *FakeClient) InvalidateTokenContextCalls(stub func(context.Context, string) error) {
+	fake.invalidateTokenContextMutex.Lock()
+	defer fake.invalidateTokenContextMutex.Unlock()
+	fake.InvalidateTokenContextStub = stub
+}
+
+func (fake *FakeClient) InvalidateTokenContextArgsForCall(i int) (context.Context, string) {
+	fake.invalidateTokenContextMutex.RLock()
+	defer fake.invalidateTokenContextMutex.RUnlock()
+	argsForCall := fake.invalidateTokenContextArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeClient) InvalidateTokenContextReturns(result1 error) {
+	fake.invalidateTokenContextMutex.Lock()
+	defer fake.invalidateTokenContextMutex.Unlock()
+	fake.InvalidateTokenContextStub = nil
+	fake.invalidateTokenContextReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) InvalidateTokenContextReturnsOnCall(i int, result1 error) {
+	fake.invalidateTokenContextMutex.Lock()
+	defer fake.invalidateTokenContextMutex.Unlock()
+	fake.InvalidateTokenContextStub = nil
+	if fake.invalidateTokenContextReturnsOnCall == nil {
+		fake.invalidateTokenContextReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.invalidateTokenContextReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) Providers() ([]arcade.Provider, error) {
+	fake.providersMutex.Lock()
+	ret, specificReturn := fake.providersReturnsOnCall[len(fake.providersArgsForCall)]
+	fake.providersArgsForCall = append(fake.providersArgsForCall, struct {
+	}{})
+	stub := fake.ProvidersStub
+	fakeReturns := fake.providersReturns
+	fake.recordInvocation("Providers", []interface{}{})
+	fake.providersMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) ProvidersCallCount() int {
+	fake.providersMutex.RLock()
+	defer fake.providersMutex.RUnlock()
+	return len(fake.providersArgsForCall)
+}
+
+func (fake *FakeClient) ProvidersCalls(stub func() ([]arcade.Provider, error)) {
+	fake.providersMutex.Lock()
+	defer fake.providersMutex.Unlock()
+	fake.ProvidersStub = stub
+}
+
+func (fake *FakeClient) ProvidersReturns(result1 []arcade.Provider, result2 error) {
+	fake.providersMutex.Lock()
+	defer fake.providersMutex.Unlock()
+	fake.ProvidersStub = nil
+	fake.providersReturns = struct {
+		result1 []arcade.Provider
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) ProvidersReturnsOnCall(i int, result1 []arcade.Provider, result2 error) {
+	fake.providersMutex.Lock()
+	defer fake.providersMutex.Unlock()
+	fake.ProvidersStub = nil
+	if fake.providersReturnsOnCall == nil {
+		fake.providersReturnsOnCall = make(map[int]struct {
+			result1 []arcade.Provider
+			result2 error
+		})
+	}
+	fake.providersReturnsOnCall[i] = struct {
+		result1 []arcade.Provider
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) ProvidersContext(arg1 context.Context) ([]arcade.Provider, error) {
+	fake.providersContextMutex.Lock()
+	ret, specificReturn := fake.providersContextReturnsOnCall[len(fake.providersContextArgsForCall)]
+	fake.providersContextArgsForCall = append(fake.providersContextArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.ProvidersContextStub
+	fakeReturns := fake.providersContextReturns
+	fake.recordInvocation("ProvidersContext", []interface{}{arg1})
+	fake.providersContextMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) ProvidersContextCallCount() int {
+	fake.providersContextMutex.RLock()
+	defer fake.providersContextMutex.RUnlock()
+	return len(fake.providersContextArgsForCall)
+}
+
+func (fake *FakeClient) ProvidersContextCalls(stub func(context.Context) ([]arcade.Provider, error)) {
+	fake.providersContextMutex.Lock()
+	defer fake.providersContextMutex.Unlock()
+	fake.ProvidersContextStub = stub
+}
+
+func (fake *FakeClient) ProvidersContextArgsForCall(i int) context.Context {
+	fake.providersContextMutex.RLock()
+	defer fake.providersContextMutex.RUnlock()
+	argsForCall := fake.providersContextArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeClient) ProvidersContextReturns(result1 []arcade.Provider, result2 error) {
+	fake.providersContextMutex.Lock()
+	defer fake.providersContextMutex.Unlock()
+	fake.ProvidersContextStub = nil
+	fake.providersContextReturns = struct {
+		result1 []arcade.Provider
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) ProvidersContextReturnsOnCall(i int, result1 []arcade.Provider, result2 error) {
+	fake.providersContextMutex.Lock()
+	defer fake.providersContextMutex.Unlock()
+	fake.ProvidersContextStub = nil
+	if fake.providersContextReturnsOnCall == nil {
+		fake.providersContextReturnsOnCall = make(map[int]struct {
+			result1 []arcade.Provider
+			result2 error
+		})
+	}
+	fake.providersContextReturnsOnCall[i] = struct {
+		result1 []arcade.Provider
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) RefreshToken(arg1 string) error {
+	fake.refreshTokenMutex.Lock()
+	ret, specificReturn := fake.refreshTokenReturnsOnCall[len(fake.refreshTokenArgsForCall)]
+	fake.refreshTokenArgsForCall = append(fake.refreshTokenArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.RefreshTokenStub
+	fakeReturns := fake.refreshTokenReturns
+	fake.recordInvocation("RefreshToken", []interface{}{arg1})
+	fake.refreshTokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClient) RefreshTokenCallCount() int {
+	fake.refreshTokenMutex.RLock()
+	defer fake.refreshTokenMutex.RUnlock()
+	return len(fake.refreshTokenArgsForCall)
+}
+
+func (fake *FakeClient) RefreshTokenCalls(stub func(string) error) {
+	fake.refreshTokenMutex.Lock()
+	defer fake.refreshTokenMutex.Unlock()
+	fake.RefreshTokenStub = stub
+}
+
+func (fake *FakeClient) RefreshTokenArgsForCall(i int) string {
+	fake.refreshTokenMutex.RLock()
+	defer fake.refreshTokenMutex.RUnlock()
+	argsForCall := fake.refreshTokenArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeClient) RefreshTokenReturns(result1 error) {
+	fake.refreshTokenMutex.Lock()
+	defer fake.refreshTokenMutex.Unlock()
+	fake.RefreshTokenStub = nil
+	fake.refreshTokenReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) RefreshTokenReturnsOnCall(i int, result1 error) {
+	fake.refreshTokenMutex.Lock()
+	defer fake.refreshTokenMutex.Unlock()
+	fake.RefreshTokenStub = nil
+	if fake.refreshTokenReturnsOnCall == nil {
+		fake.refreshTokenReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.refreshTokenReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) RefreshTokenContext(arg1 context.Context, arg2 string) error {
+	fake.refreshTokenContextMutex.Lock()
+	ret, specificReturn := fake.refreshTokenContextReturnsOnCall[len(fake.refreshTokenContextArgsForCall)]
+	fake.refreshTokenContextArgsForCall = append(fake.refreshTokenContextArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.RefreshTokenContextStub
+	fakeReturns := fake.refreshTokenContextReturns
+	fake.recordInvocation("RefreshTokenContext", []interface{}{arg1, arg2})
+	fake.refreshTokenContextMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClient) RefreshTokenContextCallCount() int {
+	fake.refreshTokenContextMutex.RLock()
+	defer fake.refreshTokenContextMutex.RUnlock()
+	return len(fake.refreshTokenContextArgsForCall)
+}
+
+func (fake *FakeClient) RefreshTokenContextCalls(stub func(context.Context, string) error) {
+	fake.refreshTokenContextMutex.Lock()
+	defer fake.refreshTokenContextMutex.Unlock()
+	fake.RefreshTokenContextStub = stub
+}
+
+func (fake *FakeClient) RefreshTokenContextArgsForCall(i int) (context.Context, string) {
+	fake.refreshTokenContextMutex.RLock()
+	defer fake.refreshTokenContextMutex.RUnlock()
+	argsForCall := fake.refreshTokenContextArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeClient) RefreshTokenContextReturns(result1 error) {
+	fake.refreshTokenContextMutex.Lock()
+	defer fake.refreshTokenContextMutex.Unlock()
+	fake.RefreshTokenContextStub = nil
+	fake.refreshTokenContextReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) RefreshTokenContextReturnsOnCall(i int, result1 error) {
+	fake.refreshTokenContextMutex.Lock()
+	defer fake.refreshTokenContextMutex.Unlock()
+	fake.RefreshTokenContextStub = nil
+	if fake.refreshTokenContextReturnsOnCall == nil {
+		fake.refreshTokenContextReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.refreshTokenContextReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) ReportInvalidToken(arg1 string, arg2 string) error {
+	fake.reportInvalidTokenMutex.Lock()
+	ret, specificReturn := fake.reportInvalidTokenReturnsOnCall[len(fake.reportInvalidTokenArgsForCall)]
+	fake.reportInvalidTokenArgsForCall = append(fake.reportInvalidTokenArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ReportInvalidTokenStub
+	fakeReturns := fake.reportInvalidTokenReturns
+	fake.recordInvocation("ReportInvalidToken", []interface{}{arg1, arg2})
+	fake.reportInvalidTokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClient) ReportInvalidTokenCallCount() int {
+	fake.reportInvalidTokenMutex.RLock()
+	defer fake.reportInvalidTokenMutex.RUnlock()
+	return len(fake.reportInvalidTokenArgsForCall)
+}
+
+func (fake *FakeClient) ReportInvalidTokenCalls(stub func(string, string) error) {
+	fake.reportInvalidTokenMutex.Lock()
+	defer fake.reportInvalidTokenMutex.Unlock()
+	fake.ReportInvalidTokenStub = stub
+}
+
+func (fake *FakeClient) ReportInvalidTokenArgsForCall(i int) (string, string) {
+	fake.reportInvalidTokenMutex.RLock()
+	defer fake.reportInvalidTokenMutex.RUnlock()
+	argsForCall := fake.reportInvalidTokenArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeClient) ReportInvalidTokenReturns(result1 error) {
+	fake.reportInvalidTokenMutex.Lock()
+	defer fake.reportInvalidTokenMutex.Unlock()
+	fake.ReportInvalidTokenStub = nil
+	fake.reportInvalidTokenReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) ReportInvalidTokenReturnsOnCall(i int, result1 error) {
+	fake.reportInvalidTokenMutex.Lock()
+	defer fake.reportInvalidTokenMutex.Unlock()
+	fake.ReportInvalidTokenStub = nil
+	if fake.reportInvalidTokenReturnsOnCall == nil {
+		fake.reportInvalidTokenReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.reportInvalidTokenReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) ReportInvalidTokenContext(arg1 context.Context, arg2 string, arg3 string) error {
+	fake.reportInvalidTokenContextMutex.Lock()
+	ret, specificReturn := fake.reportInvalidTokenContextReturnsOnCall[len(fake.reportInvalidTokenContextArgsForCall)]
+	fake.reportInvalidTokenContextArgsForCall = append(fake.reportInvalidTokenContextArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.ReportInvalidTokenContextStub
+	fakeReturns := fake.reportInvalidTokenContextReturns
+	fake.recordInvocation("ReportInvalidTokenContext", []interface{}{arg1, arg2, arg3})
+	fake.reportInvalidTokenContextMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClient) ReportInvalidTokenContextCallCount() int {
+	fake.reportInvalidTokenContextMutex.RLock()
+	defer fake.reportInvalidTokenContextMutex.RUnlock()
+	return len(fake.reportInvalidTokenContextArgsForCall)
+}
+
+func (fake *FakeClient) ReportInvalidTokenContextCalls(stub func(context.Context, string, string) error) {
+	fake.reportInvalidTokenContextMutex.Lock()
+	defer fake.reportInvalidTokenContextMutex.Unlock()
+	fake.ReportInvalidTokenContextStub = stub
+}
+
+func (fake *FakeClient) ReportInvalidTokenContextArgsForCall(i int) (context.Context, string, string) {
+	fake.reportInvalidTokenContextMutex.RLock()
+	defer fake.reportInvalidTokenContextMutex.RUnlock()
+	argsForCall := fake.reportInvalidTokenContextArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeClient) ReportInvalidTokenContextReturns(result1 error) {
+	fake.reportInvalidTokenContextMutex.Lock()
+	defer fake.reportInvalidTokenContextMutex.Unlock()
+	fake.ReportInvalidTokenContextStub = nil
+	fake.reportInvalidTokenContextReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) ReportInvalidTokenContextReturnsOnCall(i int, result1 error) {
+	fake.reportInvalidTokenContextMutex.Lock()
+	defer fake.reportInvalidTokenContextMutex.Unlock()
+	fake.ReportInvalidTokenContextStub = nil
+	if fake.reportInvalidTokenContextReturnsOnCall == nil {
+		fake.reportInvalidTokenContextReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.reportInvalidTokenContextReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeClient) Token(arg1 string) (string, error) {
 	fake.tokenMutex.Lock()
 	ret, specificReturn := fake.tokenReturnsOnCall[len(fake.tokenArgsForCall)]
@@ -89,11 +775,495 @@ func (fake *FakeClient) TokenReturnsOnCall(i int, result1 string, result2 error)
 	}{result1, result2}
 }
 
+func (fake *FakeClient) TokenContext(arg1 context.Context, arg2 string) (string, error) {
+	fake.tokenContextMutex.Lock()
+	ret, specificReturn := fake.tokenContextReturnsOnCall[len(fake.tokenContextArgsForCall)]
+	fake.tokenContextArgsForCall = append(fake.tokenContextArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.TokenContextStub
+	fakeReturns := fake.tokenContextReturns
+	fake.recordInvocation("TokenContext", []interface{}{arg1, arg2})
+	fake.tokenContextMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) TokenContextCallCount() int {
+	fake.tokenContextMutex.RLock()
+	defer fake.tokenContextMutex.RUnlock()
+	return len(fake.tokenContextArgsForCall)
+}
+
+func (fake *FakeClient) TokenContextCalls(stub func(context.Context, string) (string, error)) {
+	fake.tokenContextMutex.Lock()
+	defer fake.tokenContextMutex.Unlock()
+	fake.TokenContextStub = stub
+}
+
+func (fake *FakeClient) TokenContextArgsForCall(i int) (context.Context, string) {
+	fake.tokenContextMutex.RLock()
+	defer fake.tokenContextMutex.RUnlock()
+	argsForCall := fake.tokenContextArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeClient) TokenContextReturns(result1 string, result2 error) {
+	fake.tokenContextMutex.Lock()
+	defer fake.tokenContextMutex.Unlock()
+	fake.TokenContextStub = nil
+	fake.tokenContextReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) TokenContextReturnsOnCall(i int, result1 string, result2 error) {
+	fake.tokenContextMutex.Lock()
+	defer fake.tokenContextMutex.Unlock()
+	fake.TokenContextStub = nil
+	if fake.tokenContextReturnsOnCall == nil {
+		fake.tokenContextReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.tokenContextReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) TokenNoCache(arg1 string) (string, error) {
+	fake.tokenNoCacheMutex.Lock()
+	ret, specificReturn := fake.tokenNoCacheReturnsOnCall[len(fake.tokenNoCacheArgsForCall)]
+	fake.tokenNoCacheArgsForCall = append(fake.tokenNoCacheArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.TokenNoCacheStub
+	fakeReturns := fake.tokenNoCacheReturns
+	fake.recordInvocation("TokenNoCache", []interface{}{arg1})
+	fake.tokenNoCacheMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) TokenNoCacheCallCount() int {
+	fake.tokenNoCacheMutex.RLock()
+	defer fake.tokenNoCacheMutex.RUnlock()
+	return len(fake.tokenNoCacheArgsForCall)
+}
+
+func (fake *FakeClient) TokenNoCacheCalls(stub func(string) (string, error)) {
+	fake.tokenNoCacheMutex.Lock()
+	defer fake.tokenNoCacheMutex.Unlock()
+	fake.TokenNoCacheStub = stub
+}
+
+func (fake *FakeClient) TokenNoCacheArgsForCall(i int) string {
+	fake.tokenNoCacheMutex.RLock()
+	defer fake.tokenNoCacheMutex.RUnlock()
+	argsForCall := fake.tokenNoCacheArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeClient) TokenNoCacheReturns(result1 string, result2 error) {
+	fake.tokenNoCacheMutex.Lock()
+	defer fake.tokenNoCacheMutex.Unlock()
+	fake.TokenNoCacheStub = nil
+	fake.tokenNoCacheReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) TokenNoCacheReturnsOnCall(i int, result1 string, result2 error) {
+	fake.tokenNoCacheMutex.Lock()
+	defer fake.tokenNoCacheMutex.Unlock()
+	fake.TokenNoCacheStub = nil
+	if fake.tokenNoCacheReturnsOnCall == nil {
+		fake.tokenNoCacheReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.tokenNoCacheReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) TokenNoCacheContext(arg1 context.Context, arg2 string) (string, error) {
+	fake.tokenNoCacheContextMutex.Lock()
+	ret, specificReturn := fake.tokenNoCacheContextReturnsOnCall[len(fake.tokenNoCacheContextArgsForCall)]
+	fake.tokenNoCacheContextArgsForCall = append(fake.tokenNoCacheContextArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.TokenNoCacheContextStub
+	fakeReturns := fake.tokenNoCacheContextReturns
+	fake.recordInvocation("TokenNoCacheContext", []interface{}{arg1, arg2})
+	fake.tokenNoCacheContextMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) TokenNoCacheContextCallCount() int {
+	fake.tokenNoCacheContextMutex.RLock()
+	defer fake.tokenNoCacheContextMutex.RUnlock()
+	return len(fake.tokenNoCacheContextArgsForCall)
+}
+
+func (fake *FakeClient) TokenNoCacheContextCalls(stub func(context.Context, string) (string, error)) {
+	fake.tokenNoCacheContextMutex.Lock()
+	defer fake.tokenNoCacheContextMutex.Unlock()
+	fake.TokenNoCacheContextStub = stub
+}
+
+func (fake *FakeClient) TokenNoCacheContextArgsForCall(i int) (context.Context, string) {
+	fake.tokenNoCacheContextMutex.RLock()
+	defer fake.tokenNoCacheContextMutex.RUnlock()
+	argsForCall := fake.tokenNoCacheContextArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeClient) TokenNoCacheContextReturns(result1 string, result2 error) {
+	fake.tokenNoCacheContextMutex.Lock()
+	defer fake.tokenNoCacheContextMutex.Unlock()
+	fake.TokenNoCacheContextStub = nil
+	fake.tokenNoCacheContextReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) TokenNoCacheContextReturnsOnCall(i int, result1 string, result2 error) {
+	fake.tokenNoCacheContextMutex.Lock()
+	defer fake.tokenNoCacheContextMutex.Unlock()
+	fake.TokenNoCacheContextStub = nil
+	if fake.tokenNoCacheContextReturnsOnCall == nil {
+		fake.tokenNoCacheContextReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.tokenNoCacheContextReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) TokenWithExpiry(arg1 string) (string, time.Time, error) {
+	fake.tokenWithExpiryMutex.Lock()
+	ret, specificReturn := fake.tokenWithExpiryReturnsOnCall[len(fake.tokenWithExpiryArgsForCall)]
+	fake.tokenWithExpiryArgsForCall = append(fake.tokenWithExpiryArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.TokenWithExpiryStub
+	fakeReturns := fake.tokenWithExpiryReturns
+	fake.recordInvocation("TokenWithExpiry", []interface{}{arg1})
+	fake.tokenWithExpiryMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeClient) TokenWithExpiryCallCount() int {
+	fake.tokenWithExpiryMutex.RLock()
+	defer fake.tokenWithExpiryMutex.RUnlock()
+	return len(fake.tokenWithExpiryArgsForCall)
+}
+
+func (fake *FakeClient) TokenWithExpiryCalls(stub func(string) (string, time.Time, error)) {
+	fake.tokenWithExpiryMutex.Lock()
+	defer fake.tokenWithExpiryMutex.Unlock()
+	fake.TokenWithExpiryStub = stub
+}
+
+func (fake *FakeClient) TokenWithExpiryArgsForCall(i int) string {
+	fake.tokenWithExpiryMutex.RLock()
+	defer fake.tokenWithExpiryMutex.RUnlock()
+	argsForCall := fake.tokenWithExpiryArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeClient) TokenWithExpiryReturns(result1 string, result2 time.Time, result3 error) {
+	fake.tokenWithExpiryMutex.Lock()
+	defer fake.tokenWithExpiryMutex.Unlock()
+	fake.TokenWithExpiryStub = nil
+	fake.tokenWithExpiryReturns = struct {
+		result1 string
+		result2 time.Time
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeClient) TokenWithExpiryReturnsOnCall(i int, result1 string, result2 time.Time, result3 error) {
+	fake.tokenWithExpiryMutex.Lock()
+	defer fake.tokenWithExpiryMutex.Unlock()
+	fake.TokenWithExpiryStub = nil
+	if fake.tokenWithExpiryReturnsOnCall == nil {
+		fake.tokenWithExpiryReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 time.Time
+			result3 error
+		})
+	}
+	fake.tokenWithExpiryReturnsOnCall[i] = struct {
+		result1 string
+		result2 time.Time
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeClient) TokenWithExpiryContext(arg1 context.Context, arg2 string) (string, time.Time, error) {
+	fake.tokenWithExpiryContextMutex.Lock()
+	ret, specificReturn := fake.tokenWithExpiryContextReturnsOnCall[len(fake.tokenWithExpiryContextArgsForCall)]
+	fake.tokenWithExpiryContextArgsForCall = append(fake.tokenWithExpiryContextArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.TokenWithExpiryContextStub
+	fakeReturns := fake.tokenWithExpiryContextReturns
+	fake.recordInvocation("TokenWithExpiryContext", []interface{}{arg1, arg2})
+	fake.tokenWithExpiryContextMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeClient) TokenWithExpiryContextCallCount() int {
+	fake.tokenWithExpiryContextMutex.RLock()
+	defer fake.tokenWithExpiryContextMutex.RUnlock()
+	return len(fake.tokenWithExpiryContextArgsForCall)
+}
+
+func (fake *FakeClient) TokenWithExpiryContextCalls(stub func(context.Context, string) (string, time.Time, error)) {
+	fake.tokenWithExpiryContextMutex.Lock()
+	defer fake.tokenWithExpiryContextMutex.Unlock()
+	fake.TokenWithExpiryContextStub = stub
+}
+
+func (fake *FakeClient) TokenWithExpiryContextArgsForCall(i int) (context.Context, string) {
+	fake.tokenWithExpiryContextMutex.RLock()
+	defer fake.tokenWithExpiryContextMutex.RUnlock()
+	argsForCall := fake.tokenWithExpiryContextArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeClient) TokenWithExpiryContextReturns(result1 string, result2 time.Time, result3 error) {
+	fake.tokenWithExpiryContextMutex.Lock()
+	defer fake.tokenWithExpiryContextMutex.Unlock()
+	fake.TokenWithExpiryContextStub = nil
+	fake.tokenWithExpiryContextReturns = struct {
+		result1 string
+		result2 time.Time
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeClient) TokenWithExpiryContextReturnsOnCall(i int, result1 string, result2 time.Time, result3 error) {
+	fake.tokenWithExpiryContextMutex.Lock()
+	defer fake.tokenWithExpiryContextMutex.Unlock()
+	fake.TokenWithExpiryContextStub = nil
+	if fake.tokenWithExpiryContextReturnsOnCall == nil {
+		fake.tokenWithExpiryContextReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 time.Time
+			result3 error
+		})
+	}
+	fake.tokenWithExpiryContextReturnsOnCall[i] = struct {
+		result1 string
+		result2 time.Time
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeClient) WatchToken(arg1 string, arg2 func(string, time.Time) error) error {
+	fake.watchTokenMutex.Lock()
+	ret, specificReturn := fake.watchTokenReturnsOnCall[len(fake.watchTokenArgsForCall)]
+	fake.watchTokenArgsForCall = append(fake.watchTokenArgsForCall, struct {
+		arg1 string
+		arg2 func(string, time.Time) error
+	}{arg1, arg2})
+	stub := fake.WatchTokenStub
+	fakeReturns := fake.watchTokenReturns
+	fake.recordInvocation("WatchToken", []interface{}{arg1, arg2})
+	fake.watchTokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClient) WatchTokenCallCount() int {
+	fake.watchTokenMutex.RLock()
+	defer fake.watchTokenMutex.RUnlock()
+	return len(fake.watchTokenArgsForCall)
+}
+
+func (fake *FakeClient) WatchTokenCalls(stub func(string, func(string, time.Time) error) error) {
+	fake.watchTokenMutex.Lock()
+	defer fake.watchTokenMutex.Unlock()
+	fake.WatchTokenStub = stub
+}
+
+func (fake *FakeClient) WatchTokenArgsForCall(i int) (string, func(string, time.Time) error) {
+	fake.watchTokenMutex.RLock()
+	defer fake.watchTokenMutex.RUnlock()
+	argsForCall := fake.watchTokenArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeClient) WatchTokenReturns(result1 error) {
+	fake.watchTokenMutex.Lock()
+	defer fake.watchTokenMutex.Unlock()
+	fake.WatchTokenStub = nil
+	fake.watchTokenReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) WatchTokenReturnsOnCall(i int, result1 error) {
+	fake.watchTokenMutex.Lock()
+	defer fake.watchTokenMutex.Unlock()
+	fake.WatchTokenStub = nil
+	if fake.watchTokenReturnsOnCall == nil {
+		fake.watchTokenReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.watchTokenReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) WatchTokenContext(arg1 context.Context, arg2 string, arg3 func(string, time.Time) error) error {
+	fake.watchTokenContextMutex.Lock()
+	ret, specificReturn := fake.watchTokenContextReturnsOnCall[len(fake.watchTokenContextArgsForCall)]
+	fake.watchTokenContextArgsForCall = append(fake.watchTokenContextArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 func(string, time.Time) error
+	}{arg1, arg2, arg3})
+	stub := fake.WatchTokenContextStub
+	fakeReturns := fake.watchTokenContextReturns
+	fake.recordInvocation("WatchTokenContext", []interface{}{arg1, arg2, arg3})
+	fake.watchTokenContextMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClient) WatchTokenContextCallCount() int {
+	fake.watchTokenContextMutex.RLock()
+	defer fake.watchTokenContextMutex.RUnlock()
+	return len(fake.watchTokenContextArgsForCall)
+}
+
+func (fake *FakeClient) WatchTokenContextCalls(stub func(context.Context, string, func(string, time.Time) error) error) {
+	fake.watchTokenContextMutex.Lock()
+	defer fake.watchTokenContextMutex.Unlock()
+	fake.WatchTokenContextStub = stub
+}
+
+func (fake *FakeClient) WatchTokenContextArgsForCall(i int) (context.Context, string, func(string, time.Time) error) {
+	fake.watchTokenContextMutex.RLock()
+	defer fake.watchTokenContextMutex.RUnlock()
+	argsForCall := fake.watchTokenContextArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeClient) WatchTokenContextReturns(result1 error) {
+	fake.watchTokenContextMutex.Lock()
+	defer fake.watchTokenContextMutex.Unlock()
+	fake.WatchTokenContextStub = nil
+	fake.watchTokenContextReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) WatchTokenContextReturnsOnCall(i int, result1 error) {
+	fake.watchTokenContextMutex.Lock()
+	defer fake.watchTokenContextMutex.Unlock()
+	fake.WatchTokenContextStub = nil
+	if fake.watchTokenContextReturnsOnCall == nil {
+		fake.watchTokenContextReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.watchTokenContextReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.invalidateTokenMutex.RLock()
+	defer fake.invalidateTokenMutex.RUnlock()
+	fake.invalidateTokenContextMutex.RLock()
+	defer fake.invalidateTokenContextMutex.RUnlock()
+	fake.providersMutex.RLock()
+	defer fake.providersMutex.RUnlock()
+	fake.providersContextMutex.RLock()
+	defer fake.providersContextMutex.RUnlock()
+	fake.refreshTokenMutex.RLock()
+	defer fake.refreshTokenMutex.RUnlock()
+	fake.refreshTokenContextMutex.RLock()
+	defer fake.refreshTokenContextMutex.RUnlock()
+	fake.reportInvalidTokenMutex.RLock()
+	defer fake.reportInvalidTokenMutex.RUnlock()
+	fake.reportInvalidTokenContextMutex.RLock()
+	defer fake.reportInvalidTokenContextMutex.RUnlock()
 	fake.tokenMutex.RLock()
 	defer fake.tokenMutex.RUnlock()
+	fake.tokenContextMutex.RLock()
+	defer fake.tokenContextMutex.RUnlock()
+	fake.tokenNoCacheMutex.RLock()
+	defer fake.tokenNoCacheMutex.RUnlock()
+	fake.tokenNoCacheContextMutex.RLock()
+	defer fake.tokenNoCacheContextMutex.RUnlock()
+	fake.tokenWithExpiryMutex.RLock()
+	defer fake.tokenWithExpiryMutex.RUnlock()
+	fake.tokenWithExpiryContextMutex.RLock()
+	defer fake.tokenWithExpiryContextMutex.RUnlock()
+	fake.watchTokenMutex.RLock()
+	defer fake.watchTokenMutex.RUnlock()
+	fake.watchTokenContextMutex.RLock()
+	defer fake.watchTokenContextMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -0,0 +1,27 @@
+package arcadepb
+
+import (
+	"context"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// MetadataAPIKey is the metadata key calls are authenticated with.
+const MetadataAPIKey = "api-key"
+
+// APIKey returns credentials authenticating every call with apiKey. They
+// may be sent without transport security, as arcade usually runs as a
+// sidecar of its callers.
+func APIKey(apiKey string) credentials.PerRPCCredentials {
+	return apiKeyCredentials(apiKey)
+}
+
+type apiKeyCredentials string
+
+func (k apiKeyCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{MetadataAPIKey: string(k)}, nil
+}
+
+func (k apiKeyCredentials) RequireTransportSecurity() bool {
+	return false
+}
@@ -0,0 +1,215 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: arcade.proto
+
+package arcadepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Arcade_GetToken_FullMethodName      = "/arcade.v1.Arcade/GetToken"
+	Arcade_ListProviders_FullMethodName = "/arcade.v1.Arcade/ListProviders"
+	Arcade_WatchToken_FullMethodName    = "/arcade.v1.Arcade/WatchToken"
+)
+
+// ArcadeClient is the client API for Arcade service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Arcade serves access tokens from the token providers it is configured
+// with. Requests are authenticated with an API key in the api-key metadata.
+type ArcadeClient interface {
+	// GetToken returns a token from a provider.
+	GetToken(ctx context.Context, in *GetTokenRequest, opts ...grpc.CallOption) (*Token, error)
+	// ListProviders lists the providers the caller may request tokens from.
+	ListProviders(ctx context.Context, in *ListProvidersRequest, opts ...grpc.CallOption) (*ListProvidersResponse, error)
+	// WatchToken streams the current token from a provider, and then every
+	// new token once it is refreshed.
+	WatchToken(ctx context.Context, in *WatchTokenRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Token], error)
+}
+
+type arcadeClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewArcadeClient(cc grpc.ClientConnInterface) ArcadeClient {
+	return &arcadeClient{cc}
+}
+
+func (c *arcadeClient) GetToken(ctx context.Context, in *GetTokenRequest, opts ...grpc.CallOption) (*Token, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Token)
+	err := c.cc.Invoke(ctx, Arcade_GetToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *arcadeClient) ListProviders(ctx context.Context, in *ListProvidersRequest, opts ...grpc.CallOption) (*ListProvidersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListProvidersResponse)
+	err := c.cc.Invoke(ctx, Arcade_ListProviders_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *arcadeClient) WatchToken(ctx context.Context, in *WatchTokenRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Token], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Arcade_ServiceDesc.Streams[0], Arcade_WatchToken_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchTokenRequest, Token]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Arcade_WatchTokenClient = grpc.ServerStreamingClient[Token]
+
+// ArcadeServer is the server API for Arcade service.
+// All implementations must embed UnimplementedArcadeServer
+// for forward compatibility.
+//
+// Arcade serves access tokens from the token providers it is configured
+// with. Requests are authenticated with an API key in the api-key metadata.
+type ArcadeServer interface {
+	// GetToken returns a token from a provider.
+	GetToken(context.Context, *GetTokenRequest) (*Token, error)
+	// ListProviders lists the providers the caller may request tokens from.
+	ListProviders(context.Context, *ListProvidersRequest) (*ListProvidersResponse, error)
+	// WatchToken streams the current token from a provider, and then every
+	// new token once it is refreshed.
+	WatchToken(*WatchTokenRequest, grpc.ServerStreamingServer[Token]) error
+	mustEmbedUnimplementedArcadeServer()
+}
+
+// UnimplementedArcadeServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedArcadeServer struct{}
+
+func (UnimplementedArcadeServer) GetToken(context.Context, *GetTokenRequest) (*Token, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetToken not implemented")
+}
+func (UnimplementedArcadeServer) ListProviders(context.Context, *ListProvidersRequest) (*ListProvidersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListProviders not implemented")
+}
+func (UnimplementedArcadeServer) WatchToken(*WatchTokenRequest, grpc.ServerStreamingServer[Token]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchToken not implemented")
+}
+func (UnimplementedArcadeServer) mustEmbedUnimplementedArcadeServer() {}
+func (UnimplementedArcadeServer) testEmbeddedByValue()                {}
+
+// UnsafeArcadeServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ArcadeServer will
+// result in compilation errors.
+type UnsafeArcadeServer interface {
+	mustEmbedUnimplementedArcadeServer()
+}
+
+func RegisterArcadeServer(s grpc.ServiceRegistrar, srv ArcadeServer) {
+	// If the following call pancis, it indicates UnimplementedArcadeServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Arcade_ServiceDesc, srv)
+}
+
+func _Arcade_GetToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArcadeServer).GetToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Arcade_GetToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArcadeServer).GetToken(ctx, req.(*GetTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Arcade_ListProviders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProvidersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArcadeServer).ListProviders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Arcade_ListProviders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArcadeServer).ListProviders(ctx, req.(*ListProvidersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Arcade_WatchToken_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchTokenRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ArcadeServer).WatchToken(m, &grpc.GenericServerStream[WatchTokenRequest, Token]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Arcade_WatchTokenServer = grpc.ServerStreamingServer[Token]
+
+// Arcade_ServiceDesc is the grpc.ServiceDesc for Arcade service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Arcade_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "arcade.v1.Arcade",
+	HandlerType: (*ArcadeServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetToken",
+			Handler:    _Arcade_GetToken_Handler,
+		},
+		{
+			MethodName: "ListProviders",
+			Handler:    _Arcade_ListProviders_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchToken",
+			Handler:       _Arcade_WatchToken_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "arcade.proto",
+}
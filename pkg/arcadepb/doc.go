@@ -0,0 +1,11 @@
+// Package arcadepb is the gRPC client of arcade, generated from
+// arcade.proto. Calls are authenticated with APIKey:
+//
+//	conn, err := grpc.NewClient("arcade:1983",
+//		grpc.WithTransportCredentials(insecure.NewCredentials()),
+//		grpc.WithPerRPCCredentials(arcadepb.APIKey(apiKey)))
+//	client := arcadepb.NewArcadeClient(conn)
+//	token, err := client.GetToken(ctx, &arcadepb.GetTokenRequest{Provider: "google"})
+package arcadepb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative arcade.proto
@@ -1,12 +1,16 @@
 package arcade
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
-	"log"
+	"strconv"
+	"time"
 )
 
 const (
@@ -16,70 +20,345 @@ const (
 //go:generate counterfeiter -o arcadefakes . Client
 type Client interface {
 	Token(string) (string, error)
+	// TokenContext returns a token for a given provider, aborting the
+	// request once ctx is done.
+	TokenContext(context.Context, string) (string, error)
+	// TokenWithExpiry returns a token for a given provider and when it
+	// expires, or the zero time if arcade does not know.
+	TokenWithExpiry(string) (string, time.Time, error)
+	// TokenWithExpiryContext is TokenWithExpiry, aborting the request once
+	// ctx is done.
+	TokenWithExpiryContext(context.Context, string) (string, time.Time, error)
+	// TokenNoCache returns a token for a given provider, requesting that
+	// arcade fetch a new one rather than return a cached token.
+	TokenNoCache(string) (string, error)
+	// TokenNoCacheContext is TokenNoCache, aborting the request once
+	// ctx is done.
+	TokenNoCacheContext(context.Context, string) (string, error)
+	// RefreshToken makes arcade fetch a new token for a given provider,
+	// replacing its cached token. It requires the admin API key.
+	RefreshToken(string) error
+	// RefreshTokenContext is RefreshToken, aborting the request once
+	// ctx is done.
+	RefreshTokenContext(context.Context, string) error
+	// InvalidateToken removes the cached token of a given provider. It
+	// requires the admin API key.
+	InvalidateToken(string) error
+	// InvalidateTokenContext is InvalidateToken, aborting the request once
+	// ctx is done.
+	InvalidateTokenContext(context.Context, string) error
+	// ReportInvalidToken reports that a token of a given provider was
+	// rejected, so that arcade replaces it if it is still cached.
+	ReportInvalidToken(string, string) error
+	// ReportInvalidTokenContext is ReportInvalidToken, aborting the
+	// request once ctx is done.
+	ReportInvalidTokenContext(context.Context, string, string) error
+	// Providers returns the providers arcade serves tokens for to the
+	// caller.
+	Providers() ([]Provider, error)
+	// ProvidersContext is Providers, aborting the request once ctx is
+	// done.
+	ProvidersContext(context.Context) ([]Provider, error)
+	// WatchToken calls a function with the token of a given provider and
+	// its expiry, or the zero time if arcade does not know, and then
+	// again every time arcade refreshes it, until the function returns an
+	// error. It reconnects whenever the connection to arcade is lost.
+	WatchToken(string, func(string, time.Time) error) error
+	// WatchTokenContext is WatchToken, also returning once ctx is done.
+	WatchTokenContext(context.Context, string, func(string, time.Time) error) error
 }
 
 // NewDefaultClient creates a new instance of client with an API Key
 // that calls the default URL endpoint.
-func NewDefaultClient(apiKey string) Client {
-	return NewClient(defaultURL, apiKey)
+func NewDefaultClient(apiKey string, opts ...Option) Client {
+	return NewClient(defaultURL, apiKey, opts...)
 }
 
 // NewClient creates a new instance of client with a defined API Key
 // and URL endpoint.
-func NewClient(url, apiKey string) Client {
-	return &client{
-		apiKey: apiKey,
-		url:    url,
+func NewClient(url, apiKey string, opts ...Option) Client {
+	c := &client{
+		apiKey:  apiKey,
+		url:     url,
+		c:       http.DefaultClient,
+		timeout: DefaultTimeout,
+		backoff: DefaultBackoff,
+	}
+
+	for _, opt := range opts {
+		opt(c)
 	}
+
+	return c
 }
 
 type client struct {
-	apiKey string
-	url    string
+	apiKey  string
+	url     string
+	c       *http.Client
+	timeout time.Duration
+	retries int
+	backoff time.Duration
 }
 
 // Token returns a token for a given provider.
 func (c *client) Token(tokenProvider string) (string, error) {
-	req, err := http.NewRequest(http.MethodGet, c.url+"/tokens", nil)
+	return c.TokenContext(context.Background(), tokenProvider)
+}
+
+// TokenContext returns a token for a given provider.
+func (c *client) TokenContext(ctx context.Context, tokenProvider string) (string, error) {
+	token, _, err := c.token(ctx, tokenProvider, false)
+
+	return token, err
+}
+
+// TokenWithExpiry returns a token for a given provider and its expiry.
+func (c *client) TokenWithExpiry(tokenProvider string) (string, time.Time, error) {
+	return c.TokenWithExpiryContext(context.Background(), tokenProvider)
+}
+
+// TokenWithExpiryContext returns a token for a given provider and its
+// expiry.
+func (c *client) TokenWithExpiryContext(ctx context.Context, tokenProvider string) (string, time.Time, error) {
+	return c.token(ctx, tokenProvider, false)
+}
+
+// TokenNoCache returns a new token for a given provider.
+func (c *client) TokenNoCache(tokenProvider string) (string, error) {
+	return c.TokenNoCacheContext(context.Background(), tokenProvider)
+}
+
+// TokenNoCacheContext returns a new token for a given provider.
+func (c *client) TokenNoCacheContext(ctx context.Context, tokenProvider string) (string, error) {
+	token, _, err := c.token(ctx, tokenProvider, true)
+
+	return token, err
+}
+
+func (c *client) token(ctx context.Context, tokenProvider string, noCache bool) (string, time.Time, error) {
+	header := http.Header{}
+	if noCache {
+		header.Add("Cache-Control", "no-cache")
+	}
+
+	b, err := c.send(ctx, "getting token", http.MethodGet, "/tokens", tokenProvider, header, nil)
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
 	}
 
-	q := url.Values{}
-	q.Add("provider", tokenProvider)
-	req.URL.RawQuery = q.Encode()
+	var response struct {
+		Token  string    `json:"token"`
+		Expiry time.Time `json:"expiry"`
+	}
 
-	req.Header.Add("Api-Key", c.apiKey)
+	err = json.Unmarshal(b, &response)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return response.Token, response.Expiry, nil
+}
+
+// RefreshToken makes arcade fetch a new token for a given provider.
+func (c *client) RefreshToken(tokenProvider string) error {
+	return c.RefreshTokenContext(context.Background(), tokenProvider)
+}
+
+// RefreshTokenContext makes arcade fetch a new token for a given
+// provider.
+func (c *client) RefreshTokenContext(ctx context.Context, tokenProvider string) error {
+	return c.do(ctx, http.MethodPost, "/tokens/refresh", tokenProvider, nil)
+}
+
+// InvalidateToken removes the cached token of a given provider.
+func (c *client) InvalidateToken(tokenProvider string) error {
+	return c.InvalidateTokenContext(context.Background(), tokenProvider)
+}
+
+// InvalidateTokenContext removes the cached token of a given provider.
+func (c *client) InvalidateTokenContext(ctx context.Context, tokenProvider string) error {
+	return c.do(ctx, http.MethodDelete, "/tokens/cache", tokenProvider, nil)
+}
 
-	res, err := http.DefaultClient.Do(req)
+// ReportInvalidToken reports that a token of a given provider was
+// rejected. Only the token's fingerprint is sent to arcade.
+func (c *client) ReportInvalidToken(tokenProvider, token string) error {
+	return c.ReportInvalidTokenContext(context.Background(), tokenProvider, token)
+}
+
+// ReportInvalidTokenContext reports that a token of a given provider
+// was rejected.
+func (c *client) ReportInvalidTokenContext(ctx context.Context, tokenProvider, token string) error {
+	b, err := json.Marshal(struct {
+		Fingerprint string `json:"fingerprint"`
+	}{Fingerprint(token)})
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	defer func(){
-                 err := res.Body.Close()
-                 if err != nil {
-                        log.Printf("arcade: arcade-client: error closing response body: %s\n", err.Error())
-                 }
-        }()
+	return c.do(ctx, http.MethodPost, "/tokens/report-invalid", tokenProvider, b)
+}
+
+// Providers returns the providers arcade serves tokens for, sorted by name.
+func (c *client) Providers() ([]Provider, error) {
+	return c.ProvidersContext(context.Background())
+}
 
-	if res.StatusCode < 200 || res.StatusCode > 399 {
-		return "", fmt.Errorf("error getting token: %s", res.Status)
+// ProvidersContext returns the providers arcade serves tokens for, sorted
+// by name.
+func (c *client) ProvidersContext(ctx context.Context) ([]Provider, error) {
+	b, err := c.send(ctx, "listing providers", http.MethodGet, "/providers", "", nil, nil)
+	if err != nil {
+		return nil, err
 	}
 
 	var response struct {
-		Token string `json:"token"`
+		Providers []Provider `json:"providers"`
 	}
 
-	b, err := io.ReadAll(res.Body)
+	err = json.Unmarshal(b, &response)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	err = json.Unmarshal(b, &response)
+	return response.Providers, nil
+}
+
+// do makes a request that returns no content.
+func (c *client) do(ctx context.Context, method, path, tokenProvider string, body []byte) error {
+	header := http.Header{}
+	if body != nil {
+		header.Add("Content-Type", "application/json")
+	}
+
+	_, err := c.send(ctx, method+" "+path, method, path, tokenProvider, header, body)
+
+	return err
+}
+
+// send makes an authenticated request for the given path and provider, if
+// any, retrying it as configured, and returns the body of the response. A
+// response with a non-2XX status is returned as an *Error describing op.
+func (c *client) send(ctx context.Context, op, method, path, tokenProvider string,
+	header http.Header, body []byte) ([]byte, error) {
+	u, err := url.Parse(c.url + path)
+	if err != nil {
+		return nil, err
+	}
+
+	if tokenProvider != "" {
+		q := url.Values{}
+		q.Add("provider", tokenProvider)
+		u.RawQuery = q.Encode()
+	}
+
+	backoff := c.backoff
+
+	for attempt := 0; ; attempt++ {
+		b, retryAfter, err := c.attempt(ctx, op, method, u.String(), header, body)
+		if err == nil || attempt >= c.retries || !retryable(ctx, err) {
+			return b, err
+		}
+
+		wait := backoff
+		if retryAfter > wait {
+			wait = retryAfter
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(wait):
+		}
+
+		backoff = min(2*backoff, maxBackoff)
+	}
+}
+
+// attempt makes a single request, returning the body of the response and
+// how long the server asked to wait before retrying, if it did.
+func (c *client) attempt(ctx context.Context, op, method, u string,
+	header http.Header, body []byte) ([]byte, time.Duration, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if header != nil {
+		req.Header = header.Clone()
+	}
+
+	req.Header.Add("Api-Key", c.apiKey)
+
+	res, err := c.c.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer func() {
+		err := res.Body.Close()
+		if err != nil {
+			log.Printf("arcade: arcade-client: error closing response body: %s\n", err.Error())
+		}
+	}()
+
+	b, err := io.ReadAll(res.Body)
 	if err != nil {
-		return "", err
+		return nil, 0, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		err, retryAfter := responseError(op, res, b)
+
+		return nil, retryAfter, err
+	}
+
+	return b, 0, nil
+}
+
+// responseError returns the error for a response with a non-2XX status and
+// body b, and how long the server asked to wait before retrying, if it did.
+func responseError(op string, res *http.Response, b []byte) (*Error, time.Duration) {
+	var response struct {
+		Error string `json:"error"`
+		Code  string `json:"code"`
+	}
+
+	_ = json.Unmarshal(b, &response)
+
+	retryAfter, _ := strconv.Atoi(res.Header.Get("Retry-After"))
+
+	return &Error{
+		Op:         op,
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+		Message:    response.Error,
+		Code:       response.Code,
+	}, time.Duration(retryAfter) * time.Second
+}
+
+// retryable reports whether a request failing with err may succeed if
+// retried: if arcade could not be reached or was rate limited or failed,
+// unless the request was canceled by the caller.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
 	}
 
-	return response.Token, nil
+	var e *Error
+	if errors.As(err, &e) {
+		return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+	}
+
+	var uerr *url.Error
+
+	return errors.As(err, &uerr)
 }
@@ -1,7 +1,10 @@
 package arcade_test
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"time"
 
 	. "github.com/homedepot/arcade/pkg"
 	. "github.com/onsi/ginkgo"
@@ -9,6 +12,16 @@ import (
 	"github.com/onsi/gomega/ghttp"
 )
 
+type fakeTransport struct {
+	requests int
+}
+
+func (t *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests++
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
 var _ = Describe("Client", func() {
 	var (
 		server   *ghttp.Server
@@ -75,6 +88,168 @@ var _ = Describe("Client", func() {
 			})
 		})
 
+		When("the response is a redirect", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusNotModified, nil),
+				)
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError("error getting token: 304 Not Modified"))
+			})
+		})
+
+		When("the API key is rejected", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusForbidden, `{"error":"bad api key"}`),
+				)
+			})
+
+			It("returns ErrUnauthorized with the server's message", func() {
+				Expect(errors.Is(err, ErrUnauthorized)).To(BeTrue())
+				Expect(err.Error()).To(Equal("error getting token: 403 Forbidden: bad api key"))
+			})
+		})
+
+		When("the provider is unknown", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusBadRequest, `{"error":"Unsupported token provider: google"}`),
+				)
+			})
+
+			It("returns ErrUnknownProvider", func() {
+				Expect(errors.Is(err, ErrUnknownProvider)).To(BeTrue())
+
+				var e *Error
+				Expect(errors.As(err, &e)).To(BeTrue())
+				Expect(e.StatusCode).To(Equal(http.StatusBadRequest))
+				Expect(e.Message).To(Equal("Unsupported token provider: google"))
+			})
+
+			When("the error has a code", func() {
+				BeforeEach(func() {
+					server.SetHandler(0, ghttp.RespondWith(http.StatusNotFound,
+						`{"error":"Unsupported token provider: google","code":"unknown_provider"}`))
+				})
+
+				It("returns ErrUnknownProvider with the code", func() {
+					Expect(errors.Is(err, ErrUnknownProvider)).To(BeTrue())
+
+					var e *Error
+					Expect(errors.As(err, &e)).To(BeTrue())
+					Expect(e.Code).To(Equal("unknown_provider"))
+				})
+			})
+		})
+
+		When("the provider fails", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusInternalServerError, `{"error":"error getting token from google"}`),
+				)
+			})
+
+			It("returns ErrUpstream", func() {
+				Expect(errors.Is(err, ErrUpstream)).To(BeTrue())
+				Expect(errors.Is(err, ErrUnauthorized)).To(BeFalse())
+			})
+		})
+
+		When("the request is rate limited", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusTooManyRequests, `{"error":"Too many requests"}`),
+				)
+			})
+
+			It("returns ErrRateLimited", func() {
+				Expect(errors.Is(err, ErrRateLimited)).To(BeTrue())
+			})
+		})
+
+		When("retries are configured", func() {
+			BeforeEach(func() {
+				client = NewClient(server.URL(), "test-api-key", WithRetries(2, time.Millisecond))
+			})
+
+			When("a retry succeeds", func() {
+				BeforeEach(func() {
+					server.AppendHandlers(
+						ghttp.RespondWith(http.StatusServiceUnavailable, nil),
+						ghttp.RespondWith(http.StatusTooManyRequests, nil),
+						ghttp.RespondWith(http.StatusOK, `{"token":"some.bearer.token"}`),
+					)
+				})
+
+				It("returns the token", func() {
+					Expect(err).To(BeNil())
+					Expect(token).To(Equal("some.bearer.token"))
+					Expect(server.ReceivedRequests()).To(HaveLen(3))
+				})
+			})
+
+			When("every attempt fails", func() {
+				BeforeEach(func() {
+					server.AppendHandlers(
+						ghttp.RespondWith(http.StatusServiceUnavailable, nil),
+						ghttp.RespondWith(http.StatusServiceUnavailable, nil),
+						ghttp.RespondWith(http.StatusServiceUnavailable, nil),
+					)
+				})
+
+				It("returns the last error", func() {
+					Expect(errors.Is(err, ErrUpstream)).To(BeTrue())
+					Expect(server.ReceivedRequests()).To(HaveLen(3))
+				})
+			})
+
+			When("the error is not retryable", func() {
+				BeforeEach(func() {
+					server.AppendHandlers(
+						ghttp.RespondWith(http.StatusUnauthorized, nil),
+					)
+				})
+
+				It("does not retry", func() {
+					Expect(errors.Is(err, ErrUnauthorized)).To(BeTrue())
+					Expect(server.ReceivedRequests()).To(HaveLen(1))
+				})
+			})
+		})
+
+		When("the request times out", func() {
+			BeforeEach(func() {
+				client = NewClient(server.URL(), "test-api-key", WithTimeout(10*time.Millisecond))
+				server.AppendHandlers(func(w http.ResponseWriter, r *http.Request) {
+					<-r.Context().Done()
+				})
+			})
+
+			It("returns an error", func() {
+				Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+			})
+		})
+
+		When("an http client is configured", func() {
+			var transport *fakeTransport
+
+			BeforeEach(func() {
+				transport = &fakeTransport{}
+				client = NewClient(server.URL(), "test-api-key", WithHTTPClient(&http.Client{Transport: transport}))
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusOK, `{"token":"some.bearer.token"}`),
+				)
+			})
+
+			It("uses it", func() {
+				Expect(err).To(BeNil())
+				Expect(transport.requests).To(Equal(1))
+			})
+		})
+
 		When("the server returns bad data", func() {
 			BeforeEach(func() {
 				server.AppendHandlers(
@@ -119,4 +294,269 @@ var _ = Describe("Client", func() {
 			})
 		})
 	})
+
+	Describe("#TokenContext", func() {
+		var ctx context.Context
+
+		BeforeEach(func() {
+			ctx = context.Background()
+		})
+
+		JustBeforeEach(func() {
+			token, err = client.TokenContext(ctx, provider)
+		})
+
+		When("the context is canceled", func() {
+			BeforeEach(func() {
+				var cancel context.CancelFunc
+
+				ctx, cancel = context.WithCancel(ctx)
+				cancel()
+			})
+
+			It("returns an error", func() {
+				Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+		})
+
+		When("it succeeds", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/tokens", "provider=google"),
+					ghttp.RespondWith(http.StatusOK, `{"token":"some.bearer.token"}`),
+				))
+			})
+
+			It("succeeds", func() {
+				Expect(err).To(BeNil())
+				Expect(token).To(Equal("some.bearer.token"))
+			})
+		})
+	})
+
+	Describe("#TokenWithExpiry", func() {
+		var expiry time.Time
+
+		JustBeforeEach(func() {
+			token, expiry, err = client.TokenWithExpiry(provider)
+		})
+
+		When("the expiry is known", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusOK, `{"token":"some.bearer.token","expiry":"2030-01-02T03:04:05Z"}`),
+				)
+			})
+
+			It("returns it", func() {
+				Expect(err).To(BeNil())
+				Expect(token).To(Equal("some.bearer.token"))
+				Expect(expiry).To(Equal(time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)))
+			})
+		})
+
+		When("the expiry is unknown", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusOK, `{"token":"some.bearer.token"}`),
+				)
+			})
+
+			It("returns the zero time", func() {
+				Expect(err).To(BeNil())
+				Expect(expiry.IsZero()).To(BeTrue())
+			})
+		})
+	})
+
+	Describe("#TokenNoCache", func() {
+		JustBeforeEach(func() {
+			token, err = client.TokenNoCache(provider)
+		})
+
+		When("it succeeds", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(ghttp.CombineHandlers(
+					ghttp.VerifyHeaderKV("api-key", "test-api-key"),
+					ghttp.VerifyHeaderKV("cache-control", "no-cache"),
+					ghttp.VerifyRequest(http.MethodGet, "/tokens", "provider=google"),
+					ghttp.RespondWith(http.StatusOK, `{"token":"some.bearer.token"}`),
+				))
+			})
+
+			It("succeeds", func() {
+				Expect(err).To(BeNil())
+				Expect(token).To(Equal("some.bearer.token"))
+			})
+		})
+	})
+
+	Describe("#RefreshToken", func() {
+		JustBeforeEach(func() {
+			err = client.RefreshToken(provider)
+		})
+
+		When("the server is not reachable", func() {
+			BeforeEach(func() {
+				server.Close()
+			})
+
+			It("returns an error", func() {
+				Expect(err).ToNot(BeNil())
+			})
+		})
+
+		When("the response is not 2XX", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusUnauthorized, nil),
+				)
+			})
+
+			It("returns an error", func() {
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(Equal("error POST /tokens/refresh: 401 Unauthorized"))
+			})
+		})
+
+		When("it succeeds", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(ghttp.CombineHandlers(
+					ghttp.VerifyHeaderKV("api-key", "test-api-key"),
+					ghttp.VerifyRequest(http.MethodPost, "/tokens/refresh", "provider=google"),
+					ghttp.RespondWith(http.StatusNoContent, nil),
+				))
+			})
+
+			It("succeeds", func() {
+				Expect(err).To(BeNil())
+			})
+		})
+	})
+
+	Describe("#InvalidateToken", func() {
+		JustBeforeEach(func() {
+			err = client.InvalidateToken(provider)
+		})
+
+		When("the response is not 2XX", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusBadRequest, nil),
+				)
+			})
+
+			It("returns an error", func() {
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(Equal("error DELETE /tokens/cache: 400 Bad Request"))
+			})
+		})
+
+		When("it succeeds", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(ghttp.CombineHandlers(
+					ghttp.VerifyHeaderKV("api-key", "test-api-key"),
+					ghttp.VerifyRequest(http.MethodDelete, "/tokens/cache", "provider=google"),
+					ghttp.RespondWith(http.StatusNoContent, nil),
+				))
+			})
+
+			It("succeeds", func() {
+				Expect(err).To(BeNil())
+			})
+		})
+	})
+
+	Describe("#ReportInvalidToken", func() {
+		JustBeforeEach(func() {
+			err = client.ReportInvalidToken(provider, "some.bearer.token")
+		})
+
+		When("the response is not 2XX", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusBadRequest, nil),
+				)
+			})
+
+			It("returns an error", func() {
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(Equal("error POST /tokens/report-invalid: 400 Bad Request"))
+			})
+		})
+
+		When("it succeeds", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(ghttp.CombineHandlers(
+					ghttp.VerifyHeaderKV("api-key", "test-api-key"),
+					ghttp.VerifyRequest(http.MethodPost, "/tokens/report-invalid", "provider=google"),
+					ghttp.VerifyJSON(`{"fingerprint":"`+Fingerprint("some.bearer.token")+`"}`),
+					ghttp.RespondWith(http.StatusNoContent, nil),
+				))
+			})
+
+			It("sends only the token's fingerprint", func() {
+				Expect(err).To(BeNil())
+			})
+		})
+	})
+
+	Describe("#ReportInvalidTokenContext", func() {
+		When("the context is canceled", func() {
+			It("returns an error", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				err = client.ReportInvalidTokenContext(ctx, provider, "some.bearer.token")
+				Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("#Providers", func() {
+		var providers []Provider
+
+		JustBeforeEach(func() {
+			providers, err = client.Providers()
+		})
+
+		When("the response is not 2XX", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusForbidden, `{"error":"bad api key"}`),
+				)
+			})
+
+			It("returns an error", func() {
+				Expect(errors.Is(err, ErrUnauthorized)).To(BeTrue())
+			})
+		})
+
+		When("it succeeds", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(ghttp.CombineHandlers(
+					ghttp.VerifyHeaderKV("api-key", "test-api-key"),
+					ghttp.VerifyRequest(http.MethodGet, "/providers", ""),
+					ghttp.RespondWith(http.StatusOK, `{"providers":[
+						{"name":"google","type":"google","parameters":[],"config":{"name":"google","type":"google"},
+						 "status":{"lastSuccess":"2030-01-02T03:04:05Z"},"cachedTokens":1},
+						{"name":"vault-k8s-dv","type":"vault-k8s","parameters":["cluster"],
+						 "config":{"name":"vault-k8s-dv","type":"vault-k8s","url":"https://vault"},"status":{},"cachedTokens":0}
+					]}`),
+				))
+			})
+
+			It("returns the providers", func() {
+				Expect(err).To(BeNil())
+				Expect(providers).To(HaveLen(2))
+				Expect(providers[0].Name).To(Equal("google"))
+				Expect(*providers[0].Status.LastSuccess).To(Equal(time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)))
+				Expect(providers[0].CachedTokens).To(Equal(1))
+				Expect(providers[1].Parameters).To(Equal([]string{"cluster"}))
+				Expect(providers[1].Config).To(HaveKeyWithValue("url", "https://vault"))
+			})
+		})
+	})
 })
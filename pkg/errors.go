@@ -0,0 +1,78 @@
+package arcade
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrUnauthorized is returned when arcade rejects the API key.
+	ErrUnauthorized = errors.New("arcade: unauthorized")
+	// ErrUnknownProvider is returned when arcade has no provider with the
+	// requested name.
+	ErrUnknownProvider = errors.New("arcade: unknown token provider")
+	// ErrRateLimited is returned when arcade rejects a request because of
+	// a rate limit.
+	ErrRateLimited = errors.New("arcade: rate limited")
+	// ErrUpstream is returned when arcade fails to get a token from its
+	// provider, or fails otherwise.
+	ErrUpstream = errors.New("arcade: upstream error")
+)
+
+// Error is returned when arcade responds with a non-2XX status, or sends an
+// error while watching a token, in which case it has no status. It matches
+// one of ErrUnauthorized, ErrUnknownProvider, ErrRateLimited or ErrUpstream
+// with errors.Is, depending on its code or, for arcade servers predating
+// error codes, its status.
+type Error struct {
+	// Op describes the failed operation, for example "getting token".
+	Op         string
+	StatusCode int
+	Status     string
+	// Message is the error returned by arcade, if any.
+	Message string
+	// Code is the machine-readable code of the error, such as
+	// "unknown_provider", if any.
+	Code string
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.Message == "":
+		return fmt.Sprintf("error %s: %s", e.Op, e.Status)
+	case e.Status == "":
+		return fmt.Sprintf("error %s: %s", e.Op, e.Message)
+	}
+
+	return fmt.Sprintf("error %s: %s: %s", e.Op, e.Status, e.Message)
+}
+
+// Unwrap returns the sentinel error matching the code or status of e, if
+// any.
+func (e *Error) Unwrap() error {
+	switch e.Code {
+	case "unauthorized", "forbidden":
+		return ErrUnauthorized
+	case "unknown_provider":
+		return ErrUnknownProvider
+	case "rate_limited":
+		return ErrRateLimited
+	case "upstream_error":
+		return ErrUpstream
+	}
+
+	switch {
+	case e.StatusCode == http.StatusUnauthorized, e.StatusCode == http.StatusForbidden:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusBadRequest && strings.HasPrefix(e.Message, "Unsupported token provider"):
+		return ErrUnknownProvider
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode >= 500:
+		return ErrUpstream
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package arcade
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultTimeout is how long a request to arcade may take, including
+	// arcade requesting a new token from its provider.
+	DefaultTimeout = time.Minute
+	// DefaultBackoff is how long the client waits before its first retry.
+	DefaultBackoff = 100 * time.Millisecond
+	// maxBackoff caps the wait between retries.
+	maxBackoff = 10 * time.Second
+)
+
+// Option configures a client created by NewClient or NewDefaultClient.
+type Option func(*client)
+
+// WithHTTPClient sets the http.Client requests are made with, for example
+// to configure TLS. It defaults to http.DefaultClient.
+func WithHTTPClient(c *http.Client) Option {
+	return func(cl *client) {
+		cl.c = c
+	}
+}
+
+// WithTimeout sets how long each attempt at a request may take. There is
+// no timeout if zero. It defaults to DefaultTimeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(cl *client) {
+		cl.timeout = timeout
+	}
+}
+
+// WithRetries sets how many times a request is retried after failing to
+// reach arcade or being rejected with 429 Too Many Requests or a 5XX
+// status. The wait before each retry starts at backoff and doubles
+// after every attempt, or is as long as the server's Retry-After header
+// if longer. Requests are not retried by default.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(cl *client) {
+		cl.retries = retries
+		cl.backoff = backoff
+	}
+}
@@ -1,5 +1,22 @@
 package provider
 
+import "context"
+
 type ContextKey string
 
 const ProviderKey ContextKey = "provider"
+
+type noCacheKey struct{}
+
+// WithNoCache returns a new context based on ctx requesting that a new
+// token be fetched from the upstream provider rather than from cache.
+func WithNoCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+// NoCache reports whether ctx requests that the cache be bypassed.
+func NoCache(ctx context.Context) bool {
+	noCache, _ := ctx.Value(noCacheKey{}).(bool)
+
+	return noCache
+}
@@ -0,0 +1,29 @@
+package provider
+
+import (
+	"context"
+	"time"
+)
+
+// Token is a token issued by an upstream provider.
+type Token struct {
+	AccessToken string
+	// Expiry is when the token expires, or the zero time if the provider
+	// does not report an expiration.
+	Expiry time.Time
+}
+
+// Fetcher requests new tokens from an upstream provider. Fetchers do not
+// cache tokens; wrap them in a cache to get a Client.
+type Fetcher interface {
+	Fetch(context.Context) (Token, error)
+}
+
+// FetcherFunc is an adapter allowing an ordinary function to be used as a
+// Fetcher.
+type FetcherFunc func(context.Context) (Token, error)
+
+// Fetch calls f(ctx).
+func (f FetcherFunc) Fetch(ctx context.Context) (Token, error) {
+	return f(ctx)
+}
@@ -0,0 +1,35 @@
+package provider
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrRefreshLimited is returned by Refresh when the upstream provider is
+// already handling the maximum number of concurrent refreshes.
+var ErrRefreshLimited = errors.New("too many concurrent token refreshes")
+
+// RefreshLimiter caps the number of concurrent requests made to an
+// upstream provider.
+type RefreshLimiter interface {
+	// TryAcquire reserves a refresh, reporting whether one was available.
+	TryAcquire() bool
+	// Release returns a refresh reserved by TryAcquire.
+	Release()
+}
+
+type refreshLimiterKey struct{}
+
+// WithRefreshLimiter returns a new context based on ctx whose refreshes
+// are limited by l.
+func WithRefreshLimiter(ctx context.Context, l RefreshLimiter) context.Context {
+	return context.WithValue(ctx, refreshLimiterKey{}, l)
+}
+
+// ContextRefreshLimiter returns the RefreshLimiter associated with ctx, or
+// nil if there is none.
+func ContextRefreshLimiter(ctx context.Context) RefreshLimiter {
+	l, _ := ctx.Value(refreshLimiterKey{}).(RefreshLimiter)
+
+	return l
+}
@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const tracerName = "github.com/homedepot/arcade/pkg/provider"
+
+// Trace is a set of hooks run while a Client retrieves a token, letting
+// callers observe requests made to the upstream provider. Any hook may be
+// nil.
+type Trace struct {
+	// RefreshStart is called when a new token is about to be requested
+	// from the upstream provider.
+	RefreshStart func()
+	// RefreshDone is called when the request for a new token completes.
+	RefreshDone func(RefreshInfo)
+}
+
+// RefreshInfo is passed to Trace.RefreshDone with the result of a request
+// for a new token.
+type RefreshInfo struct {
+	// Expiry is when the new token expires, or the zero time if the
+	// provider does not report an expiration.
+	Expiry time.Time
+	// Err is the error returned by the upstream request, if any.
+	Err error
+	// Duration is how long the upstream request took.
+	Duration time.Duration
+	// Shared reports that the request was made on behalf of several
+	// callers and already reported to another, so that it is counted once.
+	Shared bool
+}
+
+type traceKey struct{}
+
+// WithTrace returns a new context based on ctx whose Trace runs the hooks
+// of trace followed by the hooks of any Trace already present in ctx.
+func WithTrace(ctx context.Context, trace *Trace) context.Context {
+	if old := ContextTrace(ctx); old != nil {
+		trace = &Trace{
+			RefreshStart: compose(trace.RefreshStart, old.RefreshStart),
+			RefreshDone:  compose1(trace.RefreshDone, old.RefreshDone),
+		}
+	}
+
+	return context.WithValue(ctx, traceKey{}, trace)
+}
+
+// WithoutTrace returns a new context based on ctx without its Trace, for
+// requests whose hooks must not run, such as those outliving ctx.
+func WithoutTrace(ctx context.Context) context.Context {
+	return context.WithValue(ctx, traceKey{}, (*Trace)(nil))
+}
+
+// ContextTrace returns the Trace associated with ctx, or nil if there is
+// none.
+func ContextTrace(ctx context.Context) *Trace {
+	trace, _ := ctx.Value(traceKey{}).(*Trace)
+
+	return trace
+}
+
+// Refresh calls fetch to request a new token from the upstream provider,
+// reporting the request to the Trace associated with ctx and recording it
+// as an OpenTelemetry span. The fetch function returns the expiration of
+// the token it retrieved. If ctx carries a RefreshLimiter with no refresh
+// available, fetch is not called and ErrRefreshLimited is returned.
+func Refresh(ctx context.Context, fetch func(context.Context) (time.Time, error)) error {
+	if l := ContextRefreshLimiter(ctx); l != nil {
+		if !l.TryAcquire() {
+			return ErrRefreshLimited
+		}
+
+		defer l.Release()
+	}
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "arcade.refresh")
+	defer span.End()
+
+	trace := ContextTrace(ctx)
+	if trace != nil && trace.RefreshStart != nil {
+		trace.RefreshStart()
+	}
+
+	start := time.Now()
+	expiry, err := fetch(ctx)
+	// The error itself is recorded, redacted, on the enclosing token span.
+	if err != nil {
+		span.SetStatus(codes.Error, "refresh failed")
+	} else if !expiry.IsZero() {
+		span.SetAttributes(attribute.String("arcade.token.expiry", expiry.UTC().Format(time.RFC3339)))
+	}
+
+	if trace != nil && trace.RefreshDone != nil {
+		trace.RefreshDone(RefreshInfo{Expiry: expiry, Err: err, Duration: time.Since(start)})
+	}
+
+	return err
+}
+
+func compose(f, g func()) func() {
+	switch {
+	case f == nil:
+		return g
+	case g == nil:
+		return f
+	}
+
+	return func() {
+		f()
+		g()
+	}
+}
+
+func compose1[T any](f, g func(T)) func(T) {
+	switch {
+	case f == nil:
+		return g
+	case g == nil:
+		return f
+	}
+
+	return func(v T) {
+		f(v)
+		g(v)
+	}
+}
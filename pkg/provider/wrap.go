@@ -0,0 +1,28 @@
+package provider
+
+// Wrapper is implemented by Clients that decorate another Client, such as
+// those adding instrumentation.
+type Wrapper interface {
+	Unwrap() Client
+}
+
+// As finds the first Client in the chain of wrapped clients starting at c
+// that is of type T.
+func As[T any](c Client) (T, bool) {
+	for c != nil {
+		if t, ok := c.(T); ok {
+			return t, true
+		}
+
+		w, ok := c.(Wrapper)
+		if !ok {
+			break
+		}
+
+		c = w.Unwrap()
+	}
+
+	var zero T
+
+	return zero, false
+}
@@ -0,0 +1,29 @@
+package arcade
+
+import "time"
+
+// Provider describes a token provider served by arcade.
+type Provider struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Parameters are the request parameters accepted by the provider in
+	// addition to its name, such as the cluster of a vault-k8s provider,
+	// whose tokens are requested with the cluster appended to its name,
+	// as in vault-k8s-dv-cluster.
+	Parameters []string `json:"parameters"`
+	// Config is the provider's configuration, without credentials, such
+	// as its URL or resource.
+	Config map[string]interface{} `json:"config"`
+	Status ProviderStatus         `json:"status"`
+	// CachedTokens is the number of fresh tokens cached for the provider.
+	CachedTokens int `json:"cachedTokens"`
+}
+
+// ProviderStatus describes the most recent token requests of a provider.
+type ProviderStatus struct {
+	LastSuccess *time.Time `json:"lastSuccess,omitempty"`
+	LastError   string     `json:"lastError,omitempty"`
+	LastErrorAt *time.Time `json:"lastErrorAt,omitempty"`
+	// Expiry is when the provider's most recently fetched token expires.
+	Expiry *time.Time `json:"expiry,omitempty"`
+}
@@ -0,0 +1,129 @@
+package arcade
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// DefaultTokenTTL is how long a TokenSource reuses a token whose expiry is
+// unknown, such as a Vault K8s token.
+const DefaultTokenTTL = time.Minute
+
+// TokenSource is an oauth2.TokenSource returning the tokens of a provider
+// from arcade. Tokens are reused until shortly before they expire.
+type TokenSource struct {
+	client   Client
+	provider string
+	ttl      time.Duration
+	// fetching is held while requesting a token, so that concurrent
+	// callers wait for it rather than each requesting one, and mux while
+	// accessing token.
+	fetching chan struct{}
+	mux      sync.Mutex
+	token    *oauth2.Token
+}
+
+// NewTokenSource returns a TokenSource for the given provider.
+func NewTokenSource(c Client, tokenProvider string) *TokenSource {
+	return &TokenSource{
+		client:   c,
+		provider: tokenProvider,
+		ttl:      DefaultTokenTTL,
+		fetching: make(chan struct{}, 1),
+	}
+}
+
+// WithTTL sets how long tokens whose expiry is unknown are reused.
+func (s *TokenSource) WithTTL(ttl time.Duration) {
+	s.ttl = ttl
+}
+
+// Token returns the current token if it is still valid, otherwise it
+// requests a new one from arcade.
+func (s *TokenSource) Token() (*oauth2.Token, error) {
+	return s.TokenContext(context.Background())
+}
+
+// TokenContext returns the current token if it is still valid,
+// otherwise it requests a new one from arcade, giving up once ctx is done.
+func (s *TokenSource) TokenContext(ctx context.Context) (*oauth2.Token, error) {
+	if t := s.current(); t != nil {
+		return t, nil
+	}
+
+	select {
+	case s.fetching <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-s.fetching }()
+
+	// Another caller may have requested a token while this one waited.
+	if t := s.current(); t != nil {
+		return t, nil
+	}
+
+	accessToken, expiry, err := s.client.TokenWithExpiryContext(ctx, s.provider)
+	if err != nil {
+		return nil, err
+	}
+
+	if expiry.IsZero() {
+		expiry = time.Now().Add(s.ttl)
+	}
+
+	t := &oauth2.Token{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		Expiry:      expiry,
+	}
+
+	s.mux.Lock()
+	s.token = t
+	s.mux.Unlock()
+
+	return t, nil
+}
+
+// current returns the current token if it is still valid, or nil.
+func (s *TokenSource) current() *oauth2.Token {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if !s.token.Valid() {
+		return nil
+	}
+
+	return s.token
+}
+
+// invalidate discards token if it is the current token, so that the next
+// call to Token requests a new one.
+func (s *TokenSource) invalidate(token string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.token != nil && s.token.AccessToken == token {
+		s.token = nil
+	}
+}
+
+// WrapTransport returns a function wrapping an http.RoundTripper with a
+// transport authorizing requests with the tokens of the given provider,
+// as returned by NewTransport. It can be used to authorize a Kubernetes
+// client-go rest.Config, which should have no other credentials set:
+//
+//	config.Wrap(arcade.WrapTransport(client, "rancher"))
+//
+// Every transport wrapped by the returned function shares its tokens.
+func WrapTransport(c Client, tokenProvider string) func(http.RoundTripper) http.RoundTripper {
+	s := NewTokenSource(c, tokenProvider)
+
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return s.Transport(rt)
+	}
+}
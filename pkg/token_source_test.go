@@ -0,0 +1,161 @@
+package arcade_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	. "github.com/homedepot/arcade/pkg"
+	"github.com/homedepot/arcade/pkg/arcadefakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("TokenSource", func() {
+	var (
+		fakeClient *arcadefakes.FakeClient
+		source     *TokenSource
+	)
+
+	BeforeEach(func() {
+		fakeClient = &arcadefakes.FakeClient{}
+		fakeClient.TokenWithExpiryContextReturnsOnCall(0, "token-1", time.Now().Add(time.Hour), nil)
+		fakeClient.TokenWithExpiryContextReturnsOnCall(1, "token-2", time.Now().Add(time.Hour), nil)
+		source = NewTokenSource(fakeClient, "google")
+	})
+
+	Describe("#Token", func() {
+		When("getting a token fails", func() {
+			BeforeEach(func() {
+				fakeClient.TokenWithExpiryContextReturnsOnCall(0, "", time.Time{}, errors.New("error getting token"))
+			})
+
+			It("returns the error", func() {
+				_, err := source.Token()
+				Expect(err).To(MatchError("error getting token"))
+			})
+		})
+
+		When("another caller is requesting a token", func() {
+			var release chan struct{}
+
+			BeforeEach(func() {
+				release = make(chan struct{})
+				fakeClient.TokenWithExpiryContextStub = func(context.Context, string) (string, time.Time, error) {
+					<-release
+
+					return "token-1", time.Now().Add(time.Hour), nil
+				}
+			})
+
+			It("waits for it until the context is done", func() {
+				done := make(chan struct{})
+				go func() {
+					defer close(done)
+					defer GinkgoRecover()
+
+					t, err := source.Token()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(t.AccessToken).To(Equal("token-1"))
+				}()
+
+				Eventually(fakeClient.TokenWithExpiryContextCallCount).Should(Equal(1))
+
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				_, err := source.TokenContext(ctx)
+				Expect(err).To(MatchError(context.Canceled))
+
+				close(release)
+				Eventually(done).Should(BeClosed())
+
+				t, err := source.TokenContext(ctx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(t.AccessToken).To(Equal("token-1"))
+				Expect(fakeClient.TokenWithExpiryContextCallCount()).To(Equal(1))
+			})
+		})
+
+		When("the token is valid", func() {
+			It("reuses it", func() {
+				t, err := source.Token()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(t.AccessToken).To(Equal("token-1"))
+				Expect(t.Type()).To(Equal("Bearer"))
+
+				t, _ = source.Token()
+				Expect(t.AccessToken).To(Equal("token-1"))
+				Expect(fakeClient.TokenWithExpiryContextCallCount()).To(Equal(1))
+				_, p := fakeClient.TokenWithExpiryContextArgsForCall(0)
+				Expect(p).To(Equal("google"))
+			})
+		})
+
+		When("the token is about to expire", func() {
+			BeforeEach(func() {
+				fakeClient.TokenWithExpiryContextReturnsOnCall(0, "token-1", time.Now().Add(time.Second), nil)
+			})
+
+			It("requests a new one", func() {
+				_, _ = source.Token()
+				t, _ := source.Token()
+				Expect(t.AccessToken).To(Equal("token-2"))
+			})
+		})
+
+		When("the expiry of the token is unknown", func() {
+			BeforeEach(func() {
+				fakeClient.TokenWithExpiryContextReturnsOnCall(0, "token-1", time.Time{}, nil)
+			})
+
+			It("reuses it for the default TTL", func() {
+				t, _ := source.Token()
+				Expect(t.Expiry).To(BeTemporally("~", time.Now().Add(DefaultTokenTTL), time.Second))
+			})
+
+			When("a TTL is set", func() {
+				BeforeEach(func() {
+					source.WithTTL(time.Hour)
+				})
+
+				It("reuses it for the TTL", func() {
+					t, _ := source.Token()
+					Expect(t.Expiry).To(BeTemporally("~", time.Now().Add(time.Hour), time.Second))
+				})
+			})
+		})
+	})
+
+	Describe("#WrapTransport", func() {
+		var server *ghttp.Server
+
+		BeforeEach(func() {
+			server = ghttp.NewServer()
+			server.AppendHandlers(
+				ghttp.VerifyHeaderKV("Authorization", "Bearer token-1"),
+				ghttp.VerifyHeaderKV("Authorization", "Bearer token-1"),
+			)
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("authorizes requests with tokens shared by every wrapped transport", func() {
+			wrap := WrapTransport(fakeClient, "rancher")
+
+			for i := 0; i < 2; i++ {
+				c := &http.Client{Transport: wrap(http.DefaultTransport)}
+				res, err := c.Get(server.URL())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res.StatusCode).To(Equal(http.StatusOK))
+				_ = res.Body.Close()
+			}
+
+			Expect(fakeClient.TokenWithExpiryContextCallCount()).To(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,108 @@
+package arcade
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+)
+
+// Fingerprint returns a value identifying a token without revealing it,
+// as recorded by arcade in its audit log.
+func Fingerprint(token string) string {
+	if token == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(token))
+
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// NewTransport returns an http.RoundTripper authorizing requests made
+// through base with a bearer token of the given provider, reusing tokens
+// until shortly before they expire. If a request is rejected with 401
+// Unauthorized, the token is reported as invalid and the request is retried
+// once with a new token. Requests with a body are only retried if they can
+// be replayed, that is if their GetBody is set. If base is nil,
+// http.DefaultTransport is used.
+func NewTransport(c Client, tokenProvider string, base http.RoundTripper) http.RoundTripper {
+	return NewTokenSource(c, tokenProvider).Transport(base)
+}
+
+// Transport returns an http.RoundTripper authorizing requests made through
+// base with the tokens of s, as described by NewTransport.
+func (s *TokenSource) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &transport{
+		source: s,
+		base:   base,
+	}
+}
+
+type transport struct {
+	source *TokenSource
+	base   http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.source.TokenContext(req.Context())
+	if err != nil {
+		closeBody(req)
+
+		return nil, err
+	}
+
+	res, err := t.base.RoundTrip(authorize(req, token.AccessToken))
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return res, nil
+	}
+
+	t.source.invalidate(token.AccessToken)
+
+	if err := t.source.client.ReportInvalidTokenContext(req.Context(), t.source.provider, token.AccessToken); err != nil {
+		return res, nil
+	}
+
+	token, err = t.source.TokenContext(req.Context())
+	if err != nil {
+		return res, nil
+	}
+
+	retry := authorize(req, token.AccessToken)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return res, nil
+		}
+
+		retry.Body = body
+	}
+
+	_ = res.Body.Close()
+
+	return t.base.RoundTrip(retry)
+}
+
+// authorize returns a copy of req with token set in its Authorization
+// header, as a RoundTripper must not modify the request it is given.
+func authorize(req *http.Request, token string) *http.Request {
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	return r
+}
+
+// closeBody closes the body of a request that is not sent, as a
+// RoundTripper must always close it.
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+}
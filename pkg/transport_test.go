@@ -0,0 +1,131 @@
+package arcade_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/homedepot/arcade/pkg"
+	"github.com/homedepot/arcade/pkg/arcadefakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Transport", func() {
+	var (
+		server     *ghttp.Server
+		fakeClient *arcadefakes.FakeClient
+		body       io.Reader
+		ctx        context.Context
+		res        *http.Response
+		err        error
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		fakeClient = &arcadefakes.FakeClient{}
+		fakeClient.TokenWithExpiryContextReturnsOnCall(0, "token-1", time.Time{}, nil)
+		fakeClient.TokenWithExpiryContextReturnsOnCall(1, "token-2", time.Time{}, nil)
+		body = nil
+		ctx = context.WithValue(context.Background(), requestKey{}, "request")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	JustBeforeEach(func() {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL()+"/resource", body)
+		c := &http.Client{Transport: NewTransport(fakeClient, "google", nil)}
+		res, err = c.Do(req)
+	})
+
+	When("getting a token fails", func() {
+		BeforeEach(func() {
+			fakeClient.TokenWithExpiryContextReturnsOnCall(0, "", time.Time{}, errors.New("error getting token"))
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError(ContainSubstring("error getting token")))
+			Expect(server.ReceivedRequests()).To(BeEmpty())
+		})
+	})
+
+	When("the token is accepted", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyHeaderKV("Authorization", "Bearer token-1"),
+				ghttp.RespondWith(http.StatusOK, nil),
+			))
+		})
+
+		It("authorizes the request", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+			Expect(fakeClient.ReportInvalidTokenContextCallCount()).To(BeZero())
+		})
+
+		It("requests the token with the context of the request", func() {
+			tokenCtx, _ := fakeClient.TokenWithExpiryContextArgsForCall(0)
+			Expect(tokenCtx.Value(requestKey{})).To(Equal("request"))
+		})
+	})
+
+	When("the token is rejected", func() {
+		BeforeEach(func() {
+			body = strings.NewReader("some-body")
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyHeaderKV("Authorization", "Bearer token-1"),
+					ghttp.RespondWith(http.StatusUnauthorized, nil),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyHeaderKV("Authorization", "Bearer token-2"),
+					ghttp.VerifyBody([]byte("some-body")),
+					ghttp.RespondWith(http.StatusOK, nil),
+				),
+			)
+		})
+
+		It("reports it and retries with a new token", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+			Expect(fakeClient.ReportInvalidTokenContextCallCount()).To(Equal(1))
+			reportCtx, p, t := fakeClient.ReportInvalidTokenContextArgsForCall(0)
+			Expect(reportCtx.Value(requestKey{})).To(Equal("request"))
+			Expect(p).To(Equal("google"))
+			Expect(t).To(Equal("token-1"))
+		})
+
+		When("the new token is also rejected", func() {
+			BeforeEach(func() {
+				server.SetHandler(1, ghttp.RespondWith(http.StatusUnauthorized, nil))
+			})
+
+			It("retries only once", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res.StatusCode).To(Equal(http.StatusUnauthorized))
+				Expect(server.ReceivedRequests()).To(HaveLen(2))
+			})
+		})
+
+		When("reporting the token fails", func() {
+			BeforeEach(func() {
+				fakeClient.ReportInvalidTokenContextReturns(errors.New("error reporting token"))
+			})
+
+			It("returns the rejected response", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res.StatusCode).To(Equal(http.StatusUnauthorized))
+				Expect(server.ReceivedRequests()).To(HaveLen(1))
+			})
+		})
+	})
+})
+
+// requestKey identifies the context of requests made through the transport.
+type requestKey struct{}
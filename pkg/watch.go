@@ -0,0 +1,227 @@
+package arcade
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// opWatch describes watching a token in errors.
+const opWatch = "watching token"
+
+// WatchToken calls fn with the token of a given provider, and then with
+// every new token, streamed by arcade as Server-Sent Events. When the
+// connection is lost, or arcade is unavailable or rate limits the watch,
+// it reconnects after waiting as arcade asked or with backoff, starting
+// from the backoff of WithRetries. Other errors, and those returned by fn,
+// are returned.
+func (c *client) WatchToken(tokenProvider string, fn func(string, time.Time) error) error {
+	return c.WatchTokenContext(context.Background(), tokenProvider, fn)
+}
+
+// WatchTokenContext is WatchToken, returning once ctx is done.
+func (c *client) WatchTokenContext(ctx context.Context, tokenProvider string, fn func(string, time.Time) error) error {
+	u, err := url.Parse(c.url + "/tokens/watch")
+	if err != nil {
+		return err
+	}
+
+	if tokenProvider != "" {
+		q := url.Values{}
+		q.Add("provider", tokenProvider)
+		u.RawQuery = q.Encode()
+	}
+
+	w := &watch{client: c, url: u.String(), fn: fn}
+	backoff := c.backoff
+
+	for {
+		err := w.connect(ctx)
+
+		var ferr *callbackError
+
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.As(err, &ferr):
+			return ferr.err
+		case !reconnectable(err):
+			return err
+		}
+
+		if w.connected {
+			backoff = c.backoff
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(max(backoff, w.retry, w.retryAfter)):
+		}
+
+		backoff = min(2*backoff, maxBackoff)
+	}
+}
+
+// watch is the state of a watch kept across connections.
+type watch struct {
+	client *client
+	url    string
+	fn     func(string, time.Time) error
+	// lastID is the ID of the last event received, sent when reconnecting
+	// so that arcade only sends the current token if it has changed.
+	lastID string
+	// retry is how long arcade asked to wait before reconnecting.
+	retry time.Duration
+	// connected reports whether the last connection succeeded, and
+	// retryAfter how long arcade asked to wait if it was rate limited.
+	connected  bool
+	retryAfter time.Duration
+}
+
+// callbackError is an error returned by the function called with tokens.
+type callbackError struct {
+	err error
+}
+
+func (e *callbackError) Error() string {
+	return e.err.Error()
+}
+
+// connect watches the token until the connection is lost or fails.
+func (w *watch) connect(ctx context.Context) error {
+	w.connected = false
+	w.retryAfter = 0
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, w.url, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Add("Api-Key", w.client.apiKey)
+	req.Header.Add("Accept", "text/event-stream")
+
+	if w.lastID != "" {
+		req.Header.Add("Last-Event-ID", w.lastID)
+	}
+
+	res, err := w.client.c.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		b, _ := io.ReadAll(res.Body)
+
+		var e *Error
+
+		e, w.retryAfter = responseError(opWatch, res, b)
+
+		return e
+	}
+
+	w.connected = true
+
+	return w.read(res.Body)
+}
+
+// read dispatches the events read from r until it ends.
+func (w *watch) read(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var id, event, data string
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if line == "" {
+			if err := w.dispatch(id, event, data); err != nil {
+				return err
+			}
+
+			id, event, data = "", "", ""
+
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "id":
+			id = value
+		case "event":
+			event = value
+		case "data":
+			if data != "" {
+				data += "\n"
+			}
+
+			data += value
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil {
+				w.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return io.ErrUnexpectedEOF
+}
+
+// dispatch handles an event.
+func (w *watch) dispatch(id, event, data string) error {
+	switch event {
+	case "token":
+		var t struct {
+			Token  string    `json:"token"`
+			Expiry time.Time `json:"expiry"`
+		}
+
+		if err := json.Unmarshal([]byte(data), &t); err != nil {
+			return err
+		}
+
+		if err := w.fn(t.Token, t.Expiry); err != nil {
+			return &callbackError{err: err}
+		}
+
+		w.lastID = id
+	case "error":
+		var e struct {
+			Error string `json:"error"`
+			Code  string `json:"code"`
+		}
+
+		_ = json.Unmarshal([]byte(data), &e)
+
+		return &Error{Op: opWatch, Message: e.Error, Code: e.Code}
+	}
+
+	return nil
+}
+
+// reconnectable reports whether a watch failing with err may succeed if
+// reconnected: if the connection was lost, or arcade was rate limited or
+// failed.
+func reconnectable(err error) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return errors.Is(e, ErrRateLimited) || errors.Is(e, ErrUpstream)
+	}
+
+	return true
+}
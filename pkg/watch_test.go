@@ -0,0 +1,129 @@
+package arcade_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	. "github.com/homedepot/arcade/pkg"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("WatchToken", func() {
+	var (
+		server   *ghttp.Server
+		client   Client
+		ctx      context.Context
+		cancel   context.CancelFunc
+		tokens   []string
+		expiries []time.Time
+		stop     int
+		err      error
+	)
+
+	errStop := errors.New("stop watching")
+
+	// respond responds to a watch with the given events.
+	respond := func(events string) http.HandlerFunc {
+		return ghttp.CombineHandlers(
+			ghttp.VerifyRequest(http.MethodGet, "/tokens/watch", "provider=google"),
+			ghttp.VerifyHeaderKV("Api-Key", "test-api-key"),
+			ghttp.RespondWith(http.StatusOK, "retry: 1\n\n"+events, http.Header{"Content-Type": {"text/event-stream"}}),
+		)
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		client = NewClient(server.URL(), "test-api-key")
+		ctx, cancel = context.WithCancel(context.Background())
+		tokens = nil
+		expiries = nil
+		stop = 2
+	})
+
+	AfterEach(func() {
+		cancel()
+		server.Close()
+	})
+
+	JustBeforeEach(func() {
+		err = client.WatchTokenContext(ctx, "google", func(token string, expiry time.Time) error {
+			tokens = append(tokens, token)
+			expiries = append(expiries, expiry)
+
+			if len(tokens) == stop {
+				return errStop
+			}
+
+			return nil
+		})
+	})
+
+	When("arcade sends tokens", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				respond("id: sha256:1\nevent: token\ndata: {\"token\":\"token-1\",\"expiry\":\"2030-01-02T03:04:05Z\"}\n\n: heartbeat\n\n"),
+				ghttp.CombineHandlers(
+					ghttp.VerifyHeaderKV("Last-Event-ID", "sha256:1"),
+					respond("id: sha256:2\nevent: token\ndata: {\"token\":\"token-2\"}\n\n"),
+				),
+			)
+		})
+
+		It("calls the function with each token, reconnecting with the last event ID", func() {
+			Expect(err).To(Equal(errStop))
+			Expect(tokens).To(Equal([]string{"token-1", "token-2"}))
+			Expect(expiries[0]).To(Equal(time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)))
+			Expect(expiries[1]).To(BeZero())
+			Expect(server.ReceivedRequests()).To(HaveLen(2))
+		})
+	})
+
+	When("arcade sends an upstream error", func() {
+		BeforeEach(func() {
+			stop = 1
+			server.AppendHandlers(
+				respond("event: error\ndata: {\"error\":\"error getting token\",\"code\":\"upstream_error\"}\n\n"),
+				respond("id: sha256:1\nevent: token\ndata: {\"token\":\"token-1\"}\n\n"),
+			)
+		})
+
+		It("reconnects", func() {
+			Expect(err).To(Equal(errStop))
+			Expect(tokens).To(Equal([]string{"token-1"}))
+		})
+	})
+
+	When("the API key is rejected", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusForbidden, `{"error":"bad api key","code":"unauthorized"}`),
+			)
+		})
+
+		It("returns ErrUnauthorized without reconnecting", func() {
+			Expect(errors.Is(err, ErrUnauthorized)).To(BeTrue())
+			Expect(err.Error()).To(Equal("error watching token: 403 Forbidden: bad api key"))
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
+	When("the context is canceled", func() {
+		BeforeEach(func() {
+			stop = 0
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					func(http.ResponseWriter, *http.Request) { cancel() },
+					respond("id: sha256:1\nevent: token\ndata: {\"token\":\"token-1\"}\n\n"),
+				),
+			)
+		})
+
+		It("returns the context's error", func() {
+			Expect(err).To(MatchError(context.Canceled))
+		})
+	})
+})